@@ -10,14 +10,14 @@ package bap
 
 import (
 	"crypto/sha256"
-	"errors"
 	"fmt"
+	"math"
+	"strconv"
 
 	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
 	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
 	"github.com/bsv-blockchain/go-sdk/transaction"
 	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
-	"github.com/bitcoinschema/go-aip"
 )
 
 // Prefix is the bitcom prefix for Bitcoin Attestation Protocol (BAP)
@@ -33,6 +33,7 @@ const (
 	ID     AttestationType = "ID"
 	REVOKE AttestationType = "REVOKE"
 	ALIAS  AttestationType = "ALIAS"
+	DATA   AttestationType = "DATA"
 )
 
 // CreateIdentity creates an identity from a private key, an id key, and a counter
@@ -40,16 +41,42 @@ const (
 // Source: https://github.com/icellan/bap
 func CreateIdentity(xPrivateKey, idKey string, currentCounter uint32) (*transaction.Transaction, error) {
 
-	// Test for id key
-	if len(idKey) == 0 {
-		return nil, fmt.Errorf("missing required field: %s", "idKey")
+	// Create and sign the identity record
+	record, err := CreateIdentityRecord(xPrivateKey, idKey, currentCounter)
+	if err != nil {
+		return nil, err
 	}
 
-	hdKey, err := hd.NewKeyFromString(xPrivateKey)
+	// Return the transaction
+	return returnTx(record)
+}
+
+// AddIdentityOutput adds a signed identity output to an existing transaction,
+// so the ID record can be combined with other outputs (payments, MAP, B, etc.)
+func AddIdentityOutput(t *transaction.Transaction, xPrivateKey, idKey string, currentCounter uint32) error {
+
+	// Create and sign the identity record
+	record, err := CreateIdentityRecord(xPrivateKey, idKey, currentCounter)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	signingHdKey, err := hdKey.DeriveChildFromPath(fmt.Sprintf("%d/%d", 0, currentCounter))
+
+	// Add the output to the given transaction
+	return addOutput(t, record)
+}
+
+// CreateIdentityRecord creates a signed identity record from a private key, an id key, and a counter
+// without wrapping it in a transaction
+func CreateIdentityRecord(xPrivateKey, idKey string, currentCounter uint32) (*Record, error) {
+
+	// Test for id key
+	if len(idKey) == 0 {
+		return nil, &MissingFieldError{Field: "idKey"}
+	} else if len(xPrivateKey) == 0 {
+		return nil, &MissingFieldError{Field: "xPrivateKey"}
+	}
+
+	signingHdKey, err := deriveSigningKey(xPrivateKey, currentCounter)
 	if err != nil {
 		return nil, err
 	}
@@ -70,13 +97,60 @@ func CreateIdentity(xPrivateKey, idKey string, currentCounter uint32) (*transact
 	)
 
 	// Generate a signature from this point
-	var finalOutput [][]byte
-	if finalOutput, _, err = aip.SignOpReturnData(signingKey, aip.BitcoinECDSA, data); err != nil {
+	return newRecord(signingKey, data)
+}
+
+// CreateIdentityRotation creates a transaction rotating an identity from the signing key at the
+// current counter to the signing key at the next counter
+func CreateIdentityRotation(xPrivateKey, idKey string, currentCounter uint32) (*transaction.Transaction, error) {
+
+	// Create and sign the rotation record
+	record, err := CreateIdentityRotationRecord(xPrivateKey, idKey, currentCounter)
+	if err != nil {
 		return nil, err
 	}
 
 	// Return the transaction
-	return returnTx(finalOutput)
+	return returnTx(record)
+}
+
+// CreateIdentityRotationRecord creates an ID record for the address of the next counter (0/currentCounter+1),
+// signed by the current signing key (0/currentCounter) so indexers accept the rotation
+func CreateIdentityRotationRecord(xPrivateKey, idKey string, currentCounter uint32) (*Record, error) {
+
+	// Test for id key
+	if len(idKey) == 0 {
+		return nil, &MissingFieldError{Field: "idKey"}
+	} else if len(xPrivateKey) == 0 {
+		return nil, &MissingFieldError{Field: "xPrivateKey"}
+	} else if currentCounter == math.MaxUint32 {
+		return nil, fmt.Errorf("counter %d cannot be rotated", currentCounter)
+	}
+
+	currentHdKey, err := deriveSigningKey(xPrivateKey, currentCounter)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := currentHdKey.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	var nextHdKey *hd.ExtendedKey
+	if nextHdKey, err = deriveSigningKey(xPrivateKey, currentCounter+1); err != nil {
+		return nil, err
+	}
+
+	// The new address is signed by the current key
+	var data [][]byte
+	data = append(
+		data,
+		[]byte(Prefix),
+		[]byte(ID),
+		[]byte(idKey),
+		[]byte(nextHdKey.Address(&chaincfg.MainNet)),
+		[]byte(pipe),
+	)
+	return newRecord(signingKey, data)
 }
 
 // CreateAttestation creates an attestation transaction from an id key, signing key, and signing address
@@ -85,46 +159,245 @@ func CreateIdentity(xPrivateKey, idKey string, currentCounter uint32) (*transact
 func CreateAttestation(idKey string, attestorSigningKey *ec.PrivateKey, attributeName,
 	attributeValue, identityAttributeSecret string) (*transaction.Transaction, error) {
 
-	// ID key is required
+	// Create and sign the attestation record
+	record, err := CreateAttestationRecord(idKey, attestorSigningKey, attributeName, attributeValue, identityAttributeSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the transaction
+	return returnTx(record)
+}
+
+// CreateAttestationWithExpiry creates an attestation transaction that expires at a block height
+// or time (see Expiry), the record carries sequence 0 followed by the expiry
+func CreateAttestationWithExpiry(idKey string, attestorSigningKey *ec.PrivateKey, attributeName,
+	attributeValue, identityAttributeSecret string, expiry Expiry) (*transaction.Transaction, error) {
+
+	// Create and sign the attestation record
+	record, err := CreateAttestationRecordWithExpiry(idKey, attestorSigningKey, attributeName,
+		attributeValue, identityAttributeSecret, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the transaction
+	return returnTx(record)
+}
+
+// AddAttestationOutput adds a signed attestation output to an existing transaction,
+// so the ATTEST record can be combined with other outputs (payments, MAP, B, etc.)
+func AddAttestationOutput(t *transaction.Transaction, idKey string, attestorSigningKey *ec.PrivateKey,
+	attributeName, attributeValue, identityAttributeSecret string) error {
+
+	// Create and sign the attestation record
+	record, err := CreateAttestationRecord(idKey, attestorSigningKey, attributeName, attributeValue, identityAttributeSecret)
+	if err != nil {
+		return err
+	}
+
+	// Add the output to the given transaction
+	return addOutput(t, record)
+}
+
+// CreateAttestationRecord creates a signed attestation record from an id key, signing key, and attribute
+// without wrapping it in a transaction
+func CreateAttestationRecord(idKey string, attestorSigningKey *ec.PrivateKey, attributeName,
+	attributeValue, identityAttributeSecret string) (*Record, error) {
+	return CreateAttestationRecordWithExpiry(idKey, attestorSigningKey, attributeName, attributeValue, identityAttributeSecret, 0)
+}
+
+// CreateAttestationRecordWithExpiry creates a signed attestation record that expires at a block
+// height or time (no expiry if 0) without wrapping it in a transaction
+func CreateAttestationRecordWithExpiry(idKey string, attestorSigningKey *ec.PrivateKey, attributeName,
+	attributeValue, identityAttributeSecret string, expiry Expiry) (*Record, error) {
+
+	// ID key and signing key are required
 	if len(idKey) == 0 {
-		return nil, errors.New("missing required field: idKey")
+		return nil, &MissingFieldError{Field: "idKey"}
+	} else if attestorSigningKey == nil {
+		return nil, &MissingFieldError{Field: "attestorSigningKey"}
 	}
 
 	// Attribute secret and name
 	if len(attributeName) == 0 {
-		return nil, errors.New("missing required field: attributeName")
+		return nil, &MissingFieldError{Field: "attributeName"}
 	} else if len(identityAttributeSecret) == 0 {
-		return nil, errors.New("missing required field: identityAttributeSecret")
+		return nil, &MissingFieldError{Field: "identityAttributeSecret"}
 	}
 
-	// Attest that an internal wallet address is associated with our identity key
-	idUrn := fmt.Sprintf("urn:bap:id:%s:%s:%s", attributeName, attributeValue, identityAttributeSecret)
-	attestationUrn := fmt.Sprintf("urn:bap:attest:%v:%s", sha256.Sum256([]byte(idUrn)), idKey)
-	attestationHash := sha256.Sum256([]byte(attestationUrn))
-
 	// Create op_return attestation
+	attestationHash := AttestationHash(idKey, attributeName, attributeValue, identityAttributeSecret)
 	var data [][]byte
 	data = append(
 		data,
 		[]byte(Prefix),
 		[]byte(ATTEST),
 		attestationHash[0:],
+	)
+
+	// The expiry follows the sequence
+	if expiry > 0 {
+		data = append(data, []byte("0"), []byte(expiry.String()))
+	}
+	data = append(data, []byte(pipe))
+
+	// Generate a signature from this point
+	return newRecord(attestorSigningKey, data)
+}
+
+// CreateRevocation creates a transaction revoking an attestation of an attribute, the sequence
+// must be equal to or higher than the sequence of the attestation (see ResolveStatus)
+func CreateRevocation(idKey string, attestorSigningKey *ec.PrivateKey, attributeName,
+	attributeValue, identityAttributeSecret string, sequence uint64) (*transaction.Transaction, error) {
+
+	// Create and sign the revocation record
+	record, err := CreateRevocationRecord(idKey, attestorSigningKey, attributeName,
+		attributeValue, identityAttributeSecret, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the transaction
+	return returnTx(record)
+}
+
+// CreateRevocationRecord creates a signed REVOKE record for an attribute without wrapping it in a transaction
+func CreateRevocationRecord(idKey string, attestorSigningKey *ec.PrivateKey, attributeName,
+	attributeValue, identityAttributeSecret string, sequence uint64) (*Record, error) {
+
+	// ID key and signing key are required
+	if len(idKey) == 0 {
+		return nil, &MissingFieldError{Field: "idKey"}
+	} else if attestorSigningKey == nil {
+		return nil, &MissingFieldError{Field: "attestorSigningKey"}
+	}
+
+	// Attribute secret and name
+	if len(attributeName) == 0 {
+		return nil, &MissingFieldError{Field: "attributeName"}
+	} else if len(identityAttributeSecret) == 0 {
+		return nil, &MissingFieldError{Field: "identityAttributeSecret"}
+	}
+
+	// Revoke the attestation hash
+	return CreateURNRevocationRecord(AttestationHash(idKey, attributeName, attributeValue, identityAttributeSecret),
+		attestorSigningKey, sequence)
+}
+
+// CreateURNRevocation creates a transaction revoking a URN hash (see CreateRevocation to
+// revoke an attestation of an attribute)
+func CreateURNRevocation(urnHash [32]byte, signingKey *ec.PrivateKey, sequence uint64) (*transaction.Transaction, error) {
+
+	// Create and sign the revocation record
+	record, err := CreateURNRevocationRecord(urnHash, signingKey, sequence)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return the transaction
+	return returnTx(record)
+}
+
+// CreateURNRevocationRecord creates a signed REVOKE record of a URN hash without wrapping it in a transaction
+func CreateURNRevocationRecord(urnHash [32]byte, signingKey *ec.PrivateKey, sequence uint64) (*Record, error) {
+
+	// Signing key is required
+	if signingKey == nil {
+		return nil, &MissingFieldError{Field: "signingKey"}
+	}
+
+	// Create op_return revocation
+	var data [][]byte
+	data = append(
+		data,
+		[]byte(Prefix),
+		[]byte(REVOKE),
+		urnHash[0:],
+		[]byte(strconv.FormatUint(sequence, 10)),
 		[]byte(pipe),
 	)
 
 	// Generate a signature from this point
-	finalOutput, _, err := aip.SignOpReturnData(attestorSigningKey, aip.BitcoinECDSA, data)
+	return newRecord(signingKey, data)
+}
+
+// CreateAlias creates a transaction publishing the profile (JSON) of an identity
+func CreateAlias(idKey string, signingKey *ec.PrivateKey, profile string) (*transaction.Transaction, error) {
+
+	// Create and sign the alias record
+	record, err := CreateAliasRecord(idKey, signingKey, profile)
 	if err != nil {
 		return nil, err
 	}
 
 	// Return the transaction
-	return returnTx(finalOutput)
+	return returnTx(record)
+}
+
+// CreateAliasRecord creates a signed ALIAS record without wrapping it in a transaction,
+// it must be signed by the current signing key of the identity and the profile must
+// match the profile schema (see ValidateProfile)
+func CreateAliasRecord(idKey string, signingKey *ec.PrivateKey, profile string) (*Record, error) {
+
+	// ID key, signing key and profile are required
+	if len(idKey) == 0 {
+		return nil, &MissingFieldError{Field: "idKey"}
+	} else if signingKey == nil {
+		return nil, &MissingFieldError{Field: "signingKey"}
+	} else if len(profile) == 0 {
+		return nil, &MissingFieldError{Field: "profile"}
+	} else if err := ValidateProfile(profile); err != nil {
+		return nil, err
+	}
+
+	// Create op_return alias
+	var data [][]byte
+	data = append(
+		data,
+		[]byte(Prefix),
+		[]byte(ALIAS),
+		[]byte(idKey),
+		[]byte(profile),
+		[]byte(pipe),
+	)
+
+	// Generate a signature from this point
+	return newRecord(signingKey, data)
+}
+
+// AttestationHash returns the attestation hash for an attribute of an identity
+//
+// Source: https://github.com/icellan/bap
+func AttestationHash(idKey, attributeName, attributeValue, identityAttributeSecret string) [32]byte {
+
+	// Attest that an internal wallet address is associated with our identity key
+	idUrn := fmt.Sprintf("urn:bap:id:%s:%s:%s", attributeName, attributeValue, identityAttributeSecret)
+	attestationUrn := fmt.Sprintf("urn:bap:attest:%v:%s", sha256.Sum256([]byte(idUrn)), idKey)
+	return sha256.Sum256([]byte(attestationUrn))
+}
+
+// deriveSigningKey will derive the signing key of an identity at a counter (0/counter)
+func deriveSigningKey(xPrivateKey string, counter uint32) (*hd.ExtendedKey, error) {
+	hdKey, err := hd.NewKeyFromString(xPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return hdKey.DeriveChildFromPath(fmt.Sprintf("%d/%d", 0, counter))
 }
 
 // returnTx will add the output and return a new tx
-func returnTx(outBytes [][]byte) (t *transaction.Transaction, err error) {
+func returnTx(record *Record) (t *transaction.Transaction, err error) {
 	t = transaction.NewTransaction()
-	err = t.AddOpReturnPartsOutput(outBytes)
+	err = addOutput(t, record)
 	return
 }
+
+// addOutput will add the output to an existing tx
+func addOutput(t *transaction.Transaction, record *Record) error {
+	if t == nil {
+		return &MissingFieldError{Field: "transaction"}
+	}
+	t.AddOutput(record.Output())
+	return nil
+}
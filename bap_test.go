@@ -1,11 +1,15 @@
 package bap
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"testing"
 
 	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
 )
 
 // Examples
@@ -263,3 +267,292 @@ func BenchmarkCreateAttestation(b *testing.B) {
 		)
 	}
 }
+
+// TestAddIdentityOutput will test the method AddIdentityOutput()
+func TestAddIdentityOutput(t *testing.T) {
+	t.Parallel()
+
+	// Existing transaction with another protocol output
+	tx := transaction.NewTransaction()
+	if err := tx.AddOpReturnOutput([]byte("existing-output")); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	if err := AddIdentityOutput(tx, privateKey, idKey, 0); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(tx.Outputs) != 2 {
+		t.Fatalf("expected 2 outputs but got %d", len(tx.Outputs))
+	}
+
+	// Same output as a standalone identity
+	single, err := CreateIdentity(privateKey, idKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if tx.Outputs[1].LockingScript.String() != single.Outputs[0].LockingScript.String() {
+		t.Fatalf("identity output does not match CreateIdentity()")
+	}
+
+	// Missing transaction
+	if err = AddIdentityOutput(nil, privateKey, idKey, 0); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+
+	// Invalid identity
+	if err = AddIdentityOutput(tx, privateKey, "", 0); err == nil {
+		t.Fatalf("error should have occurred")
+	} else if len(tx.Outputs) != 2 {
+		t.Fatalf("expected 2 outputs but got %d", len(tx.Outputs))
+	}
+}
+
+// TestAddAttestationOutput will test the method AddAttestationOutput()
+func TestAddAttestationOutput(t *testing.T) {
+	t.Parallel()
+
+	privBuf, _ := hex.DecodeString("127d0ab318252b4622d8eac61407359a4cab7c1a5d67754b5bf9db910eaf052c")
+	priv, _ := ec.PrivateKeyFromBytes(privBuf)
+
+	// Existing transaction with an identity output
+	tx, err := CreateIdentity(privateKey, idKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	if err = AddAttestationOutput(tx, idKey, priv, "person", "john", "some-secret-hash"); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(tx.Outputs) != 2 {
+		t.Fatalf("expected 2 outputs but got %d", len(tx.Outputs))
+	}
+
+	// Same output as a standalone attestation
+	var single *transaction.Transaction
+	if single, err = CreateAttestation(idKey, priv, "person", "john", "some-secret-hash"); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if tx.Outputs[1].LockingScript.String() != single.Outputs[0].LockingScript.String() {
+		t.Fatalf("attestation output does not match CreateAttestation()")
+	}
+
+	// Missing transaction
+	if err = AddAttestationOutput(nil, idKey, priv, "person", "john", "some-secret-hash"); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+
+	// Invalid attestation
+	if err = AddAttestationOutput(tx, idKey, priv, "", "john", "some-secret-hash"); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+}
+
+// ExampleAddIdentityOutput example using AddIdentityOutput()
+func ExampleAddIdentityOutput() {
+	tx := transaction.NewTransaction()
+	if err := AddIdentityOutput(tx, privateKey, idKey, 0); err != nil {
+		fmt.Printf("failed to add identity: %s", err.Error())
+		return
+	}
+
+	fmt.Printf("outputs: %d", len(tx.Outputs))
+	// Output:outputs: 1
+}
+
+// TestCreateIdentityRotation will test the method CreateIdentityRotation()
+func TestCreateIdentityRotation(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			inputPrivateKey string
+			inputIDKey      string
+			inputCounter    uint32
+			expectedError   bool
+		}{
+			{privateKey, idKey, 0, false},
+			{privateKey, idKey, 41, false},
+			{"", idKey, 0, true},
+			{"invalid-key", idKey, 0, true},
+			{privateKey, "", 0, true},
+			{privateKey, idKey, math.MaxUint32, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		tx, err := CreateIdentityRotation(test.inputPrivateKey, test.inputIDKey, test.inputCounter)
+		if err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] [%s] [%d] inputted and error not expected but got: %s", t.Name(), test.inputPrivateKey, test.inputIDKey, test.inputCounter, err.Error())
+			continue
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] [%s] [%d] inputted and error was expected", t.Name(), test.inputPrivateKey, test.inputIDKey, test.inputCounter)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		// Signed by the current key, for the address of the next key
+		current, _ := deriveSigningKey(test.inputPrivateKey, test.inputCounter)
+		next, _ := deriveSigningKey(test.inputPrivateKey, test.inputCounter+1)
+		var records []*SignedBap
+		if records, err = signedRecordsFromHex(tx.Hex()); err != nil {
+			t.Errorf("%s Failed: [%d] inputted and error not expected but got: %s", t.Name(), test.inputCounter, err.Error())
+		} else if records[0].Type != ID || records[0].IDKey != test.inputIDKey || !records[0].Signer.Valid ||
+			records[0].Signer.Address != current.Address(&chaincfg.MainNet) || records[0].Address != next.Address(&chaincfg.MainNet) {
+			t.Errorf("%s Failed: [%d] inputted and unexpected record %+v signed by %+v", t.Name(), test.inputCounter, records[0].Bap, records[0].Signer)
+		}
+	}
+}
+
+// TestCreateRevocation will test the method CreateRevocation()
+func TestCreateRevocation(t *testing.T) {
+	t.Parallel()
+
+	privBuf, _ := hex.DecodeString("127d0ab318252b4622d8eac61407359a4cab7c1a5d67754b5bf9db910eaf052c")
+	priv, _ := ec.PrivateKeyFromBytes(privBuf)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			idKey         string
+			key           *ec.PrivateKey
+			attributeName string
+			secret        string
+			sequence      uint64
+			expectedError bool
+		}{
+			{"valid", idKey, priv, "person", "some-secret-hash", 1, false},
+			{"high sequence", idKey, priv, "person", "some-secret-hash", 12, false},
+			{"missing id key", "", priv, "person", "some-secret-hash", 1, true},
+			{"missing key", idKey, nil, "person", "some-secret-hash", 1, true},
+			{"missing attribute", idKey, priv, "", "some-secret-hash", 1, true},
+			{"missing secret", idKey, priv, "person", "", 1, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		tx, err := CreateRevocation(test.idKey, test.key, test.attributeName, "john", test.secret, test.sequence)
+		if err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		hash := AttestationHash(test.idKey, test.attributeName, "john", test.secret)
+		var records []*SignedBap
+		if records, err = signedRecordsFromHex(tx.Hex()); err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if records[0].Type != REVOKE || records[0].URNHash != hex.EncodeToString(hash[:]) ||
+			records[0].Sequence != test.sequence || !records[0].Signer.Valid {
+			t.Errorf("%s Failed: [%s] inputted and unexpected record %+v", t.Name(), test.name, records[0].Bap)
+		}
+	}
+}
+
+// TestCreateURNRevocation will test the method CreateURNRevocation()
+func TestCreateURNRevocation(t *testing.T) {
+	t.Parallel()
+
+	privBuf, _ := hex.DecodeString("127d0ab318252b4622d8eac61407359a4cab7c1a5d67754b5bf9db910eaf052c")
+	priv, _ := ec.PrivateKeyFromBytes(privBuf)
+	hash := sha256.Sum256([]byte("urn:bap:test"))
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			key           *ec.PrivateKey
+			sequence      uint64
+			expectedError bool
+		}{
+			{"valid", priv, 0, false},
+			{"high sequence", priv, 12, false},
+			{"missing key", nil, 1, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		tx, err := CreateURNRevocation(hash, test.key, test.sequence)
+		if err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		var records []*SignedBap
+		if records, err = signedRecordsFromHex(tx.Hex()); err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if records[0].Type != REVOKE || records[0].URNHash != hex.EncodeToString(hash[:]) ||
+			records[0].Sequence != test.sequence || !records[0].Signer.Valid {
+			t.Errorf("%s Failed: [%s] inputted and unexpected record %+v", t.Name(), test.name, records[0].Bap)
+		}
+	}
+}
+
+// TestCreateAlias will test the method CreateAlias()
+func TestCreateAlias(t *testing.T) {
+	t.Parallel()
+
+	privBuf, _ := hex.DecodeString("127d0ab318252b4622d8eac61407359a4cab7c1a5d67754b5bf9db910eaf052c")
+	priv, _ := ec.PrivateKeyFromBytes(privBuf)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			idKey         string
+			key           *ec.PrivateKey
+			profile       string
+			expectedError bool
+		}{
+			{"valid", idKey, priv, `{"@type":"Person","name":"John Doe"}`, false},
+			{"missing id key", "", priv, `{"name":"John Doe"}`, true},
+			{"missing key", idKey, nil, `{"name":"John Doe"}`, true},
+			{"missing profile", idKey, priv, "", true},
+			{"invalid json", idKey, priv, `{"name":`, true},
+			{"invalid profile", idKey, priv, `{"@type":"Robot","name":"John Doe"}`, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		tx, err := CreateAlias(test.idKey, test.key, test.profile)
+		if err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		var records []*SignedBap
+		if records, err = signedRecordsFromHex(tx.Hex()); err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if records[0].Type != ALIAS || records[0].IDKey != test.idKey || records[0].Profile != test.profile || !records[0].Signer.Valid {
+			t.Errorf("%s Failed: [%s] inputted and unexpected record %+v", t.Name(), test.name, records[0].Bap)
+		}
+	}
+}
+
+// ExampleCreateIdentityRotation example using CreateIdentityRotation()
+func ExampleCreateIdentityRotation() {
+	tx, err := CreateIdentityRotation(privateKey, idKey, 0)
+	if err != nil {
+		fmt.Printf("failed to create rotation: %s", err.Error())
+		return
+	}
+	records, _ := signedRecordsFromHex(tx.Hex())
+	fmt.Printf("%s signed by %s", records[0].Type, records[0].Signer.Address)
+	// Output:ID signed by 1A9VQqdNJrvVF73nf879n2fES6cd5nWNid
+}
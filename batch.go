@@ -0,0 +1,68 @@
+package bap
+
+import (
+	"fmt"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// AttestationRequest is a single attestation to be included in a batch
+type AttestationRequest struct {
+	IDKey                   string `json:"id_key"`
+	AttributeName           string `json:"attribute_name"`
+	AttributeValue          string `json:"attribute_value"`
+	IdentityAttributeSecret string `json:"identity_attribute_secret"`
+	Expiry                  Expiry `json:"expiry,omitempty"` // Optional block height or time the attestation expires
+}
+
+// CreateAttestations creates a single transaction holding one signed attestation
+// output per request, in the order the requests are given
+//
+// Each output is a complete BAP + AIP record, so the records can be read back
+// with NewFromOutputs()
+func CreateAttestations(attestorSigningKey *ec.PrivateKey,
+	requests []AttestationRequest) (*transaction.Transaction, error) {
+
+	t := transaction.NewTransaction()
+	if err := AddAttestationOutputs(t, attestorSigningKey, requests); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// AddAttestationOutputs adds one signed attestation output per request to an
+// existing transaction, in the order the requests are given
+func AddAttestationOutputs(t *transaction.Transaction, attestorSigningKey *ec.PrivateKey,
+	requests []AttestationRequest) error {
+
+	// At least one request is required
+	if len(requests) == 0 {
+		return &MissingFieldError{Field: "requests"}
+	}
+
+	// Sign every record before adding any output, so a failed request leaves the transaction unchanged
+	records := make([]*Record, len(requests))
+	for index, request := range requests {
+		var err error
+		if records[index], err = CreateAttestationRecordWithExpiry(
+			request.IDKey,
+			attestorSigningKey,
+			request.AttributeName,
+			request.AttributeValue,
+			request.IdentityAttributeSecret,
+			request.Expiry,
+		); err != nil {
+			return fmt.Errorf("attestation request %d: %w", index, err)
+		}
+	}
+
+	// Add each record as its own output
+	for _, record := range records {
+		if err := addOutput(t, record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
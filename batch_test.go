@@ -0,0 +1,191 @@
+package bap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bob"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// testAttestationRequests are example batch requests
+var testAttestationRequests = []AttestationRequest{
+	{IDKey: idKey, AttributeName: "person", AttributeValue: "john", IdentityAttributeSecret: "some-secret-hash"},
+	{IDKey: idKey, AttributeName: "email", AttributeValue: "john@example.com", IdentityAttributeSecret: "another-secret"},
+	{IDKey: "other-id-key", AttributeName: "person", AttributeValue: "jane", IdentityAttributeSecret: "jane-secret"},
+}
+
+// testAttestorKey returns the example attestor signing key
+func testAttestorKey() *ec.PrivateKey {
+	privBuf, _ := hex.DecodeString("127d0ab318252b4622d8eac61407359a4cab7c1a5d67754b5bf9db910eaf052c")
+	priv, _ := ec.PrivateKeyFromBytes(privBuf)
+	return priv
+}
+
+// TestCreateAttestations will test the method CreateAttestations()
+func TestCreateAttestations(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			inputRequests   []AttestationRequest
+			expectedOutputs int
+			expectedNil     bool
+			expectedError   bool
+		}{
+			{testAttestationRequests, 3, false, false},
+			{testAttestationRequests[:1], 1, false, false},
+			{nil, 0, true, true},
+			{[]AttestationRequest{testAttestationRequests[0], {IDKey: idKey}}, 0, true, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if tx, err := CreateAttestations(testAttestorKey(), test.inputRequests); err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%v] inputted and error not expected but got: %s", t.Name(), test.inputRequests, err.Error())
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%v] inputted and error was expected", t.Name(), test.inputRequests)
+		} else if tx == nil && !test.expectedNil {
+			t.Errorf("%s Failed: [%v] inputted and nil was not expected", t.Name(), test.inputRequests)
+		} else if tx != nil && test.expectedNil {
+			t.Errorf("%s Failed: [%v] inputted and nil was expected", t.Name(), test.inputRequests)
+		} else if tx != nil && len(tx.Outputs) != test.expectedOutputs {
+			t.Errorf("%s Failed: [%v] inputted and expected [%d] outputs but got [%d]", t.Name(), test.inputRequests, test.expectedOutputs, len(tx.Outputs))
+		}
+	}
+}
+
+// TestCreateAttestationsMatchesSingle will test that each batch output matches CreateAttestation()
+func TestCreateAttestationsMatchesSingle(t *testing.T) {
+	t.Parallel()
+
+	tx, err := CreateAttestations(testAttestorKey(), testAttestationRequests)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	for index, request := range testAttestationRequests {
+		single, err := CreateAttestation(request.IDKey, testAttestorKey(), request.AttributeName,
+			request.AttributeValue, request.IdentityAttributeSecret)
+		if err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+		if tx.Outputs[index].LockingScript.String() != single.Outputs[0].LockingScript.String() {
+			t.Errorf("output %d does not match the single attestation", index)
+		}
+	}
+}
+
+// TestNewFromOutputs will test the method NewFromOutputs()
+func TestNewFromOutputs(t *testing.T) {
+	t.Parallel()
+
+	tx, err := CreateAttestations(testAttestorKey(), testAttestationRequests)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var bobTx *bob.Tx
+	if bobTx, err = bob.NewFromTx(tx); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	var baps []*Bap
+	if baps, err = NewFromOutputs(bobTx.Out); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(baps) != len(testAttestationRequests) {
+		t.Fatalf("expected %d records but got %d", len(testAttestationRequests), len(baps))
+	}
+	for index, request := range testAttestationRequests {
+		hash := AttestationHash(request.IDKey, request.AttributeName, request.AttributeValue, request.IdentityAttributeSecret)
+		if baps[index].Type != ATTEST {
+			t.Errorf("expected: %s got: %s", ATTEST, baps[index].Type)
+		} else if baps[index].URNHash != hex.EncodeToString(hash[:]) {
+			t.Errorf("record %d is out of order", index)
+		}
+	}
+
+	// No BAP outputs
+	if _, err = NewFromOutputs(bobTx.Out[:0]); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+}
+
+// TestNewAllFromTapes will test the method NewAllFromTapes()
+func TestNewAllFromTapes(t *testing.T) {
+	t.Parallel()
+
+	bobData, err := bob.NewFromString(sampleValidBobTx)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Two BAP/AIP tape groups in one output
+	tapes := append(bobData.Out[0].Tape, bobData.Out[0].Tape[1:]...)
+	var baps []*Bap
+	if baps, err = NewAllFromTapes(tapes); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(baps) != 2 {
+		t.Fatalf("expected 2 records but got %d", len(baps))
+	}
+
+	// No BAP tapes
+	if _, err = NewAllFromTapes(bobData.Out[1].Tape); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+}
+
+// ExampleCreateAttestations example using CreateAttestations()
+func ExampleCreateAttestations() {
+	privBuf, _ := hex.DecodeString("127d0ab318252b4622d8eac61407359a4cab7c1a5d67754b5bf9db910eaf052c")
+	priv, _ := ec.PrivateKeyFromBytes(privBuf)
+	tx, err := CreateAttestations(priv, []AttestationRequest{
+		{IDKey: idKey, AttributeName: "person", AttributeValue: "john doe", IdentityAttributeSecret: "some-secret-hash"},
+		{IDKey: idKey, AttributeName: "email", AttributeValue: "john@example.com", IdentityAttributeSecret: "another-secret"},
+	})
+	if err != nil {
+		fmt.Printf("failed to create attestations: %s", err.Error())
+		return
+	}
+
+	fmt.Printf("attestations in tx: %d", len(tx.Outputs))
+	// Output:attestations in tx: 2
+}
+
+// BenchmarkCreateAttestations benchmarks the method CreateAttestations()
+func BenchmarkCreateAttestations(b *testing.B) {
+	priv := testAttestorKey()
+	for i := 0; i < b.N; i++ {
+		_, _ = CreateAttestations(priv, testAttestationRequests)
+	}
+}
+
+// TestAddAttestationOutputs will test the method AddAttestationOutputs()
+func TestAddAttestationOutputs(t *testing.T) {
+	t.Parallel()
+
+	tx, err := CreateIdentity(privateKey, idKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if err = AddAttestationOutputs(tx, testAttestorKey(), testAttestationRequests); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(tx.Outputs) != len(testAttestationRequests)+1 {
+		t.Fatalf("expected %d outputs but got %d", len(testAttestationRequests)+1, len(tx.Outputs))
+	}
+
+	// Missing transaction
+	if err = AddAttestationOutputs(nil, testAttestorKey(), testAttestationRequests); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+
+	// A failed request leaves the transaction unchanged
+	outputs := len(tx.Outputs)
+	requests := []AttestationRequest{testAttestationRequests[0], {AttributeName: "person", AttributeValue: "john"}}
+	if err = AddAttestationOutputs(tx, testAttestorKey(), requests); err == nil {
+		t.Fatalf("error should have occurred")
+	} else if len(tx.Outputs) != outputs {
+		t.Fatalf("expected %d outputs but got %d", outputs, len(tx.Outputs))
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/bitcoinschema/go-bpu"
 )
@@ -13,41 +15,81 @@ type Bap struct {
 	Address  string          `json:"address,omitempty" bson:"address,omitempty"`
 	IDKey    string          `json:"id_key,omitempty" bson:"id_key,omitempty"`
 	Sequence uint64          `json:"sequence" bson:"sequence"`
+	Expiry   Expiry          `json:"expiry,omitempty" bson:"expiry,omitempty"`
 	Type     AttestationType `json:"type,omitempty" bson:"type,omitempty"`
 	URNHash  string          `json:"urn_hash,omitempty" bson:"urn_hash,omitempty"`
 	Profile  string          `json:"profile,omitempty" bson:"profile,omitempty"`
+	Data     string          `json:"data,omitempty" bson:"data,omitempty"`
 }
 
 // FromTape takes a bob.Tape and returns a BAP data structure
+//
+// Cells are read from the string (S) field when it holds valid text, otherwise from
+// the base64 (B) or hex (H) payload. Binary URN hashes are returned as hex.
 func (b *Bap) FromTape(tape *bpu.Tape) (err error) {
-	if len(tape.Cell) < 2 || tape.Cell[1].S == nil {
-		err = fmt.Errorf("invalid %s record %+v", b.Type, tape.Cell)
-		return
+	if tape == nil {
+		return &TapeError{Reason: "tape is nil"}
 	}
 
-	b.Type = AttestationType(*tape.Cell[1].S)
+	// Fields are relative to the BAP prefix (the first cell if it is not found)
+	cells := tape.Cell
+	for index := range cells {
+		if isPrefix(&cells[index]) {
+			cells = cells[index:]
+			break
+		}
+	}
 
-	// Invalid length
-	if len(tape.Cell) < 4 {
-		err = fmt.Errorf("invalid %s record %+v", b.Type, tape.Cell)
-		return
+	var recordType string
+	var ok bool
+	if len(cells) < 2 {
+		return &TapeError{Field: "type", Reason: "missing record type"}
+	} else if recordType, ok = cellString(&cells[1]); !ok {
+		return &TapeError{Field: "type", Reason: "missing record type"}
+	}
+
+	b.Type = AttestationType(recordType)
+
+	// Invalid length (the sequence of an attestation is optional)
+	if len(cells) < 4 && !(len(cells) == 3 && (b.Type == ATTEST || b.Type == REVOKE)) {
+		return &TapeError{Type: b.Type, Reason: fmt.Sprintf("not enough cells (%d)", len(cells))}
 	}
 
 	switch b.Type {
+	case DATA:
+		if b.URNHash, ok = cellHash(&cells[2]); !ok {
+			return &TapeError{Type: b.Type, Field: "urn_hash", Reason: "missing urn hash"}
+		}
+		b.Data, _ = cellString(&cells[3])
 	case REVOKE, ATTEST:
-		if tape.Cell[2].S == nil {
-			return fmt.Errorf("invalid urn hash")
+		if b.URNHash, ok = cellHash(&cells[2]); !ok {
+			return &TapeError{Type: b.Type, Field: "urn_hash", Reason: "missing urn hash"}
+		}
+		if len(cells) > 3 {
+			sequence, _ := cellString(&cells[3])
+			if b.Sequence, err = strconv.ParseUint(sequence, 10, 64); err != nil {
+				return &TapeError{Type: b.Type, Field: "sequence", Err: err}
+			}
 		}
-		b.URNHash = *tape.Cell[2].S
-		if b.Sequence, err = strconv.ParseUint(*tape.Cell[3].S, 10, 64); err != nil {
-			return err
+		if len(cells) > 4 && b.Type == ATTEST {
+			expiry, _ := cellString(&cells[4])
+			if b.Expiry, err = ParseExpiry(expiry); err != nil {
+				return &TapeError{Type: b.Type, Field: "expiry", Err: err}
+			}
 		}
 	case ID:
-		b.Address = *tape.Cell[3].S
-		b.IDKey = *tape.Cell[2].S
+		if b.IDKey, ok = cellString(&cells[2]); !ok {
+			return &TapeError{Type: b.Type, Field: "id_key", Reason: "missing id key"}
+		}
+		if b.Address, ok = cellString(&cells[3]); !ok {
+			return &TapeError{Type: b.Type, Field: "address", Reason: "missing address"}
+		}
 	case ALIAS:
-		b.IDKey = *tape.Cell[2].S
-		b.Profile = *tape.Cell[3].S
+		if b.IDKey, ok = cellString(&cells[2]); !ok {
+			return &TapeError{Type: b.Type, Field: "id_key", Reason: "missing id key"}
+		}
+		profile, _ := cellString(&cells[3])
+		b.Profile = strings.ToValidUTF8(profile, string(utf8.RuneError))
 	}
 	return
 }
@@ -57,21 +99,76 @@ func NewFromTapes(tapes []bpu.Tape) (*Bap, error) {
 	// Loop tapes -> cells (only supporting 1 BAP record right now)
 	for index, t := range tapes {
 		for _, cell := range t.Cell {
-			if cell.S != nil && *cell.S == Prefix {
+			if isPrefix(&cell) {
 				return NewFromTape(&tapes[index])
 			}
 		}
 	}
-	return nil, errors.New("no BAP record found")
+	return nil, ErrNoRecord
+}
+
+// NewAllFromTapes will create a BAP object for every BAP record in a []bob.Tape, in order
+func NewAllFromTapes(tapes []bpu.Tape) (baps []*Bap, err error) {
+	for index, t := range tapes {
+		for _, cell := range t.Cell {
+			if isPrefix(&cell) {
+				var b *Bap
+				if b, err = NewFromTape(&tapes[index]); err != nil {
+					return nil, err
+				}
+				baps = append(baps, b)
+				break
+			}
+		}
+	}
+	if len(baps) == 0 {
+		return nil, ErrNoRecord
+	}
+	return
+}
+
+// NewFromOutputs will create a BAP object for every BAP record in a []bob.Output,
+// in output order (as written by CreateAttestations)
+func NewFromOutputs(outputs []bpu.Output) (baps []*Bap, err error) {
+	for _, output := range outputs {
+		found, tapeErr := NewAllFromTapes(output.Tape)
+		if tapeErr != nil {
+			if errors.Is(tapeErr, ErrNoRecord) {
+				continue
+			}
+			return nil, tapeErr
+		}
+		baps = append(baps, found...)
+	}
+	if len(baps) == 0 {
+		return nil, ErrNoRecord
+	}
+	return
 }
 
 // NewFromTape takes a bob.Tape and returns a BAP data structure
 func NewFromTape(tape *bpu.Tape) (b *Bap, err error) {
 	b = new(Bap)
-	if tape == nil {
-		err = fmt.Errorf("tape is nil %x", tape)
-		return
-	}
 	err = b.FromTape(tape)
 	return
 }
+
+// VerifyTapes validates the AIP signature that follows the BAP record in a []bob.Tape
+//
+// A *SignatureError (matching ErrInvalidSignature) is returned if the signature is invalid
+func VerifyTapes(tapes []bpu.Tape) error {
+	records, err := NewSignedFromTapes(tapes)
+	if err != nil {
+		return &SignatureError{Err: err}
+	}
+	signer := records[0].Signer
+	if signer == nil {
+		return &SignatureError{Err: errors.New("no AIP signature found")}
+	} else if !signer.Valid {
+		if len(signer.Error) > 0 {
+			return &SignatureError{Address: signer.Address, Err: errors.New(signer.Error)}
+		}
+		return &SignatureError{Address: signer.Address}
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package bap
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bitcoinschema/go-bob"
 	"github.com/bitcoinschema/go-bpu"
+	"github.com/bsv-blockchain/go-sdk/transaction"
 )
 
 // TestFromTape will test the method NewFromTape()
@@ -39,9 +43,9 @@ func TestNewFromTape(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error occurred: %s", err.Error())
 	}
-	empty := ""
+	badSequence := "not-a-number"
 	// Bad Sequence
-	bobData.Out[0].Tape[1].Cell[3].S = &empty
+	bobData.Out[0].Tape[1].Cell[3].S = &badSequence
 	_, err = NewFromTape(&bobData.Out[0].Tape[1])
 	if err == nil {
 		t.Fatalf("error should have occurred")
@@ -191,3 +195,67 @@ func BenchmarkNewFromTapes(b *testing.B) {
 		_, _ = NewFromTapes(bobData.Out[0].Tape)
 	}
 }
+
+// TestOnChainFixtures will test parsing real on-chain BAP transactions from testdata/onchain,
+// the fixtures are named by txid
+func TestOnChainFixtures(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			txID            string
+			raw             bool
+			expectedType    AttestationType
+			expectedURNHash string
+			expectedSigner  string
+		}{
+			{"98a5f6ef18eaea188bdfdc048f89a48af82627a15a76fd53584975f28ab3cc39", true, ATTEST, "6386afa223e54d4f955e44a1ef4ae5b18bbb8689dff078627a7cb842fad4f7c6", "134a6TXxzgQ9Az3w8BcvgdZyA5UqRL89da"},
+			{"98a5f6ef18eaea188bdfdc048f89a48af82627a15a76fd53584975f28ab3cc39", false, ATTEST, "6386afa223e54d4f955e44a1ef4ae5b18bbb8689dff078627a7cb842fad4f7c6", "134a6TXxzgQ9Az3w8BcvgdZyA5UqRL89da"},
+			{"26b754e6fdf04121b8d91160a0b252a22ae30204fc552605b7f6d3f08419f29e", false, ATTEST, "16ca90ce3c6347132adba40aa0d5faa3b2bf2015678ffc63db1511b676885e25", "134a6TXxzgQ9Az3w8BcvgdZyA5UqRL89da"},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		var bobTx *bob.Tx
+		if test.raw {
+			rawTx, err := os.ReadFile(filepath.Join("testdata", "onchain", test.txID+".hex"))
+			if err != nil {
+				t.Fatalf("error occurred: %s", err.Error())
+			}
+			var tx *transaction.Transaction
+			if tx, err = transaction.NewTransactionFromHex(strings.TrimSpace(string(rawTx))); err != nil {
+				t.Fatalf("error occurred: %s", err.Error())
+			} else if tx.TxID().String() != test.txID {
+				t.Fatalf("%s Failed: [%s] inputted and got txid %s", t.Name(), test.txID, tx.TxID().String())
+			}
+			if bobTx, err = bob.NewFromTx(tx); err != nil {
+				t.Fatalf("error occurred: %s", err.Error())
+			}
+		} else {
+			data, err := os.ReadFile(filepath.Join("testdata", "onchain", test.txID+".json"))
+			if err != nil {
+				t.Fatalf("error occurred: %s", err.Error())
+			}
+			if bobTx, err = bob.NewFromBytes(data); err != nil {
+				t.Fatalf("error occurred: %s", err.Error())
+			} else if bobTx.Tx.Tx.H != test.txID {
+				t.Fatalf("%s Failed: [%s] inputted and got txid %s", t.Name(), test.txID, bobTx.Tx.Tx.H)
+			}
+		}
+
+		records, err := NewSignedFromOutputs(bobTx.Out)
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.txID, err.Error())
+			continue
+		}
+		if len(records) != 1 || records[0].Type != test.expectedType || records[0].URNHash != test.expectedURNHash {
+			t.Errorf("%s Failed: [%s] inputted and expected %s %s but got %+v", t.Name(), test.txID, test.expectedType, test.expectedURNHash, records)
+		} else if records[0].Signer == nil || !records[0].Signer.Valid || records[0].Signer.Address != test.expectedSigner {
+			t.Errorf("%s Failed: [%s] inputted and expected a valid signature by %s but got %+v", t.Name(), test.txID, test.expectedSigner, records[0].Signer)
+		} else if err = records[0].Validate(nil); err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.txID, err.Error())
+		}
+	}
+}
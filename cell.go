@@ -0,0 +1,61 @@
+package bap
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"unicode/utf8"
+
+	"github.com/bitcoinschema/go-bpu"
+)
+
+// cellBytes returns the raw bytes of a cell
+//
+// The string (S) field is used for text cells, since it is what BOB consumers read and
+// compare. The base64 (B) or hex (H) payload is used when S is missing or empty, or
+// when the payload is binary (S is then only a lossy rendering of it).
+func cellBytes(cell *bpu.Cell) ([]byte, bool) {
+	var raw []byte
+	if cell.B != nil && len(*cell.B) > 0 {
+		raw, _ = base64.StdEncoding.DecodeString(*cell.B)
+	}
+	if raw == nil && cell.H != nil && len(*cell.H) > 0 {
+		raw, _ = hex.DecodeString(*cell.H)
+	}
+	if cell.S != nil && len(*cell.S) > 0 && (raw == nil || utf8.Valid(raw)) {
+		return []byte(*cell.S), true
+	} else if raw != nil {
+		return raw, true
+	} else if cell.S != nil {
+		return []byte{}, true
+	}
+	return nil, false
+}
+
+// cellString returns the text of a cell (see cellBytes)
+func cellString(cell *bpu.Cell) (string, bool) {
+	data, ok := cellBytes(cell)
+	return string(data), ok
+}
+
+// cellHash returns a hash from a cell, hex encoding it if it was pushed as raw bytes
+func cellHash(cell *bpu.Cell) (string, bool) {
+	data, ok := cellBytes(cell)
+	if !ok || len(data) == 0 {
+		return "", false
+	} else if len(data) == sha256.Size {
+		return hex.EncodeToString(data), true
+	}
+	return string(data), true
+}
+
+// isPrefix returns true if the cell holds the BAP prefix
+func isPrefix(cell *bpu.Cell) bool {
+	return cellHasPrefix(cell, Prefix)
+}
+
+// cellHasPrefix returns true if the cell holds the given protocol prefix
+func cellHasPrefix(cell *bpu.Cell, prefix string) bool {
+	data, ok := cellBytes(cell)
+	return ok && string(data) == prefix
+}
@@ -0,0 +1,160 @@
+package bap
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bitcoinschema/go-bob"
+	"github.com/bitcoinschema/go-bpu"
+)
+
+// loadBobFixture will load a BOB tx from testdata/bob
+func loadBobFixture(t testing.TB, name string) *bob.Tx {
+	data, err := os.ReadFile(filepath.Join("testdata", "bob", name+".json"))
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var bobTx *bob.Tx
+	if bobTx, err = bob.NewFromBytes(data); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return bobTx
+}
+
+// binaryCell returns a cell holding only a base64 and hex payload
+func binaryCell(data []byte) bpu.Cell {
+	b := base64.StdEncoding.EncodeToString(data)
+	h := hex.EncodeToString(data)
+	return bpu.Cell{B: &b, H: &h}
+}
+
+// TestFromTapeFixtures will test parsing the BOB fixtures
+func TestFromTapeFixtures(t *testing.T) {
+	t.Parallel()
+
+	hash := AttestationHash(idKey, "person", "john", "some-secret-hash")
+	var (
+		// Testing private methods
+		tests = []struct {
+			fixture         string
+			expectedType    AttestationType
+			expectedIDKey   string
+			expectedAddress string
+			expectedURNHash string
+			expectedCount   int
+		}{
+			{"id", ID, idKey, testAddress, "", 1},
+			{"attest_binary_hash", ATTEST, "", "", hex.EncodeToString(hash[:]), 1},
+			{"attest_no_string_cells", ATTEST, "", "", hex.EncodeToString(hash[:]), 1},
+			{"attest_hex_hash", ATTEST, "", "", testURNHash, 1},
+			{"attest_batch", ATTEST, "", "", hex.EncodeToString(hash[:]), 3},
+			{"revoke", REVOKE, "", "", testURNHash, 1},
+			{"alias_non_utf8", ALIAS, idKey, "", "", 1},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		baps, err := NewFromOutputs(loadBobFixture(t, test.fixture).Out)
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.fixture, err.Error())
+		} else if len(baps) != test.expectedCount {
+			t.Errorf("%s Failed: [%s] inputted and expected [%d] records but got [%d]", t.Name(), test.fixture, test.expectedCount, len(baps))
+		} else if baps[0].Type != test.expectedType {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.fixture, test.expectedType, baps[0].Type)
+		} else if baps[0].IDKey != test.expectedIDKey {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.fixture, test.expectedIDKey, baps[0].IDKey)
+		} else if baps[0].Address != test.expectedAddress {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.fixture, test.expectedAddress, baps[0].Address)
+		} else if baps[0].URNHash != test.expectedURNHash {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.fixture, test.expectedURNHash, baps[0].URNHash)
+		} else if !utf8.ValidString(baps[0].Profile) {
+			t.Errorf("%s Failed: [%s] inputted and profile is not valid UTF-8", t.Name(), test.fixture)
+		}
+	}
+}
+
+// TestFromTapeBinaryCells will test parsing tapes that only have B and H payloads
+func TestFromTapeBinaryCells(t *testing.T) {
+	t.Parallel()
+
+	tape := bpu.Tape{Cell: []bpu.Cell{
+		binaryCell([]byte(Prefix)),
+		binaryCell([]byte(ID)),
+		binaryCell([]byte(idKey)),
+		binaryCell([]byte(testAddress)),
+	}}
+	b, err := NewFromTapes([]bpu.Tape{tape})
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if b.IDKey != idKey || b.Address != testAddress {
+		t.Fatalf("unexpected record: %+v", b)
+	} else if err = b.Validate(nil); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Missing cells never panic
+	tape.Cell[2] = bpu.Cell{}
+	if _, err = NewFromTape(&tape); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+	if _, err = NewFromTape(&bpu.Tape{Cell: []bpu.Cell{{}, {}, {}, {}}}); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+	var nilBap Bap
+	if err = nilBap.FromTape(nil); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+}
+
+// FuzzNewFromOutputs will fuzz the parser with BOB transactions
+func FuzzNewFromOutputs(f *testing.F) {
+	fixtures, _ := filepath.Glob(filepath.Join("testdata", "bob", "*.json"))
+	onChain, _ := filepath.Glob(filepath.Join("testdata", "onchain", "*.json"))
+	for _, fixture := range append(fixtures, onChain...) {
+		data, err := os.ReadFile(fixture)
+		if err != nil {
+			f.Fatalf("error occurred: %s", err.Error())
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(_ *testing.T, data []byte) {
+		bobTx, err := bob.NewFromBytes(data)
+		if err != nil {
+			return
+		}
+		baps, _ := NewFromOutputs(bobTx.Out)
+		for _, b := range baps {
+			_ = b.Validate(nil)
+		}
+	})
+}
+
+// FuzzFromTape will fuzz the parser with arbitrary cell payloads
+func FuzzFromTape(f *testing.F) {
+	hash := AttestationHash(idKey, "person", "john", "some-secret-hash")
+	f.Add([]byte(ID), []byte(idKey), []byte(testAddress), false)
+	f.Add([]byte(ATTEST), hash[:], []byte("0"), true)
+	f.Add([]byte(REVOKE), []byte(testURNHash), []byte("1"), false)
+	f.Add([]byte(ALIAS), []byte(idKey), []byte{'{', 0xff, '}'}, true)
+
+	f.Fuzz(func(_ *testing.T, recordType, field, value []byte, stringCells bool) {
+		cells := []bpu.Cell{binaryCell([]byte(Prefix)), binaryCell(recordType), binaryCell(field), binaryCell(value)}
+		if stringCells {
+			for index, data := range [][]byte{[]byte(Prefix), recordType, field, value} {
+				s := string(data)
+				cells[index].S = &s
+			}
+		}
+		for length := 0; length <= len(cells); length++ {
+			if b, err := NewFromTape(&bpu.Tape{Cell: cells[:length]}); err == nil {
+				_ = b.Validate(nil)
+			}
+		}
+	})
+}
@@ -0,0 +1,223 @@
+// Package client is a typed client for the BAP API endpoints, as served by the hosted
+// BAP API or the local server package
+//
+// Use New for a remote API, or NewInProcess to serve the API from a local indexer Store
+// without any network (for tests)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+	"github.com/bitcoinschema/go-bap/server"
+)
+
+// API is the BAP API, implemented by Client
+type API interface {
+	Attestations(ctx context.Context, urnHash string) ([]*server.Attestation, error)
+	Identity(ctx context.Context, idKey string) (*server.Identity, error)
+	IdentityAttestations(ctx context.Context, idKey string) ([]*server.Attestation, error)
+	IdentityByAddress(ctx context.Context, address string, block uint32) (*server.Identity, error)
+	Profile(ctx context.Context, idKey string) (json.RawMessage, error)
+}
+
+// Doer is the HTTP transport of the client (*http.Client implements it), replace it to mock the API
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// APIError is an error response of the API (a 404 matches indexer.ErrNotFound)
+type APIError struct {
+	Code    int
+	Message string
+}
+
+// Error returns the error message
+func (e *APIError) Error() string {
+	return fmt.Sprintf("bap api error (%d): %s", e.Code, e.Message)
+}
+
+// Unwrap returns indexer.ErrNotFound for not found responses
+func (e *APIError) Unwrap() error {
+	if e.Code == http.StatusNotFound {
+		return indexer.ErrNotFound
+	}
+	return nil
+}
+
+// Client is a BAP API client
+type Client struct {
+	baseURL string
+	doer    Doer
+}
+
+// New returns a client for the API at the base URL (including the version, ie: http://localhost:3000/v1),
+// using http.DefaultClient if doer is nil
+func New(baseURL string, doer Doer) *Client {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), doer: doer}
+}
+
+// inProcessURL is the base URL of the in-process API (never dialed)
+const inProcessURL = "http://in-process" + server.Prefix
+
+// NewInProcess returns a client served in-process by the local API over the store
+func NewInProcess(store indexer.Store) *Client {
+	return New(inProcessURL, &http.Client{Transport: &handlerTransport{handler: server.New(store)}})
+}
+
+// Attestations returns the attestations and revocations of a URN hash
+func (c *Client) Attestations(ctx context.Context, urnHash string) ([]*server.Attestation, error) {
+	if len(urnHash) == 0 {
+		return nil, &bap.MissingFieldError{Field: "urnHash"}
+	}
+	result := new(server.AttestationResult)
+	if err := c.call(ctx, "/attestation/get", &server.Request{Hash: urnHash}, result); err != nil {
+		return nil, err
+	}
+	return result.Attestations, nil
+}
+
+// Identity returns the identity with the given id key
+func (c *Client) Identity(ctx context.Context, idKey string) (*server.Identity, error) {
+	if len(idKey) == 0 {
+		return nil, &bap.MissingFieldError{Field: "idKey"}
+	}
+	identity := new(server.Identity)
+	if err := c.call(ctx, "/identity/get", &server.Request{IDKey: idKey}, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// IdentityAttestations returns the attestations of an identity's claimed attributes
+func (c *Client) IdentityAttestations(ctx context.Context, idKey string) ([]*server.Attestation, error) {
+	if len(idKey) == 0 {
+		return nil, &bap.MissingFieldError{Field: "idKey"}
+	}
+	var attestations []*server.Attestation
+	if err := c.call(ctx, "/identity/getAttestations", &server.Request{IDKey: idKey}, &attestations); err != nil {
+		return nil, err
+	}
+	return attestations, nil
+}
+
+// IdentityByAddress returns the identity of an address, Valid is set if the address was
+// its signing address at the block (0 for the current address)
+func (c *Client) IdentityByAddress(ctx context.Context, address string, block uint32) (*server.Identity, error) {
+	if len(address) == 0 {
+		return nil, &bap.MissingFieldError{Field: "address"}
+	}
+	identity := new(server.Identity)
+	if err := c.call(ctx, "/identity/validByAddress", &server.Request{Address: address, Block: block}, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// Profile returns the current profile (ALIAS) of an identity, or indexer.ErrNotFound
+func (c *Client) Profile(ctx context.Context, idKey string) (json.RawMessage, error) {
+	identity, err := c.Identity(ctx, idKey)
+	if err != nil {
+		return nil, err
+	} else if len(identity.Identity) == 0 {
+		return nil, indexer.ErrNotFound
+	}
+	return identity.Identity, nil
+}
+
+// call will POST the request to the endpoint and decode the result
+func (c *Client) call(ctx context.Context, path string, request *server.Request, result interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body)); err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var res *http.Response
+	if res, err = c.doer.Do(req); err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	response := &server.Response{Result: result}
+	if err = json.NewDecoder(res.Body).Decode(response); err != nil {
+		return &APIError{Code: res.StatusCode, Message: "invalid response: " + err.Error()}
+	} else if response.Status != server.StatusOK {
+		return &APIError{Code: res.StatusCode, Message: response.Message}
+	}
+	return nil
+}
+
+// handlerTransport serves requests with an http.Handler, without a network
+type handlerTransport struct {
+	handler http.Handler
+}
+
+// RoundTrip implements http.RoundTripper
+func (h *handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer func() {
+			_ = req.Body.Close()
+		}()
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	writer := &responseWriter{header: make(http.Header)}
+	h.handler.ServeHTTP(writer, req)
+	if writer.code == 0 {
+		writer.code = http.StatusOK
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", writer.code, http.StatusText(writer.code)),
+		StatusCode:    writer.code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        writer.header,
+		Body:          io.NopCloser(&writer.body),
+		ContentLength: int64(writer.body.Len()),
+		Request:       req,
+	}, nil
+}
+
+// responseWriter buffers the response of a handler
+type responseWriter struct {
+	body   bytes.Buffer
+	code   int
+	header http.Header
+}
+
+// Header implements http.ResponseWriter
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+// Write implements http.ResponseWriter
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(data)
+}
+
+// WriteHeader implements http.ResponseWriter, only the first status code is kept
+func (w *responseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	"github.com/bitcoinschema/go-bap/internal/testutil/testindex"
+	"github.com/bitcoinschema/go-bap/server"
+)
+
+// API is implemented by the client
+var _ API = (*Client)(nil)
+
+// testStore returns a store with an identity, its profile and an attestation
+func testStore(t testing.TB) indexer.Store {
+	idx := testindex.New(t, testutil.IDKey, testutil.AttestorID)
+	attestorKey, _ := testutil.SigningKey(t, testutil.AttestorKey, 0)
+	identityKey, _ := testutil.SigningKey(t, testutil.IdentityKey, 0)
+	testindex.Add(t, idx, 102,
+		testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.IDKey, "name", "John", "0"),
+		testutil.SignedTx(t, identityKey, []byte(bap.ALIAS), []byte(testutil.IDKey), []byte(`{"name":"John"}`)),
+	)
+	if _, err := idx.AddClaim(testutil.IDKey, "name", "John", testindex.Secret); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return idx.Store()
+}
+
+// testDoer is a Doer returning a fixed response
+type testDoer struct {
+	body string
+	code int
+	err  error
+}
+
+// Do implements Doer
+func (d *testDoer) Do(_ *http.Request) (*http.Response, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &http.Response{StatusCode: d.code, Body: io.NopCloser(strings.NewReader(d.body))}, nil
+}
+
+// TestClient will test the client against an in-process and a remote API
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	store := testStore(t)
+	remote := httptest.NewServer(server.New(store))
+	defer remote.Close()
+	_, address := testutil.SigningKey(t, testutil.IdentityKey, 0)
+	hash := bap.AttestationHash(testutil.IDKey, "name", "John", testindex.Secret)
+	urnHash := fmt.Sprintf("%x", hash)
+	ctx := context.Background()
+
+	for _, c := range []*Client{NewInProcess(store), New(remote.URL+server.Prefix+"/", nil)} {
+		identity, err := c.Identity(ctx, testutil.IDKey)
+		if err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		} else if identity.IDKey != testutil.IDKey || identity.CurrentAddress != address || !identity.Valid {
+			t.Fatalf("unexpected identity: %+v", identity)
+		}
+
+		if identity, err = c.IdentityByAddress(ctx, address, 0); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		} else if identity.IDKey != testutil.IDKey || !identity.Valid {
+			t.Fatalf("unexpected identity: %+v", identity)
+		}
+
+		var attestations []*server.Attestation
+		if attestations, err = c.Attestations(ctx, urnHash); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		} else if len(attestations) != 1 || attestations[0].IDKey != testutil.AttestorID || attestations[0].Subject != testutil.IDKey {
+			t.Fatalf("unexpected attestations: %+v", attestations)
+		}
+		if attestations, err = c.IdentityAttestations(ctx, testutil.IDKey); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		} else if len(attestations) != 1 || attestations[0].Hash != urnHash {
+			t.Fatalf("unexpected attestations: %+v", attestations)
+		}
+
+		var profile []byte
+		if profile, err = c.Profile(ctx, testutil.IDKey); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		} else if string(profile) != `{"name":"John"}` {
+			t.Fatalf("unexpected profile: %s", profile)
+		}
+		if _, err = c.Profile(ctx, testutil.AttestorID); !errors.Is(err, indexer.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound but got: %v", err)
+		}
+
+		if _, err = c.Identity(ctx, "unknown"); !errors.Is(err, indexer.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound but got: %v", err)
+		}
+	}
+}
+
+// TestClient_Errors will test the client error handling
+func TestClient_Errors(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			client        *Client
+			ctx           context.Context
+			idKey         string
+			expectedError error
+			expectedCode  int
+		}{
+			{"missing id key", NewInProcess(indexer.NewMemoryStore()), ctx, "", bap.ErrMissingField, 0},
+			{"not found", NewInProcess(indexer.NewMemoryStore()), ctx, testutil.IDKey, indexer.ErrNotFound, http.StatusNotFound},
+			{"canceled", NewInProcess(indexer.NewMemoryStore()), canceled, testutil.IDKey, context.Canceled, 0},
+			{"transport", New("http://localhost", &testDoer{err: io.ErrUnexpectedEOF}), ctx, testutil.IDKey, io.ErrUnexpectedEOF, 0},
+			{"invalid json", New("http://localhost", &testDoer{body: "<html>", code: http.StatusBadGateway}), ctx, testutil.IDKey, nil, http.StatusBadGateway},
+			{"api error", New("http://localhost", &testDoer{body: `{"status":"ERROR","message":"boom"}`, code: http.StatusInternalServerError}), ctx, testutil.IDKey, nil, http.StatusInternalServerError},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		_, err := test.client.Identity(test.ctx, test.idKey)
+		var apiErr *APIError
+		if err == nil {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		} else if test.expectedError != nil && !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		} else if test.expectedCode > 0 && (!errors.As(err, &apiErr) || apiErr.Code != test.expectedCode) {
+			t.Errorf("%s Failed: [%s] inputted and expected code [%d] but got [%v]", t.Name(), test.name, test.expectedCode, err)
+		}
+	}
+}
+
+// ExampleNewInProcess example using NewInProcess()
+func ExampleNewInProcess() {
+	c := NewInProcess(indexer.NewMemoryStore())
+	_, err := c.Identity(context.Background(), "unknown")
+	fmt.Printf("not found: %t", errors.Is(err, indexer.ErrNotFound))
+	// Output:not found: true
+}
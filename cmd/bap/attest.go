@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"strings"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// recordOutput is the output of the commands creating a record
+type recordOutput struct {
+	Address string         `json:"address"` // Signing address
+	RawTx   string         `json:"raw_tx"`
+	Record  *bap.SignedBap `json:"record"`
+	TxID    string         `json:"txid"`
+}
+
+// attributeFlags are the flags of an identity attribute
+type attributeFlags struct {
+	idKey  *string
+	name   *string
+	secret *string
+	value  *string
+}
+
+// runAttest creates an ATTEST record of an identity attribute, signed by the current key of the attestor
+func runAttest(e *env, args []string) error {
+	flags := newFlagSet(e, "attest")
+	source := newKeyFlags(flags, "attestor key file")
+	attribute := newAttributeFlags(flags)
+	expiry := flags.String("expiry", "", "block height or unix time the attestation expires at")
+	if err := parseFlags(flags, args, "id-key", "name", "secret"); err != nil {
+		return err
+	} else if err = source.check(flags); err != nil {
+		return err
+	}
+
+	var parsedExpiry bap.Expiry
+	if len(*expiry) > 0 {
+		var err error
+		if parsedExpiry, err = bap.ParseExpiry(*expiry); err != nil {
+			return err
+		}
+	}
+	k, err := source.read(e)
+	if err != nil {
+		return err
+	}
+	key, address, err := k.currentKey()
+	if err != nil {
+		return err
+	}
+	var tx *transaction.Transaction
+	if tx, err = bap.CreateAttestationWithExpiry(*attribute.idKey, key, *attribute.name, *attribute.value,
+		*attribute.secret, parsedExpiry); err != nil {
+		return err
+	}
+	return writeRecord(e, address, tx)
+}
+
+// runRevoke creates a REVOKE record of an attestation, signed by the current key of the attestor
+func runRevoke(e *env, args []string) error {
+	flags := newFlagSet(e, "revoke")
+	source := newKeyFlags(flags, "attestor key file")
+	attribute := newAttributeFlags(flags)
+	sequence := flags.Uint64("sequence", 1, "sequence, higher than the sequence of the attestation")
+	if err := parseFlags(flags, args, "id-key", "name", "secret"); err != nil {
+		return err
+	} else if err = source.check(flags); err != nil {
+		return err
+	}
+
+	k, err := source.read(e)
+	if err != nil {
+		return err
+	}
+	key, address, err := k.currentKey()
+	if err != nil {
+		return err
+	}
+	var tx *transaction.Transaction
+	if tx, err = bap.CreateRevocation(*attribute.idKey, key, *attribute.name, *attribute.value,
+		*attribute.secret, *sequence); err != nil {
+		return err
+	}
+	return writeRecord(e, address, tx)
+}
+
+// runAlias creates an ALIAS record publishing a profile, signed by the current key of the identity
+func runAlias(e *env, args []string) error {
+	flags := newFlagSet(e, "alias")
+	source := newKeyFlags(flags, "identity key file")
+	profile := flags.String("profile", "", `profile JSON, "-" reads it from stdin (required)`)
+	if err := parseFlags(flags, args, "profile"); err != nil {
+		return err
+	} else if err = source.check(flags); err != nil {
+		return err
+	}
+
+	if *profile == "-" {
+		raw, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return err
+		}
+		*profile = strings.TrimSpace(string(raw))
+	}
+	k, err := source.read(e)
+	if err != nil {
+		return err
+	}
+	key, address, err := k.currentKey()
+	if err != nil {
+		return err
+	}
+	var tx *transaction.Transaction
+	if tx, err = bap.CreateAlias(k.IDKey, key, *profile); err != nil {
+		return err
+	}
+	return writeRecord(e, address, tx)
+}
+
+// newAttributeFlags adds the attribute flags to a flag set
+func newAttributeFlags(flags *flag.FlagSet) *attributeFlags {
+	return &attributeFlags{
+		idKey:  flags.String("id-key", "", "id key of the identity (required)"),
+		name:   flags.String("name", "", "attribute name (required)"),
+		secret: flags.String("secret", "", "identity attribute secret (required)"),
+		value:  flags.String("value", "", "attribute value"),
+	}
+}
+
+// writeRecord will write the record of a created transaction
+func writeRecord(e *env, address string, tx *transaction.Transaction) error {
+	records, err := decodeTx(tx)
+	if err != nil {
+		return err
+	}
+	return writeJSON(e.stdout, &recordOutput{Address: address, RawTx: tx.Hex(), Record: records[0], TxID: tx.TxID().String()})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bob"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// decodeOutput is the output of the decode command
+type decodeOutput struct {
+	Records []*bap.SignedBap `json:"records"` // BAP records in output order, with signature validity
+	TxID    string           `json:"txid"`
+}
+
+// runDecode decodes the BAP records of a raw transaction (hex) or a BOB transaction (JSON),
+// read from the argument or from stdin
+func runDecode(e *env, args []string) error {
+	flags := newFlagSet(e, "decode")
+	flags.Usage = func() {
+		_, _ = io.WriteString(flags.Output(), "usage: bap decode [raw tx hex | BOB json | -]\n")
+	}
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	input := flags.Arg(0)
+	if len(input) == 0 || input == "-" {
+		raw, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return err
+		}
+		input = string(raw)
+	}
+	input = strings.TrimSpace(input)
+
+	var (
+		bobTx *bob.Tx
+		err   error
+	)
+	if strings.HasPrefix(input, "{") {
+		bobTx, err = bob.NewFromString(input)
+	} else {
+		var tx *transaction.Transaction
+		if tx, err = transaction.NewTransactionFromHex(input); err != nil {
+			return err
+		}
+		bobTx, err = bob.NewFromTx(tx)
+	}
+	if err != nil {
+		return err
+	}
+
+	output := &decodeOutput{TxID: bobTx.Tx.Tx.H}
+	if output.Records, err = bap.NewSignedFromOutputs(bobTx.Out); err != nil {
+		return err
+	}
+	return writeJSON(e.stdout, output)
+}
+
+// decodeTx returns the BAP records of a transaction
+func decodeTx(tx *transaction.Transaction) ([]*bap.SignedBap, error) {
+	bobTx, err := bob.NewFromTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	return bap.NewSignedFromOutputs(bobTx.Out)
+}
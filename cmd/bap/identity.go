@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/keystore"
+	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// identityOutput is the output of the identity commands
+type identityOutput struct {
+	Address     string `json:"address"` // Current signing address
+	Counter     uint32 `json:"counter"`
+	IDKey       string `json:"id_key"`
+	KeyFile     string `json:"key_file,omitempty"`
+	Keystore    string `json:"keystore,omitempty"`
+	RawTx       string `json:"raw_tx,omitempty"`
+	RootAddress string `json:"root_address"`
+	TxID        string `json:"txid,omitempty"`
+	XPrivateKey string `json:"xprv,omitempty"`
+	XPublicKey  string `json:"xpub,omitempty"`
+}
+
+// runIdentity runs the identity subcommands
+func runIdentity(e *env, args []string) error {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(e.stderr, "usage: bap identity new|rotate|export [flags]\n")
+		return errUsage
+	}
+	switch args[0] {
+	case "new":
+		return runIdentityNew(e, args[1:])
+	case "rotate":
+		return runIdentityRotate(e, args[1:])
+	case "export":
+		return runIdentityExport(e, args[1:])
+	}
+	_, _ = fmt.Fprintf(e.stderr, "unknown identity command: %s\n", args[0])
+	return errUsage
+}
+
+// runIdentityNew creates a key file or keystore identity, and the ID record of a new identity
+func runIdentityNew(e *env, args []string) error {
+	flags := newFlagSet(e, "identity new")
+	out := flags.String("out", "", "key file to create (required unless -keystore)")
+	keystorePath := flags.String("keystore", "", "keystore file to add the identity to, created if missing (instead of -out)")
+	xPrivateKey := flags.String("xprv", "", "use this xprv instead of generating one")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	} else if (len(*out) == 0) == (len(*keystorePath) == 0) {
+		_, _ = fmt.Fprint(flags.Output(), "exactly one of -out and -keystore is required\n")
+		flags.Usage()
+		return errUsage
+	}
+
+	if len(*xPrivateKey) == 0 {
+		hdKey, err := hd.GenerateHDKey(hd.RecommendedSeedLen)
+		if err != nil {
+			return err
+		}
+		*xPrivateKey = hdKey.String()
+	}
+	k, err := newKeyFile(*xPrivateKey)
+	if err != nil {
+		return err
+	}
+
+	var tx *transaction.Transaction
+	if tx, err = bap.CreateIdentity(k.XPrivateKey, k.IDKey, k.Counter); err != nil {
+		return err
+	}
+	if len(*keystorePath) == 0 {
+		err = k.write(*out, false)
+	} else {
+		var store *keystore.Keystore
+		if store, err = createKeystore(e, *keystorePath); err == nil {
+			_, err = store.Add(&keystore.Identity{IDKey: k.IDKey, RootKey: k.XPrivateKey})
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return writeIdentity(e, k, *out, *keystorePath, tx, false)
+}
+
+// runIdentityRotate creates the ID record rotating to the next signing key and advances the counter
+func runIdentityRotate(e *env, args []string) error {
+	flags := newFlagSet(e, "identity rotate")
+	source := newKeyFlags(flags, "identity key file")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	} else if err = source.check(flags); err != nil {
+		return err
+	}
+
+	k, err := source.read(e)
+	if err != nil {
+		return err
+	}
+	var tx *transaction.Transaction
+	if tx, err = bap.CreateIdentityRotation(k.XPrivateKey, k.IDKey, k.Counter); err != nil {
+		return err
+	}
+	if source.store == nil {
+		k.Counter++
+		err = k.write(*source.path, true)
+	} else {
+		// The keystore counter is advanced under its lock, rotate from the key it was at
+		rotated := k.Counter
+		var previous uint32
+		if previous, k.Counter, err = source.store.AdvanceCounter(k.IDKey); err == nil && previous != rotated {
+			tx, err = bap.CreateIdentityRotation(k.XPrivateKey, k.IDKey, previous)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return writeIdentity(e, k, *source.path, *source.keystore, tx, false)
+}
+
+// runIdentityExport writes an identity, the extended keys only if asked for
+func runIdentityExport(e *env, args []string) error {
+	flags := newFlagSet(e, "identity export")
+	source := newKeyFlags(flags, "identity key file")
+	private := flags.Bool("private", false, "include the xprv and the master xpub")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	} else if err = source.check(flags); err != nil {
+		return err
+	}
+
+	k, err := source.read(e)
+	if err != nil {
+		return err
+	}
+	return writeIdentity(e, k, *source.path, *source.keystore, nil, *private)
+}
+
+// writeIdentity will write the identity output of a key file or keystore identity
+func writeIdentity(e *env, k *keyFile, path, keystorePath string, tx *transaction.Transaction, private bool) error {
+	output := &identityOutput{Counter: k.Counter, IDKey: k.IDKey, KeyFile: path, Keystore: keystorePath, RootAddress: k.RootAddress}
+	var err error
+	if output.Address, err = k.address(k.Counter); err != nil {
+		return err
+	}
+
+	// The master xpub links every signing address of the identity, only export it with the xprv
+	if private {
+		var hdKey *hd.ExtendedKey
+		if hdKey, err = hd.NewKeyFromString(k.XPrivateKey); err != nil {
+			return err
+		} else if output.XPublicKey, err = hd.GetExtendedPublicKey(hdKey); err != nil {
+			return err
+		}
+		output.XPrivateKey = k.XPrivateKey
+	}
+	if tx != nil {
+		output.RawTx, output.TxID = tx.Hex(), tx.TxID().String()
+	}
+	return writeJSON(e.stdout, output)
+}
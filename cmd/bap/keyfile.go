@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/keystore"
+	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+// passwordEnv is the environment variable holding the keystore password
+const passwordEnv = "BAP_KEYSTORE_PASSWORD"
+
+// keyFlags are the flags selecting the key of an identity: a key file or a keystore identity
+type keyFlags struct {
+	identity *string
+	keystore *string
+	path     *string
+	store    *keystore.Keystore // Opened by read for a keystore identity
+}
+
+// keyFile is a local identity key file, the counter is the path (0/counter) of the current signing key
+type keyFile struct {
+	Counter     uint32 `json:"counter"`
+	IDKey       string `json:"id_key"`
+	RootAddress string `json:"root_address"`
+	XPrivateKey string `json:"xprv"`
+}
+
+// newKeyFile returns the key file of an identity rooted at the first signing key (0/0) of an xprv
+func newKeyFile(xPrivateKey string) (*keyFile, error) {
+	k := &keyFile{XPrivateKey: xPrivateKey}
+	var err error
+	if k.RootAddress, err = k.address(0); err != nil {
+		return nil, err
+	} else if k.IDKey, err = bap.IdentityKeyFromXPrivateKey(xPrivateKey); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// newKeyFlags adds the key flags to a flag set
+func newKeyFlags(flags *flag.FlagSet, usage string) *keyFlags {
+	return &keyFlags{
+		identity: flags.String("identity", "", "id key of the keystore identity, if the keystore holds several"),
+		keystore: flags.String("keystore", "", "keystore file, unlocked with $"+passwordEnv+" (instead of -key)"),
+		path:     flags.String("key", "", usage+" (required unless -keystore)"),
+	}
+}
+
+// check will check that a single key source is set, after parsing the flags
+func (f *keyFlags) check(flags *flag.FlagSet) error {
+	if (len(*f.path) == 0) == (len(*f.keystore) == 0) {
+		_, _ = fmt.Fprint(flags.Output(), "exactly one of -key and -keystore is required\n")
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// read will read the key file, or the identity from the unlocked keystore
+func (f *keyFlags) read(e *env) (*keyFile, error) {
+	if len(*f.keystore) == 0 {
+		return readKeyFile(*f.path)
+	}
+
+	var err error
+	if f.store, err = openKeystore(e, *f.keystore); err != nil {
+		return nil, err
+	}
+	var stored *keystore.Identity
+	if len(*f.identity) > 0 {
+		if stored, err = f.store.Identity(*f.identity); err != nil {
+			return nil, err
+		}
+	} else {
+		var identities []*keystore.Identity
+		if identities, err = f.store.Identities(); err != nil {
+			return nil, err
+		} else if len(identities) != 1 {
+			return nil, fmt.Errorf("keystore %s holds %d identities, select one with -identity", *f.keystore, len(identities))
+		}
+		stored = identities[0]
+	}
+
+	var k *keyFile
+	if k, err = newKeyFile(stored.RootKey); err != nil {
+		return nil, err
+	}
+	k.Counter, k.IDKey = stored.Counter, stored.IDKey
+	return k, nil
+}
+
+// openKeystore opens and unlocks a keystore with the password of the environment
+func openKeystore(e *env, path string) (*keystore.Keystore, error) {
+	password, err := keystorePassword(e)
+	if err != nil {
+		return nil, err
+	}
+	var store *keystore.Keystore
+	if store, err = keystore.Open(path); err != nil {
+		return nil, err
+	} else if err = store.Unlock(password); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// createKeystore opens and unlocks a keystore, creating it if it does not exist
+func createKeystore(e *env, path string) (*keystore.Keystore, error) {
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		return openKeystore(e, path)
+	}
+	password, err := keystorePassword(e)
+	if err != nil {
+		return nil, err
+	}
+	return keystore.Create(path, password, nil)
+}
+
+// keystorePassword returns the keystore password of the environment
+func keystorePassword(e *env) (string, error) {
+	password := e.getenv(passwordEnv)
+	if len(password) == 0 {
+		return "", fmt.Errorf("%s is not set", passwordEnv)
+	}
+	return password, nil
+}
+
+// readKeyFile will read and check a key file
+func readKeyFile(path string) (*keyFile, error) {
+	raw, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	k := new(keyFile)
+	if err = json.Unmarshal(raw, k); err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+	} else if len(k.XPrivateKey) == 0 {
+		return nil, fmt.Errorf("invalid key file %s: %w", path, &bap.MissingFieldError{Field: "xprv"})
+	} else if err = bap.ValidateIDKey(k.IDKey); err != nil {
+		return nil, fmt.Errorf("invalid key file %s: %w", path, err)
+	}
+	return k, nil
+}
+
+// write will write the key file, readable by the owner only, replacing it atomically
+func (k *keyFile) write(path string, overwrite bool) error {
+	raw, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	if !overwrite {
+		if _, err = os.Stat(path); err == nil {
+			return fmt.Errorf("key file %s already exists", path)
+		}
+	}
+
+	var tmp *os.File
+	if tmp, err = os.CreateTemp(filepath.Dir(path), ".bap-key-*"); err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err = tmp.Write(append(raw, '\n')); err != nil {
+		_ = tmp.Close()
+		return err
+	} else if err = tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return err
+	} else if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// signingKey returns the signing key at a counter
+func (k *keyFile) signingKey(counter uint32) (*hd.ExtendedKey, error) {
+	hdKey, err := hd.NewKeyFromString(k.XPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return hdKey.DeriveChildFromPath(fmt.Sprintf("0/%d", counter))
+}
+
+// currentKey returns the current signing key and its address
+func (k *keyFile) currentKey() (*ec.PrivateKey, string, error) {
+	hdKey, err := k.signingKey(k.Counter)
+	if err != nil {
+		return nil, "", err
+	}
+	var key *ec.PrivateKey
+	if key, err = hdKey.ECPrivKey(); err != nil {
+		return nil, "", err
+	}
+	return key, hdKey.Address(&chaincfg.MainNet), nil
+}
+
+// address returns the address of the signing key at a counter
+func (k *keyFile) address(counter uint32) (string, error) {
+	hdKey, err := k.signingKey(counter)
+	if err != nil {
+		return "", err
+	}
+	return hdKey.Address(&chaincfg.MainNet), nil
+}
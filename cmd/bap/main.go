@@ -0,0 +1,140 @@
+// Command bap creates, inspects and verifies Bitcoin Attestation Protocol (BAP) records
+//
+// It works fully offline on local key files or a keystore, and writes JSON to stdout for scripting:
+//
+//	bap identity new --out alice.json
+//	bap identity rotate --key alice.json
+//	bap identity export --key alice.json
+//	bap attest --key attestor.json --id-key <id key> --name name --value Alice --secret <secret>
+//	bap revoke --key attestor.json --id-key <id key> --name name --value Alice --secret <secret> --sequence 1
+//	bap alias --key alice.json --profile '{"@type":"Person","name":"Alice"}'
+//	bap sign --key alice.json --message hello
+//	bap verify --address <address> --signature <base64> --message hello
+//	bap decode <raw tx hex | BOB json | -> (stdin by default)
+//
+// Key files hold the identity xprv unencrypted: they are written readable by their owner
+// only (0600), but anyone who can read a key file controls the identity. Keep them on an
+// encrypted disk or store long-lived identities in a password-encrypted keystore instead:
+// every command taking -key also takes -keystore (and -identity if it holds several), and
+// reads the password from $BAP_KEYSTORE_PASSWORD.
+//
+//	bap identity new --keystore identities.keystore
+//	bap identity rotate --keystore identities.keystore --identity <id key>
+//
+// Created transactions only hold the BAP output: fund and broadcast them with a wallet.
+// Errors are written to stderr as {"error": "..."} with a non-zero exit code.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// usage is printed for a missing or unknown command
+const usage = `usage: bap <command> [flags]
+
+commands:
+  identity new|rotate|export   create, rotate or export an identity (key file or keystore)
+  attest                       attest an attribute of an identity
+  revoke                       revoke an attestation
+  alias                        publish the profile of an identity
+  sign                         sign a message with the current identity key
+  verify                       verify a signed message
+  decode                       decode the BAP records of a raw tx or BOB json
+
+run "bap <command> -h" for the flags of a command
+keystores are unlocked with $BAP_KEYSTORE_PASSWORD
+`
+
+// errUsage is returned for invalid arguments, the flag set has already reported them
+var errUsage = errors.New("invalid arguments")
+
+// env is the environment of a command
+type env struct {
+	getenv func(key string) string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command runs a subcommand with its arguments
+type command func(e *env, args []string) error
+
+// commands are the subcommands by name
+var commands = map[string]command{
+	"alias":    runAlias,
+	"attest":   runAttest,
+	"decode":   runDecode,
+	"identity": runIdentity,
+	"revoke":   runRevoke,
+	"sign":     runSign,
+	"verify":   runVerify,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{getenv: os.Getenv, stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+// run will run the command line and return the exit code
+func run(args []string, e *env) int {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(e.stderr, usage)
+		return exitUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(e.stderr, "unknown command: %s\n\n%s", args[0], usage)
+		return exitUsage
+	}
+	if err := cmd(e, args[1:]); errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	} else if errors.Is(err, errUsage) {
+		return exitUsage
+	} else if err != nil {
+		_ = writeJSON(e.stderr, map[string]string{"error": err.Error()})
+		return exitError
+	}
+	return exitOK
+}
+
+// newFlagSet returns a flag set reporting errors to stderr
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	flags := flag.NewFlagSet("bap "+name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	return flags
+}
+
+// parseFlags will parse the arguments, requiring the given flags to be set
+func parseFlags(flags *flag.FlagSet, args []string, required ...string) error {
+	if err := flags.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return err
+	} else if err != nil {
+		return errUsage
+	}
+	for _, name := range required {
+		if f := flags.Lookup(name); f != nil && len(f.Value.String()) == 0 {
+			_, _ = fmt.Fprintf(flags.Output(), "missing required flag: -%s\n", name)
+			flags.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+// writeJSON will write a value as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
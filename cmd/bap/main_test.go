@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+)
+
+// Example keys
+const (
+	testAttestorKey = "xprv9s21ZrQH143K3PZSwbEeXEYq74EbnfMngzAiMCZcfjzyRpUvt2vQJnaHRTZjeuEmLXeN6BzYRoFsEckfobxE9XaRzeLGfQoxzPzTRyRb6oE"
+	testIdentityKey = "xprv9s21ZrQH143K2beTKhLXFRWWFwH8jkwUssjk3SVTiApgmge7kNC3jhVc4NgHW8PhW2y7BCDErqnKpKuyQMjqSePPJooPJowAz5BVLThsv6c"
+	testPassword    = "correct horse battery staple"
+)
+
+// testGetenv is the environment of the tests, holding the keystore password
+func testGetenv(key string) string {
+	if key == passwordEnv {
+		return testPassword
+	}
+	return ""
+}
+
+// testRun will run the command line and decode its JSON output
+func testRun(t testing.TB, stdin string, expectedCode int, output interface{}, args ...string) string {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &env{getenv: testGetenv, stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr})
+	if code != expectedCode {
+		t.Fatalf("%v: expected exit code %d but got %d: %s", args, expectedCode, code, stderr.String())
+	}
+	if output != nil {
+		if err := json.Unmarshal(stdout.Bytes(), output); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+	return stderr.String()
+}
+
+// TestRun_Identity will test the identity commands
+func TestRun_Identity(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "identity.json")
+	var created identityOutput
+	testRun(t, "", exitOK, &created, "identity", "new", "--out", path, "--xprv", testIdentityKey)
+	if created.Counter != 0 || created.Address != created.RootAddress || len(created.RawTx) == 0 || len(created.XPrivateKey) > 0 || len(created.XPublicKey) > 0 {
+		t.Fatalf("%s Failed: unexpected identity %+v", t.Name(), created)
+	}
+
+	// Key files are never overwritten
+	if stderr := testRun(t, "", exitError, nil, "identity", "new", "--out", path); !strings.Contains(stderr, "already exists") {
+		t.Fatalf("%s Failed: unexpected error %s", t.Name(), stderr)
+	}
+
+	var rotated, public, exported identityOutput
+	testRun(t, "", exitOK, &rotated, "identity", "rotate", "--key", path)
+	testRun(t, "", exitOK, &public, "identity", "export", "--key", path)
+	testRun(t, "", exitOK, &exported, "identity", "export", "--key", path, "--private")
+	if rotated.Counter != 1 || rotated.Address == created.Address || rotated.IDKey != created.IDKey {
+		t.Fatalf("%s Failed: unexpected rotation %+v", t.Name(), rotated)
+	} else if public.Address != rotated.Address || len(public.XPrivateKey) > 0 || len(public.XPublicKey) > 0 {
+		t.Fatalf("%s Failed: unexpected public export %+v", t.Name(), public)
+	} else if exported.Counter != 1 || exported.Address != rotated.Address || exported.XPrivateKey != testIdentityKey || len(exported.XPublicKey) == 0 || len(exported.TxID) > 0 {
+		t.Fatalf("%s Failed: unexpected export %+v", t.Name(), exported)
+	}
+
+	// A generated identity
+	var generated identityOutput
+	testRun(t, "", exitOK, &generated, "identity", "new", "--out", filepath.Join(t.TempDir(), "generated.json"))
+	if generated.IDKey == created.IDKey || len(generated.TxID) == 0 {
+		t.Fatalf("%s Failed: unexpected generated identity %+v", t.Name(), generated)
+	}
+}
+
+// TestRun_Keystore will test the commands with identities stored in a keystore
+func TestRun_Keystore(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "identities.keystore")
+	var created, attestor identityOutput
+	testRun(t, "", exitOK, &created, "identity", "new", "--keystore", path, "--xprv", testIdentityKey)
+	if created.Keystore != path || len(created.KeyFile) > 0 || len(created.TxID) == 0 || len(created.XPrivateKey) > 0 {
+		t.Fatalf("%s Failed: unexpected identity %+v", t.Name(), created)
+	}
+
+	// A single identity is selected by default
+	var signed messageOutput
+	testRun(t, "", exitOK, &signed, "sign", "--keystore", path, "--message", "hello")
+	if signed.Address != created.Address || signed.IDKey != created.IDKey {
+		t.Fatalf("%s Failed: unexpected signature %+v", t.Name(), signed)
+	}
+
+	// Identities are stored once, and selected with -identity when the keystore holds several
+	if stderr := testRun(t, "", exitError, nil, "identity", "new", "--keystore", path, "--xprv", testIdentityKey); !strings.Contains(stderr, "already exists") {
+		t.Fatalf("%s Failed: unexpected error %s", t.Name(), stderr)
+	}
+	testRun(t, "", exitOK, &attestor, "identity", "new", "--keystore", path, "--xprv", testAttestorKey)
+	if stderr := testRun(t, "", exitError, nil, "sign", "--keystore", path, "--message", "hello"); !strings.Contains(stderr, "-identity") {
+		t.Fatalf("%s Failed: unexpected error %s", t.Name(), stderr)
+	}
+
+	var rotated, exported identityOutput
+	testRun(t, "", exitOK, &rotated, "identity", "rotate", "--keystore", path, "--identity", created.IDKey)
+	testRun(t, "", exitOK, &exported, "identity", "export", "--keystore", path, "--identity", created.IDKey, "--private")
+	if rotated.Counter != 1 || rotated.Address == created.Address || rotated.IDKey != created.IDKey || len(rotated.TxID) == 0 {
+		t.Fatalf("%s Failed: unexpected rotation %+v", t.Name(), rotated)
+	} else if exported.Counter != 1 || exported.Address != rotated.Address || exported.XPrivateKey != testIdentityKey {
+		t.Fatalf("%s Failed: unexpected export %+v", t.Name(), exported)
+	}
+
+	// The keystore is unlocked with the password of the environment
+	var stdout, stderr bytes.Buffer
+	for _, getenv := range []func(string) string{
+		func(string) string { return "" },
+		func(string) string { return "wrong password" },
+	} {
+		args := []string{"sign", "--keystore", path, "--identity", attestor.IDKey, "--message", "hello"}
+		if code := run(args, &env{getenv: getenv, stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}); code != exitError {
+			t.Fatalf("%s Failed: expected exit code %d but got %d", t.Name(), exitError, code)
+		}
+	}
+}
+
+// TestRun_Records will test that the created records are accepted by an indexer
+func TestRun_Records(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	identityPath, attestorPath := filepath.Join(dir, "identity.json"), filepath.Join(dir, "attestor.json")
+	var identity, attestor, rotated identityOutput
+	testRun(t, "", exitOK, &identity, "identity", "new", "--out", identityPath, "--xprv", testIdentityKey)
+	testRun(t, "", exitOK, &attestor, "identity", "new", "--out", attestorPath, "--xprv", testAttestorKey)
+	testRun(t, "", exitOK, &rotated, "identity", "rotate", "--key", identityPath)
+
+	attribute := []string{"--id-key", identity.IDKey, "--name", "name", "--value", "John", "--secret", "secret"}
+	var alias, attest, revoke recordOutput
+	testRun(t, `{"@type":"Person","name":"John"}`, exitOK, &alias, "alias", "--key", identityPath, "--profile", "-")
+	testRun(t, "", exitOK, &attest, append([]string{"attest", "--key", attestorPath, "--expiry", "900000"}, attribute...)...)
+	testRun(t, "", exitOK, &revoke, append([]string{"revoke", "--key", attestorPath}, attribute...)...)
+	if alias.Address != rotated.Address || attest.Record.Expiry != 900000 || revoke.Record.Sequence != 1 || !revoke.Record.Signer.Valid {
+		t.Fatalf("%s Failed: unexpected records %+v %+v %+v", t.Name(), alias.Record, attest.Record, revoke.Record)
+	}
+
+	idx := indexer.New(indexer.NewMemoryStore(), nil)
+	for index, rawTx := range []string{identity.RawTx, attestor.RawTx, rotated.RawTx, alias.RawTx, attest.RawTx, revoke.RawTx} {
+		if _, err := idx.AddRawTx(rawTx, indexer.Block{Height: uint32(100 + index)}); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+	query := indexer.NewQuery(idx.Store())
+	if current, err := query.Identity(identity.IDKey); err != nil || current.Identity.CurrentAddress() != rotated.Address {
+		t.Fatalf("%s Failed: unexpected identity %+v %v", t.Name(), current, err)
+	} else if status, statusErr := query.AttestationStatus(attest.Record.URNHash, attestor.IDKey, attest.TxID); statusErr != nil || status.Status != bap.StatusRevoked {
+		t.Fatalf("%s Failed: unexpected status %+v %v", t.Name(), status, statusErr)
+	}
+}
+
+// TestRun_Message will test the sign and verify commands
+func TestRun_Message(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "identity.json")
+	testRun(t, "", exitOK, nil, "identity", "new", "--out", path, "--xprv", testIdentityKey)
+
+	var signed, verified, invalid messageOutput
+	testRun(t, "", exitOK, &signed, "sign", "--key", path, "--message", "hello")
+	testRun(t, "", exitOK, &verified, "verify", "--address", signed.Address, "--signature", signed.Signature, "--message", "hello")
+	testRun(t, "", exitError, &invalid, "verify", "--address", signed.Address, "--signature", signed.Signature, "--message", "goodbye")
+	if !verified.Valid || invalid.Valid || len(invalid.Error) == 0 {
+		t.Fatalf("%s Failed: unexpected verification %+v %+v", t.Name(), verified, invalid)
+	}
+}
+
+// TestRun_Decode will test the decode command
+func TestRun_Decode(t *testing.T) {
+	t.Parallel()
+
+	var created identityOutput
+	testRun(t, "", exitOK, &created, "identity", "new", "--out", filepath.Join(t.TempDir(), "identity.json"), "--xprv", testIdentityKey)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name         string
+			stdin        string
+			args         []string
+			expectedCode int
+		}{
+			{"raw tx argument", "", []string{"decode", created.RawTx}, exitOK},
+			{"raw tx from stdin", created.RawTx + "\n", []string{"decode"}, exitOK},
+			{"dash reads stdin", created.RawTx, []string{"decode", "-"}, exitOK},
+			{"not a tx", "", []string{"decode", "zz"}, exitError},
+			{"no records", "", []string{"decode", "01000000000000000000"}, exitError},
+			{"invalid BOB", "", []string{"decode", "{"}, exitError},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		var decoded decodeOutput
+		var output interface{}
+		if test.expectedCode == exitOK {
+			output = &decoded
+		}
+		testRun(t, test.stdin, test.expectedCode, output, test.args...)
+		if test.expectedCode == exitOK && (decoded.TxID != created.TxID || len(decoded.Records) != 1 ||
+			decoded.Records[0].IDKey != created.IDKey || !decoded.Records[0].Signer.Valid) {
+			t.Errorf("%s Failed: [%s] inputted and unexpected output %+v", t.Name(), test.name, decoded)
+		}
+	}
+}
+
+// TestRun_Usage will test invalid command lines
+func TestRun_Usage(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name         string
+			args         []string
+			expectedCode int
+		}{
+			{"no command", nil, exitUsage},
+			{"unknown command", []string{"unknown"}, exitUsage},
+			{"no identity command", []string{"identity"}, exitUsage},
+			{"unknown identity command", []string{"identity", "delete"}, exitUsage},
+			{"missing flag", []string{"sign", "--message", "hello"}, exitUsage},
+			{"unknown flag", []string{"sign", "--unknown"}, exitUsage},
+			{"help", []string{"decode", "-h"}, exitOK},
+			{"missing key file", []string{"sign", "--key", "missing.json", "--message", "hello"}, exitError},
+			{"key file and keystore", []string{"sign", "--key", "alice.json", "--keystore", "alice.keystore", "--message", "hello"}, exitUsage},
+			{"no key file or keystore", []string{"identity", "new"}, exitUsage},
+			{"missing keystore", []string{"sign", "--keystore", "missing.keystore", "--message", "hello"}, exitError},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(test.args, &env{getenv: testGetenv, stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}); code != test.expectedCode {
+			t.Errorf("%s Failed: [%s] inputted and expected exit code [%d] but got [%d]", t.Name(), test.name, test.expectedCode, code)
+		}
+	}
+}
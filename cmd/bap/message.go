@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+)
+
+// messageOutput is the output of the sign and verify commands
+type messageOutput struct {
+	Address   string `json:"address"`
+	Error     string `json:"error,omitempty"`
+	IDKey     string `json:"id_key,omitempty"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"` // Base64 Bitcoin Signed Message signature
+	Valid     bool   `json:"valid"`
+}
+
+// runSign signs a message (Bitcoin Signed Message) with the current key of an identity
+func runSign(e *env, args []string) error {
+	flags := newFlagSet(e, "sign")
+	source := newKeyFlags(flags, "identity key file")
+	message := flags.String("message", "", "message to sign (required)")
+	if err := parseFlags(flags, args, "message"); err != nil {
+		return err
+	} else if err = source.check(flags); err != nil {
+		return err
+	}
+
+	k, err := source.read(e)
+	if err != nil {
+		return err
+	}
+	key, address, err := k.currentKey()
+	if err != nil {
+		return err
+	}
+	var signature string
+	if signature, err = bsm.SignMessageString(key, []byte(*message)); err != nil {
+		return err
+	}
+	return writeJSON(e.stdout, &messageOutput{Address: address, IDKey: k.IDKey, Message: *message, Signature: signature, Valid: true})
+}
+
+// runVerify verifies a message signature, the exit code is non-zero if it is invalid
+func runVerify(e *env, args []string) error {
+	flags := newFlagSet(e, "verify")
+	address := flags.String("address", "", "signing address (required)")
+	signature := flags.String("signature", "", "base64 signature (required)")
+	message := flags.String("message", "", "signed message (required)")
+	if err := parseFlags(flags, args, "address", "signature", "message"); err != nil {
+		return err
+	}
+
+	output := &messageOutput{Address: *address, Message: *message, Signature: *signature}
+	raw, err := base64.StdEncoding.DecodeString(*signature)
+	if err == nil {
+		err = bsm.VerifyMessage(*address, raw, []byte(*message))
+	}
+	if err != nil {
+		output.Error = err.Error()
+	}
+	output.Valid = err == nil
+	if err = writeJSON(e.stdout, output); err != nil {
+		return err
+	} else if !output.Valid {
+		return fmt.Errorf("invalid signature for %s", *address)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Package did implements the did:bap DID method, resolving BAP identities from an indexer
+// Store into W3C DID documents
+//
+// A did:bap DID is the identity key prefixed with "did:bap:". The DID document lists every
+// signing address of the identity (from its ID records) as a verification method, with only
+// the current address used for authentication and assertions. Verification methods name the
+// address as a CAIP-10 blockchainAccountId on the bip122 chain id of mainnet or testnet.
+// Links in the ALIAS profile (http or https url and image, paymail) become service entries.
+//
+// Deactivation is a go-bap extension, it is not part of the BAP spec and other BAP
+// implementations do not recognize it. An identity is deactivated by a REVOKE of its
+// DeactivationHash, sha256("urn:bap:deactivate:" + idKey), signed by its root address or its
+// signing address at that height (see CreateDeactivation). A deactivated identity resolves
+// with no authentication or assertion methods.
+package did
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/bitcoinschema/go-bap"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Method is the DID method name
+const Method = "bap"
+
+// prefix is the prefix of every did:bap DID
+const prefix = "did:" + Method + ":"
+
+// ErrInvalidDID is returned when a DID is not a valid did:bap DID
+var ErrInvalidDID = errors.New("invalid did:bap DID")
+
+// New returns the did:bap DID of an identity key
+func New(idKey string) string {
+	return prefix + idKey
+}
+
+// Parse returns the identity key of a did:bap DID (a DID URL fragment, path or query is ignored)
+func Parse(did string) (string, error) {
+	if !strings.HasPrefix(did, prefix) {
+		return "", ErrInvalidDID
+	}
+	idKey := did[len(prefix):]
+	if index := strings.IndexAny(idKey, "/?#"); index >= 0 {
+		idKey = idKey[:index]
+	}
+	if err := bap.ValidateIDKey(idKey); err != nil {
+		return "", errors.Join(ErrInvalidDID, err)
+	}
+	return idKey, nil
+}
+
+// DeactivationHash returns the URN hash that is revoked to deactivate an identity (a go-bap
+// extension, see the package documentation)
+func DeactivationHash(idKey string) string {
+	hash := deactivationHash(idKey)
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateDeactivation returns a transaction deactivating the identity, it must be signed by
+// the root or current signing key of the identity
+func CreateDeactivation(idKey string, signingKey *ec.PrivateKey) (*transaction.Transaction, error) {
+	if len(idKey) == 0 {
+		return nil, &bap.MissingFieldError{Field: "idKey"}
+	}
+	return bap.CreateURNRevocation(deactivationHash(idKey), signingKey, 0)
+}
+
+// deactivationHash will hash the deactivation URN of an identity
+func deactivationHash(idKey string) [32]byte {
+	return sha256.Sum256([]byte("urn:bap:deactivate:" + idKey))
+}
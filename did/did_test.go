@@ -0,0 +1,82 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// TestParse will test the methods New() and Parse()
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			input         string
+			expectedIDKey string
+			expectedError bool
+		}{
+			{New(testutil.IDKey), testutil.IDKey, false},
+			{New(testutil.IDKey) + "#key-0", testutil.IDKey, false},
+			{New(testutil.IDKey) + "?versionId=1", testutil.IDKey, false},
+			{"did:bap:oqWsnpcTgXuEGSHRUGJUfY2518b", "oqWsnpcTgXuEGSHRUGJUfY2518b", false},
+			{"did:web:example.com", "", true},
+			{"did:bap:", "", true},
+			{"did:bap:not-an-id-key", "", true},
+			{testutil.IDKey, "", true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if idKey, err := Parse(test.input); err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.input, err.Error())
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.input)
+		} else if err != nil && !errors.Is(err, ErrInvalidDID) {
+			t.Errorf("%s Failed: [%s] inputted and expected ErrInvalidDID but got: %s", t.Name(), test.input, err.Error())
+		} else if idKey != test.expectedIDKey {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.input, test.expectedIDKey, idKey)
+		}
+	}
+}
+
+// TestCreateDeactivation will test the method CreateDeactivation()
+func TestCreateDeactivation(t *testing.T) {
+	t.Parallel()
+
+	key, address := testutil.SigningKey(t, testutil.IdentityKey, 0)
+	tx, err := CreateDeactivation(testutil.IDKey, key)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	records, err := bap.NewSignedFromOutputs(testOutputs(t, tx))
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(records) != 1 {
+		t.Fatalf("expected 1 record but got %d", len(records))
+	} else if records[0].Type != bap.REVOKE || records[0].URNHash != DeactivationHash(testutil.IDKey) {
+		t.Fatalf("unexpected record: %+v", records[0].Bap)
+	} else if !records[0].Signer.Valid || records[0].Signer.Address != address {
+		t.Fatalf("unexpected signer: %+v", records[0].Signer)
+	}
+
+	if _, err = CreateDeactivation("", key); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	}
+	var nilKey *ec.PrivateKey
+	if _, err = CreateDeactivation(testutil.IDKey, nilKey); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	}
+}
+
+// ExampleNew example using New()
+func ExampleNew() {
+	fmt.Print(New("8bafa4ca97d770276253585cb2a49da1775ec7aeed3178e346c8c1b55eaf5ca2"))
+	// Output:did:bap:8bafa4ca97d770276253585cb2a49da1775ec7aeed3178e346c8c1b55eaf5ca2
+}
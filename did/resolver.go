@@ -0,0 +1,202 @@
+package did
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+// Context is the JSON-LD context of DID documents
+const Context = "https://www.w3.org/ns/did/v1"
+
+// VerificationMethodType is the verification method type of BAP signing addresses
+const VerificationMethodType = "EcdsaSecp256k1RecoveryMethod2020"
+
+// chainIDs are the CAIP-2 chain ids (bip122 namespace, the first 32 characters of the
+// genesis block hash) used in blockchainAccountId, by network name
+var chainIDs = map[string]string{
+	chaincfg.MainNet.Name: "bip122:000000000019d6689c085ae165831e93",
+	chaincfg.TestNet.Name: "bip122:000000000933ea01ad0ee984209779ba",
+}
+
+// Document is a W3C DID document
+type Document struct {
+	Context            []string              `json:"@context"`
+	ID                 string                `json:"id"`
+	Controller         string                `json:"controller"`
+	VerificationMethod []*VerificationMethod `json:"verificationMethod"`
+	Authentication     []string              `json:"authentication,omitempty"`
+	AssertionMethod    []string              `json:"assertionMethod,omitempty"`
+	Service            []*Service            `json:"service,omitempty"`
+}
+
+// VerificationMethod is a signing address of the identity
+type VerificationMethod struct {
+	ID                  string `json:"id"`
+	Type                string `json:"type"`
+	Controller          string `json:"controller"`
+	BlockchainAccountID string `json:"blockchainAccountId"` // CAIP-10 account id of the address
+}
+
+// Service is a service endpoint from the identity profile
+type Service struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+// DocumentMetadata is the metadata of a resolved DID document
+type DocumentMetadata struct {
+	Created     string `json:"created,omitempty"`
+	Deactivated bool   `json:"deactivated,omitempty"`
+	Updated     string `json:"updated,omitempty"`
+	VersionID   string `json:"versionId,omitempty"` // Txid of the latest update
+}
+
+// ResolutionMetadata is the metadata of a DID resolution
+type ResolutionMetadata struct {
+	ContentType string `json:"contentType,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ResolutionResult is the result of resolving a DID
+type ResolutionResult struct {
+	Document           *Document           `json:"didDocument"`
+	DocumentMetadata   *DocumentMetadata   `json:"didDocumentMetadata"`
+	ResolutionMetadata *ResolutionMetadata `json:"didResolutionMetadata"`
+}
+
+// Resolver resolves did:bap DIDs from an indexer Store
+type Resolver struct {
+	chainID string
+	query   *indexer.Query
+}
+
+// NewResolver returns a resolver over the store, for the given network (mainnet if nil).
+// Only mainnet and testnet have a chain id, other networks return bap.ErrWrongNetwork.
+func NewResolver(store indexer.Store, network *chaincfg.Params) (*Resolver, error) {
+	if network == nil {
+		network = &chaincfg.MainNet
+	}
+	chainID, ok := chainIDs[network.Name]
+	if !ok {
+		return nil, fmt.Errorf("%w: no chain id for network %s", bap.ErrWrongNetwork, network.Name)
+	}
+	return &Resolver{chainID: chainID, query: indexer.NewQuery(store)}, nil
+}
+
+// Resolve returns the DID document of a did:bap DID (ErrInvalidDID or indexer.ErrNotFound on failure)
+func (r *Resolver) Resolve(did string) (*ResolutionResult, error) {
+	idKey, err := Parse(did)
+	if err != nil {
+		return nil, err
+	}
+	var identity *indexer.IdentityRecord
+	if identity, err = r.query.Identity(idKey); err != nil {
+		return nil, err
+	}
+	did = New(idKey)
+
+	document := &Document{
+		Context:            []string{Context},
+		ID:                 did,
+		Controller:         did,
+		VerificationMethod: make([]*VerificationMethod, len(identity.Addresses)),
+	}
+	metadata := &DocumentMetadata{}
+	var updated indexer.Block
+	for index, a := range identity.Addresses {
+		document.VerificationMethod[index] = &VerificationMethod{
+			ID:                  did + "#key-" + strconv.Itoa(index),
+			Type:                VerificationMethodType,
+			Controller:          did,
+			BlockchainAccountID: r.chainID + ":" + a.Address,
+		}
+		if index == 0 {
+			metadata.Created = formatTime(a.Block)
+		}
+		updated, metadata.VersionID = a.Block, a.TxID
+	}
+	if identity.Profile != nil {
+		document.Service = services(did, identity.Profile.Profile)
+		if isLater(identity.Profile.Block, updated) {
+			updated, metadata.VersionID = identity.Profile.Block, identity.Profile.TxID
+		}
+	}
+
+	var deactivation *indexer.AttestationRecord
+	if deactivation, err = r.deactivation(identity); err != nil {
+		return nil, err
+	} else if deactivation != nil {
+		metadata.Deactivated = true
+		updated, metadata.VersionID = deactivation.Block, deactivation.TxID
+	} else if len(document.VerificationMethod) > 0 {
+		current := document.VerificationMethod[len(document.VerificationMethod)-1].ID
+		document.Authentication = []string{current}
+		document.AssertionMethod = []string{current}
+	}
+	metadata.Updated = formatTime(updated)
+
+	return &ResolutionResult{
+		Document:           document,
+		DocumentMetadata:   metadata,
+		ResolutionMetadata: &ResolutionMetadata{ContentType: "application/did+ld+json"},
+	}, nil
+}
+
+// deactivation returns the first authorized deactivation of the identity, if any
+func (r *Resolver) deactivation(identity *indexer.IdentityRecord) (*indexer.AttestationRecord, error) {
+	records, _, err := r.query.Attestations(indexer.AttestationFilter{
+		Type:      bap.REVOKE,
+		URNHashes: []string{DeactivationHash(identity.IDKey)},
+	}, indexer.Page{})
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record.AttestorIDKey == identity.IDKey || record.Address == identity.RootAddress {
+			return record, nil
+		}
+	}
+	return nil, nil
+}
+
+// services returns the service entries of the links in a valid profile: http or https
+// website and image URLs and a well-formed paymail
+func services(did, profile string) []*Service {
+	p, err := bap.ParseProfile(profile)
+	if err != nil {
+		return nil
+	}
+	var list []*Service
+	if website, ok := p.Website(); ok {
+		list = append(list, &Service{ID: did + "#url", Type: "LinkedDomains", ServiceEndpoint: website})
+	}
+	if paymail, ok := p.PaymailAddress(); ok {
+		list = append(list, &Service{ID: did + "#paymail", Type: "Paymail", ServiceEndpoint: paymail})
+	}
+	if image, ok := p.ImageRef(); ok && !image.OnChain() {
+		list = append(list, &Service{ID: did + "#image", Type: "Image", ServiceEndpoint: image.URL})
+	}
+	return list
+}
+
+// isLater returns true if block a is after block b (unconfirmed blocks are the latest)
+func isLater(a, b indexer.Block) bool {
+	if a.Height == 0 || b.Height == 0 {
+		return a.Height == 0 && b.Height != 0
+	}
+	return a.Height > b.Height
+}
+
+// formatTime returns the block time as an XML datetime, or empty if unknown
+func formatTime(block indexer.Block) string {
+	if block.Time == 0 {
+		return ""
+	}
+	return time.Unix(int64(block.Time), 0).UTC().Format(time.RFC3339)
+}
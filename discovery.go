@@ -0,0 +1,146 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+
+	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	"github.com/bsv-blockchain/go-sdk/compat/bip39"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+const (
+	// DefaultRootPath is the parent path of the identity root keys (<path>/0', <path>/1', ...) of a master key
+	DefaultRootPath = "424150'/0'"
+
+	// DefaultRootGapLimit is the number of consecutive unused root keys after which discovery stops
+	DefaultRootGapLimit = 5
+
+	// masterPath is the path of the master key, which is the first candidate root key
+	masterPath = "m"
+)
+
+// DiscoverOptions are the limits and derivation path used to discover identities
+type DiscoverOptions struct {
+	GapLimit     uint32 // Consecutive unpublished signing addresses of a root key (DefaultGapLimit if 0)
+	RootGapLimit uint32 // Consecutive unused root keys (DefaultRootGapLimit if 0)
+	RootPath     string // Parent path of the hardened root keys (DefaultRootPath if empty)
+}
+
+// DiscoveredIdentity is an identity found in the indexed history of a master key
+type DiscoveredIdentity struct {
+	Counter     uint32 `json:"counter"`
+	IDKey       string `json:"id_key"`
+	Path        string `json:"path"`
+	RootAddress string `json:"root_address"`
+	XPrivateKey string `json:"xprv"`
+}
+
+// Identity returns the identity at its discovered counter
+func (d *DiscoveredIdentity) Identity() (*Identity, error) {
+	return NewIdentity(d.XPrivateKey, d.IDKey, d.Counter)
+}
+
+// DiscoverIdentities reconstructs the identities of a master key from indexed ID records.
+// The candidate root keys are the master key itself and the hardened children of the root
+// path (see DefaultRootPath), scanned until RootGapLimit consecutive root keys are unused.
+// The signing addresses of each root key are scanned as in Identity.RecoverCounter, the id
+// key is the identity that published the first address found and the counter is its last
+// published address.
+func DiscoverIdentities(xPrivateKey string, lookup AddressLookup, options *DiscoverOptions) ([]*DiscoveredIdentity, error) {
+	if len(xPrivateKey) == 0 {
+		return nil, &MissingFieldError{Field: "xPrivateKey"}
+	} else if lookup == nil {
+		return nil, &MissingFieldError{Field: "lookup"}
+	}
+	master, err := hd.NewKeyFromString(xPrivateKey)
+	if err != nil {
+		return nil, err
+	} else if !master.IsPrivate() {
+		return nil, errors.New("xPrivateKey is not a private key")
+	}
+
+	opts := DiscoverOptions{GapLimit: DefaultGapLimit, RootGapLimit: DefaultRootGapLimit, RootPath: DefaultRootPath}
+	if options != nil {
+		if options.GapLimit > 0 {
+			opts.GapLimit = options.GapLimit
+		}
+		if options.RootGapLimit > 0 {
+			opts.RootGapLimit = options.RootGapLimit
+		}
+		if len(options.RootPath) > 0 {
+			opts.RootPath = options.RootPath
+		}
+	}
+
+	var identities []*DiscoveredIdentity
+	var identity *DiscoveredIdentity
+	if identity, err = discoverRoot(master, masterPath, "", lookup, opts.GapLimit); err != nil {
+		return nil, err
+	} else if identity != nil {
+		identities = append(identities, identity)
+	}
+	for index, misses := uint32(0), uint32(0); misses < opts.RootGapLimit && index < hd.HardenedKeyStart; index++ {
+		path := fmt.Sprintf("%s/%d'", opts.RootPath, index)
+		if identity, err = discoverRoot(master, masterPath+"/"+path, path, lookup, opts.GapLimit); err != nil {
+			return nil, err
+		} else if identity == nil {
+			misses++
+			continue
+		}
+		identities = append(identities, identity)
+		misses = 0
+	}
+	return identities, nil
+}
+
+// DiscoverIdentitiesFromMnemonic reconstructs the identities of the master key of a BIP39
+// mnemonic and password (see DiscoverIdentities)
+func DiscoverIdentitiesFromMnemonic(mnemonic, password string, lookup AddressLookup,
+	options *DiscoverOptions,
+) ([]*DiscoveredIdentity, error) {
+	if len(mnemonic) == 0 {
+		return nil, &MissingFieldError{Field: "mnemonic"}
+	}
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, password)
+	if err != nil {
+		return nil, err
+	}
+	var master *hd.ExtendedKey
+	if master, err = hd.NewMaster(seed, &chaincfg.MainNet); err != nil {
+		return nil, err
+	}
+	return DiscoverIdentities(master.String(), lookup, options)
+}
+
+// discoverRoot returns the identity of the root key at a path of the master key, or nil if
+// none of its signing addresses were published
+func discoverRoot(master *hd.ExtendedKey, name, path string, lookup AddressLookup,
+	gapLimit uint32,
+) (*DiscoveredIdentity, error) {
+	rootKey, err := master.DeriveChildFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	xPrivateKey := rootKey.String()
+
+	var (
+		counter uint32
+		found   bool
+		idKey   string
+	)
+	if idKey, counter, found, err = scanAddresses(xPrivateKey, "", lookup, gapLimit); err != nil || !found {
+		return nil, err
+	}
+	var rootSigningKey *hd.ExtendedKey
+	if rootSigningKey, err = deriveSigningKey(xPrivateKey, 0); err != nil {
+		return nil, err
+	}
+	return &DiscoveredIdentity{
+		Counter:     counter,
+		IDKey:       idKey,
+		Path:        name,
+		RootAddress: rootSigningKey.Address(&chaincfg.MainNet),
+		XPrivateKey: xPrivateKey,
+	}, nil
+}
@@ -0,0 +1,248 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+// testMnemonic is the BIP39 test vector mnemonic
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// testRootKey returns the root key at a path of an xprv
+func testRootKey(t testing.TB, xPrivateKey, path string) string {
+	master, err := hd.NewKeyFromString(xPrivateKey)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var rootKey *hd.ExtendedKey
+	if rootKey, err = master.DeriveChildFromPath(path); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return rootKey.String()
+}
+
+// publish adds the addresses of the given counters of a root key to the lookup
+func (l testLookup) publish(t testing.TB, xPrivateKey, idKey string, counters ...uint32) testLookup {
+	for _, counter := range counters {
+		hdKey, err := deriveSigningKey(xPrivateKey, counter)
+		if err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+		l[hdKey.Address(&chaincfg.MainNet)] = idKey
+	}
+	return l
+}
+
+// TestDiscoverIdentities will test the method DiscoverIdentities()
+func TestDiscoverIdentities(t *testing.T) {
+	t.Parallel()
+
+	var (
+		root0 = testRootKey(t, privateKey, "424150'/0'/0'")
+		root2 = testRootKey(t, privateKey, "424150'/0'/2'")
+		root8 = testRootKey(t, privateKey, "424150'/0'/8'")
+		other = testRootKey(t, privateKey, "0'/3'")
+	)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name     string
+			lookup   testLookup
+			options  *DiscoverOptions
+			expected []string
+		}{
+			{
+				"master key",
+				testLookup{}.publish(t, privateKey, idKey, 0, 1),
+				nil,
+				[]string{"m " + idKey + " 1"},
+			},
+			{
+				"root keys",
+				testLookup{}.publish(t, privateKey, idKey, 0).publish(t, root0, "id0", 0, 1, 2).publish(t, root2, "id2", 0),
+				nil,
+				[]string{"m " + idKey + " 0", "m/424150'/0'/0' id0 2", "m/424150'/0'/2' id2 0"},
+			},
+			{
+				"beyond the root gap limit",
+				testLookup{}.publish(t, root2, "id2", 0).publish(t, root8, "id8", 0),
+				nil,
+				[]string{"m/424150'/0'/2' id2 0"},
+			},
+			{
+				"root gap limit",
+				testLookup{}.publish(t, root2, "id2", 0).publish(t, root8, "id8", 0),
+				&DiscoverOptions{RootGapLimit: 6},
+				[]string{"m/424150'/0'/2' id2 0", "m/424150'/0'/8' id8 0"},
+			},
+			{
+				"skipped signing addresses",
+				testLookup{}.publish(t, root0, "id0", 3),
+				&DiscoverOptions{GapLimit: 4},
+				[]string{"m/424150'/0'/0' id0 3"},
+			},
+			{
+				"beyond the gap limit",
+				testLookup{}.publish(t, root0, "id0", 3),
+				&DiscoverOptions{GapLimit: 3},
+				nil,
+			},
+			{
+				"root path",
+				testLookup{}.publish(t, other, "id3", 0, 1),
+				&DiscoverOptions{RootPath: "0'"},
+				[]string{"m/0'/3' id3 1"},
+			},
+			{
+				"nothing published",
+				testLookup{},
+				nil,
+				nil,
+			},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		identities, err := DiscoverIdentities(privateKey, test.lookup, test.options)
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		}
+		var discovered []string
+		for _, identity := range identities {
+			discovered = append(discovered, fmt.Sprintf("%s %s %d", identity.Path, identity.IDKey, identity.Counter))
+		}
+		if fmt.Sprint(discovered) != fmt.Sprint(test.expected) {
+			t.Errorf("%s Failed: [%s] inputted and expected %v but got %v", t.Name(), test.name, test.expected, discovered)
+		}
+	}
+}
+
+// TestDiscoverIdentities_Identity will test restoring a discovered identity
+func TestDiscoverIdentities_Identity(t *testing.T) {
+	t.Parallel()
+
+	root1 := testRootKey(t, privateKey, "424150'/0'/1'")
+	identities, err := DiscoverIdentities(privateKey, testLookup{}.publish(t, root1, idKey, 0, 1, 2), nil)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(identities) != 1 {
+		t.Fatalf("%s Failed: expected 1 identity but got %d", t.Name(), len(identities))
+	}
+
+	rootKey, _ := deriveSigningKey(root1, 0)
+	if identities[0].XPrivateKey != root1 || identities[0].RootAddress != rootKey.Address(&chaincfg.MainNet) {
+		t.Fatalf("%s Failed: expected the root key %s but got %+v", t.Name(), root1, identities[0])
+	}
+
+	var identity *Identity
+	if identity, err = identities[0].Identity(); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var records []*SignedBap
+	if tx, rotateErr := identity.Rotate(); rotateErr != nil {
+		t.Fatalf("error occurred: %s", rotateErr.Error())
+	} else if records, err = signedRecordsFromHex(tx.Hex()); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	signer, _ := deriveSigningKey(root1, 2)
+	if identity.IDKey() != idKey || records[0].Signer.Address != signer.Address(&chaincfg.MainNet) {
+		t.Fatalf("%s Failed: expected the rotation to be signed by 0/2 of %s", t.Name(), idKey)
+	}
+}
+
+// TestDiscoverIdentities_Errors will test invalid discovery inputs
+func TestDiscoverIdentities_Errors(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name        string
+			xPrivateKey string
+			lookup      AddressLookup
+			options     *DiscoverOptions
+		}{
+			{"missing key", "", testLookup{}, nil},
+			{"invalid key", "invalid-key", testLookup{}, nil},
+			{"public key", "xpub661MyMwAqRbcEj3kQ1G5zqykXYJdjcpLGRXCaQjehABtJ7nDodrn5igY9vSJiMcLPtyMaN2XrHqtsLeJ9p9fP5RgBhpc3bwaJGJ3aPdYiM6", testLookup{}, nil},
+			{"nil lookup", privateKey, nil, nil},
+			{"failing lookup", privateKey, failingLookup{}, nil},
+			{"invalid root path", privateKey, testLookup{}, &DiscoverOptions{RootPath: "m/invalid"}},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err := DiscoverIdentities(test.xPrivateKey, test.lookup, test.options); err == nil {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		}
+	}
+
+	if _, err := DiscoverIdentities(privateKey, nil, nil); !errors.Is(err, ErrMissingField) {
+		t.Errorf("%s Failed: expected a missing field error but got %v", t.Name(), err)
+	}
+}
+
+// TestDiscoverIdentitiesFromMnemonic will test the method DiscoverIdentitiesFromMnemonic()
+func TestDiscoverIdentitiesFromMnemonic(t *testing.T) {
+	t.Parallel()
+
+	master, err := hd.GenerateHDKeyFromMnemonic(testMnemonic, "", &chaincfg.MainNet)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	root0 := testRootKey(t, master.String(), "424150'/0'/0'")
+	lookup := testLookup{}.publish(t, root0, idKey, 0, 1)
+
+	var identities []*DiscoveredIdentity
+	if identities, err = DiscoverIdentitiesFromMnemonic(testMnemonic, "", lookup, nil); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(identities) != 1 || identities[0].XPrivateKey != root0 || identities[0].Counter != 1 {
+		t.Fatalf("%s Failed: expected the identity at m/424150'/0'/0' but got %+v", t.Name(), identities)
+	}
+
+	// A password derives another master key
+	if identities, err = DiscoverIdentitiesFromMnemonic(testMnemonic, "password", lookup, nil); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(identities) != 0 {
+		t.Fatalf("%s Failed: expected no identities but got %d", t.Name(), len(identities))
+	}
+
+	if _, err = DiscoverIdentitiesFromMnemonic("", "", lookup, nil); !errors.Is(err, ErrMissingField) {
+		t.Fatalf("%s Failed: expected a missing field error but got %v", t.Name(), err)
+	} else if _, err = DiscoverIdentitiesFromMnemonic("abandon abandon invalid", "", lookup, nil); err == nil {
+		t.Fatalf("%s Failed: expected an invalid mnemonic error", t.Name())
+	}
+}
+
+// ExampleDiscoverIdentities example using DiscoverIdentities()
+func ExampleDiscoverIdentities() {
+	lookup := testLookup{
+		"1A9VQqdNJrvVF73nf879n2fES6cd5nWNid": idKey,
+		"1G2AKzC4XD9iuQQJhS9WXzXXGXKdEN9DRV": idKey,
+	}
+	identities, err := DiscoverIdentities(privateKey, lookup, nil)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	for _, identity := range identities {
+		fmt.Printf("%s: counter %d", identity.Path, identity.Counter)
+	}
+	// Output:m: counter 1
+}
+
+// BenchmarkDiscoverIdentities benchmarks the method DiscoverIdentities()
+func BenchmarkDiscoverIdentities(b *testing.B) {
+	lookup := testLookup{}.publish(b, privateKey, idKey, 0, 1)
+	for i := 0; i < b.N; i++ {
+		_, _ = DiscoverIdentities(privateKey, lookup, nil)
+	}
+}
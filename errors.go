@@ -0,0 +1,150 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors, use with errors.Is()
+var (
+	ErrInvalidProfile    = errors.New("invalid profile")
+	ErrInvalidProof      = errors.New("invalid proof")
+	ErrInvalidRecordType = errors.New("invalid record type")
+	ErrInvalidSignature  = errors.New("invalid signature")
+	ErrMalformedTape     = errors.New("malformed tape")
+	ErrMissingField      = errors.New("missing required field")
+	ErrNoRecord          = errors.New("no BAP record found")
+	ErrThresholdNotMet   = errors.New("attestation threshold not met")
+	ErrUnauthorized      = errors.New("record not signed by an authorized address")
+	ErrWrongNetwork      = errors.New("wrong network")
+)
+
+// MissingFieldError is returned when a required input field is empty
+type MissingFieldError struct {
+	Field string
+}
+
+// Error returns the error message
+func (e *MissingFieldError) Error() string {
+	return fmt.Sprintf("missing required field: %s", e.Field)
+}
+
+// Unwrap returns ErrMissingField
+func (e *MissingFieldError) Unwrap() error {
+	return ErrMissingField
+}
+
+// RecordTypeError is returned when a record has an unknown or unexpected type
+type RecordTypeError struct {
+	Type AttestationType
+}
+
+// Error returns the error message
+func (e *RecordTypeError) Error() string {
+	return fmt.Sprintf("invalid record type: %q", string(e.Type))
+}
+
+// Unwrap returns ErrInvalidRecordType
+func (e *RecordTypeError) Unwrap() error {
+	return ErrInvalidRecordType
+}
+
+// TapeError is returned when a tape cannot be parsed into a BAP record
+type TapeError struct {
+	Type   AttestationType // Record type, if it was read
+	Field  string          // Field that failed to parse, if known
+	Reason string          // Human readable reason
+	Err    error           // Underlying error, if any
+}
+
+// Error returns the error message
+func (e *TapeError) Error() string {
+	msg := "invalid"
+	if len(e.Type) > 0 {
+		msg += " " + string(e.Type)
+	}
+	msg += " record"
+	if len(e.Field) > 0 {
+		msg += ": " + e.Field
+	}
+	if len(e.Reason) > 0 {
+		msg += ": " + e.Reason
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Is reports ErrMalformedTape as a match
+func (e *TapeError) Is(target error) bool {
+	return target == ErrMalformedTape
+}
+
+// Unwrap returns the underlying error
+func (e *TapeError) Unwrap() error {
+	return e.Err
+}
+
+// SignatureError is returned when an AIP signature does not validate
+type SignatureError struct {
+	Address string // Address the signature claims to be from
+	Err     error  // Underlying validation error, if any
+}
+
+// Error returns the error message
+func (e *SignatureError) Error() string {
+	msg := "invalid signature"
+	if len(e.Address) > 0 {
+		msg += " for address " + e.Address
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Is reports ErrInvalidSignature as a match
+func (e *SignatureError) Is(target error) bool {
+	return target == ErrInvalidSignature
+}
+
+// Unwrap returns the underlying error
+func (e *SignatureError) Unwrap() error {
+	return e.Err
+}
+
+// NetworkError is returned when an address does not belong to the expected network
+type NetworkError struct {
+	Address string
+	Network string // Expected network name
+}
+
+// Error returns the error message
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("wrong network: address %s is not a %s address", e.Address, e.Network)
+}
+
+// Unwrap returns ErrWrongNetwork
+func (e *NetworkError) Unwrap() error {
+	return ErrWrongNetwork
+}
+
+// ProfileError is returned when an ALIAS profile does not match the profile schema
+type ProfileError struct {
+	Field  string // Profile field, empty for the profile itself
+	Reason string // Human readable reason
+}
+
+// Error returns the error message
+func (e *ProfileError) Error() string {
+	if len(e.Field) == 0 {
+		return "invalid profile: " + e.Reason
+	}
+	return fmt.Sprintf("invalid profile: %s: %s", e.Field, e.Reason)
+}
+
+// Unwrap returns ErrInvalidProfile
+func (e *ProfileError) Unwrap() error {
+	return ErrInvalidProfile
+}
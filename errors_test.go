@@ -0,0 +1,123 @@
+package bap
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bitcoinschema/go-bob"
+)
+
+// TestErrors will test the typed errors with errors.Is() and errors.As()
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	// Missing field
+	_, err := CreateIdentity(privateKey, "", 0)
+	var missingField *MissingFieldError
+	if !errors.Is(err, ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	} else if !errors.As(err, &missingField) || missingField.Field != "idKey" {
+		t.Fatalf("expected missing field idKey but got: %v", err)
+	} else if err.Error() != "missing required field: idKey" {
+		t.Fatalf("unexpected error message: %s", err.Error())
+	}
+
+	// Missing field in a batch
+	_, err = CreateAttestations(testAttestorKey(), []AttestationRequest{{IDKey: idKey}})
+	if !errors.As(err, &missingField) || missingField.Field != "attributeName" {
+		t.Fatalf("expected missing field attributeName but got: %v", err)
+	}
+
+	// Missing signing key
+	if _, err = CreateAttestation(idKey, nil, "person", "john", "secret"); !errors.Is(err, ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	}
+
+	// Malformed tape
+	var bobData *bob.Tx
+	if bobData, err = bob.NewFromString(sampleValidBobTx); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	_, err = NewFromTape(&bobData.Out[0].Tape[0])
+	var tapeErr *TapeError
+	if !errors.Is(err, ErrMalformedTape) || !errors.As(err, &tapeErr) {
+		t.Fatalf("expected ErrMalformedTape but got: %v", err)
+	}
+	if _, err = NewFromTape(nil); !errors.Is(err, ErrMalformedTape) {
+		t.Fatalf("expected ErrMalformedTape but got: %v", err)
+	}
+
+	// Bad sequence
+	badSequence := "not-a-number"
+	bobData.Out[0].Tape[1].Cell[3].S = &badSequence
+	_, err = NewFromTape(&bobData.Out[0].Tape[1])
+	if !errors.As(err, &tapeErr) || tapeErr.Field != "sequence" || tapeErr.Type != ATTEST {
+		t.Fatalf("expected sequence tape error but got: %v", err)
+	} else if !errors.Is(err, ErrMalformedTape) {
+		t.Fatalf("expected ErrMalformedTape but got: %v", err)
+	}
+
+	// No record
+	if _, err = NewFromTapes(bobData.Out[1].Tape); !errors.Is(err, ErrNoRecord) {
+		t.Fatalf("expected ErrNoRecord but got: %v", err)
+	}
+}
+
+// TestVerifyTapes will test the method VerifyTapes()
+func TestVerifyTapes(t *testing.T) {
+	t.Parallel()
+
+	bobData, err := bob.NewFromString(sampleValidBobTx)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if err = VerifyTapes(bobData.Out[0].Tape); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Tampered record
+	tampered := "REVOKE"
+	bobData.Out[0].Tape[1].Cell[1].S = &tampered
+	err = VerifyTapes(bobData.Out[0].Tape)
+	var sigErr *SignatureError
+	if !errors.Is(err, ErrInvalidSignature) || !errors.As(err, &sigErr) {
+		t.Fatalf("expected ErrInvalidSignature but got: %v", err)
+	} else if sigErr.Address != "134a6TXxzgQ9Az3w8BcvgdZyA5UqRL89da" {
+		t.Fatalf("unexpected address: %s", sigErr.Address)
+	}
+
+	// No signature
+	if err = VerifyTapes(bobData.Out[1].Tape); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature but got: %v", err)
+	}
+}
+
+// TestNetworkError will test the NetworkError type
+func TestNetworkError(t *testing.T) {
+	t.Parallel()
+
+	var err error = &NetworkError{Address: "mzBc4XEFSdzCDcTxAgf6EZXgsZWpztRhef", Network: "mainnet"}
+	if !errors.Is(err, ErrWrongNetwork) {
+		t.Fatalf("expected ErrWrongNetwork")
+	}
+	err = &RecordTypeError{Type: "UNKNOWN"}
+	if !errors.Is(err, ErrInvalidRecordType) {
+		t.Fatalf("expected ErrInvalidRecordType")
+	}
+}
+
+// TestProfileError will test the ProfileError type
+func TestProfileError(t *testing.T) {
+	t.Parallel()
+
+	var err error = &ProfileError{Field: "url", Reason: "does not match the pattern"}
+	var profileErr *ProfileError
+	if !errors.Is(err, ErrInvalidProfile) || !errors.As(err, &profileErr) || profileErr.Field != "url" {
+		t.Fatalf("expected ErrInvalidProfile for the url field")
+	} else if err.Error() != "invalid profile: url: does not match the pattern" {
+		t.Fatalf("unexpected error message: %s", err.Error())
+	}
+	if err = (&ProfileError{Reason: "not a JSON object"}); err.Error() != "invalid profile: not a JSON object" {
+		t.Fatalf("unexpected error message: %s", err.Error())
+	}
+}
@@ -0,0 +1,75 @@
+package bap
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ExpiryThreshold separates block height expiries from unix timestamp expiries,
+// the same convention as nLockTime
+const ExpiryThreshold = 500000000
+
+// Expiry is the optional expiry of an attestation, carried in the ATTEST record after the
+// sequence. Values below ExpiryThreshold are block heights, others are unix timestamps,
+// and 0 never expires.
+type Expiry uint64
+
+// ExpiresAtHeight returns the expiry at a block height
+func ExpiresAtHeight(height uint32) (Expiry, error) {
+	if height >= ExpiryThreshold {
+		return 0, fmt.Errorf("expiry height %d is not below %d", height, ExpiryThreshold)
+	}
+	return Expiry(height), nil
+}
+
+// ExpiresAt returns the expiry at a point in time
+func ExpiresAt(t time.Time) (Expiry, error) {
+	if t.Unix() < ExpiryThreshold {
+		return 0, fmt.Errorf("expiry time %s is before %s", t.UTC(), time.Unix(ExpiryThreshold, 0).UTC())
+	}
+	return Expiry(t.Unix()), nil
+}
+
+// ParseExpiry reads the expiry cell of an ATTEST record
+func ParseExpiry(cell string) (Expiry, error) {
+	expiry, err := strconv.ParseUint(cell, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return Expiry(expiry), nil
+}
+
+// IsHeight returns true if the expiry is a block height
+func (e Expiry) IsHeight() bool {
+	return e > 0 && e < ExpiryThreshold
+}
+
+// IsTime returns true if the expiry is a unix timestamp
+func (e Expiry) IsTime() bool {
+	return e >= ExpiryThreshold
+}
+
+// Time returns the time of a timestamp expiry (zero if it is not one)
+func (e Expiry) Time() time.Time {
+	if !e.IsTime() {
+		return time.Time{}
+	}
+	return time.Unix(int64(e), 0).UTC()
+}
+
+// Expired returns true if the expiry has passed at the chain height (height expiries) or
+// time (timestamp expiries). A height expiry is never expired at height 0 (unknown).
+func (e Expiry) Expired(height uint32, now time.Time) bool {
+	if e.IsHeight() {
+		return height > 0 && uint64(height) >= uint64(e)
+	} else if e.IsTime() {
+		return now.Unix() >= int64(e)
+	}
+	return false
+}
+
+// String returns the expiry cell value
+func (e Expiry) String() string {
+	return strconv.FormatUint(uint64(e), 10)
+}
@@ -0,0 +1,155 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	"github.com/bitcoinschema/go-bpu"
+)
+
+// TestExpiry_Expired will test the method Expired()
+func TestExpiry_Expired(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name            string
+			expiry          Expiry
+			height          uint32
+			expectedHeight  bool
+			expectedExpired bool
+		}{
+			{"no expiry", 0, 900000, false, false},
+			{"height before", 850000, 849999, true, false},
+			{"height reached", 850000, 850000, true, true},
+			{"height passed", 850000, 900000, true, true},
+			{"unknown height", 850000, 0, true, false},
+			{"time before", Expiry(now.Unix() + 1), 0, false, false},
+			{"time reached", Expiry(now.Unix()), 0, false, true},
+			{"time passed", ExpiryThreshold, 100, false, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if isHeight := test.expiry.IsHeight(); isHeight != test.expectedHeight {
+			t.Errorf("%s Failed: [%s] inputted and expected height [%t] but got [%t]", t.Name(), test.name, test.expectedHeight, isHeight)
+		} else if expired := test.expiry.Expired(test.height, now); expired != test.expectedExpired {
+			t.Errorf("%s Failed: [%s] inputted and expected expired [%t] but got [%t]", t.Name(), test.name, test.expectedExpired, expired)
+		}
+	}
+}
+
+// TestExpiresAt will test the methods ExpiresAt() and ExpiresAtHeight()
+func TestExpiresAt(t *testing.T) {
+	t.Parallel()
+
+	if expiry, err := ExpiresAtHeight(850000); err != nil || !expiry.IsHeight() || expiry.String() != "850000" {
+		t.Fatalf("%s Failed: unexpected height expiry %s: %v", t.Name(), expiry, err)
+	}
+	if _, err := ExpiresAtHeight(ExpiryThreshold); err == nil {
+		t.Fatalf("%s Failed: expected an error for a height at the threshold", t.Name())
+	}
+
+	date := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if expiry, err := ExpiresAt(date); err != nil || !expiry.IsTime() || !expiry.Time().Equal(date) {
+		t.Fatalf("%s Failed: unexpected time expiry %s: %v", t.Name(), expiry, err)
+	}
+	if _, err := ExpiresAt(time.Unix(1000, 0)); err == nil {
+		t.Fatalf("%s Failed: expected an error for a time before the threshold", t.Name())
+	}
+	if !Expiry(100).Time().IsZero() {
+		t.Fatalf("%s Failed: expected no time for a height expiry", t.Name())
+	}
+}
+
+// TestCreateAttestationWithExpiry will test the method CreateAttestationWithExpiry()
+func TestCreateAttestationWithExpiry(t *testing.T) {
+	t.Parallel()
+
+	key, _ := testutil.SigningKey(t, testProofAttestorKey, 0)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name             string
+			expiry           Expiry
+			expectedSequence uint64
+		}{
+			{"height", 850000, 0},
+			{"time", 1900000000, 0},
+			{"no expiry", 0, 0},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		tx, err := CreateAttestationWithExpiry(idKey, key, "name", "John", "secret", test.expiry)
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		}
+		var records []*SignedBap
+		if records, err = signedRecordsFromHex(tx.Hex()); err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if len(records) != 1 || !records[0].Signer.Valid {
+			t.Errorf("%s Failed: [%s] inputted and expected one signed record but got %d", t.Name(), test.name, len(records))
+		} else if records[0].Expiry != test.expiry || records[0].Sequence != test.expectedSequence {
+			t.Errorf("%s Failed: [%s] inputted and expected expiry [%s] but got [%s]", t.Name(), test.name, test.expiry, records[0].Expiry)
+		}
+	}
+
+	// Missing fields are reported like CreateAttestation
+	if _, err := CreateAttestationWithExpiry("", key, "name", "John", "secret", 850000); !errors.Is(err, ErrMissingField) {
+		t.Fatalf("%s Failed: expected ErrMissingField but got: %v", t.Name(), err)
+	}
+}
+
+// TestNewFromTape_Expiry will test reading the expiry cell of a tape
+func TestNewFromTape_Expiry(t *testing.T) {
+	t.Parallel()
+
+	tape := func(recordType AttestationType, expiry string) *bpu.Tape {
+		cells := []string{Prefix, string(recordType), "c4a3e6a9b3c8bc5a8b8a9d8e1b3c1a4f2f5e6d7c8b9a0f1e2d3c4b5a6978877", "1", expiry}
+		tape := &bpu.Tape{}
+		for index := range cells {
+			tape.Cell = append(tape.Cell, bpu.Cell{S: &cells[index]})
+		}
+		return tape
+	}
+
+	if b, err := NewFromTape(tape(ATTEST, "850000")); err != nil || b.Expiry != 850000 || b.Sequence != 1 {
+		t.Fatalf("%s Failed: expected expiry 850000 but got: %+v %v", t.Name(), b, err)
+	}
+	if b, err := NewFromTape(tape(REVOKE, "850000")); err != nil || b.Expiry != 0 {
+		t.Fatalf("%s Failed: expected no expiry for a revoke but got: %+v %v", t.Name(), b, err)
+	}
+	if _, err := NewFromTape(tape(ATTEST, "soon")); !errors.Is(err, ErrMalformedTape) {
+		t.Fatalf("%s Failed: expected ErrMalformedTape but got: %v", t.Name(), err)
+	}
+}
+
+// ExampleExpiry_Expired example using Expired()
+func ExampleExpiry_Expired() {
+	expiry, err := ExpiresAtHeight(850000)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("expired at 849999: %t, at 850000: %t", expiry.Expired(849999, time.Now()), expiry.Expired(850000, time.Now()))
+	// Output:expired at 849999: false, at 850000: true
+}
+
+// BenchmarkCreateAttestationWithExpiry benchmarks the method CreateAttestationWithExpiry()
+func BenchmarkCreateAttestationWithExpiry(b *testing.B) {
+	key, _ := testutil.SigningKey(b, testProofAttestorKey, 0)
+	for i := 0; i < b.N; i++ {
+		_, _ = CreateAttestationWithExpiry(idKey, key, "name", "John", "secret", 850000)
+	}
+}
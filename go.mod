@@ -7,9 +7,10 @@ require (
 	github.com/bitcoinschema/go-bob v0.5.2
 	github.com/bitcoinschema/go-bpu v0.2.2
 	github.com/bsv-blockchain/go-sdk v1.1.22
+	golang.org/x/crypto v0.35.0
 )
 
 require (
 	github.com/pkg/errors v0.9.1 // indirect
-	golang.org/x/crypto v0.35.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
 )
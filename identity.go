@@ -0,0 +1,202 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+// DefaultGapLimit is the number of consecutive unpublished signing addresses after which a scan stops
+const DefaultGapLimit = 20
+
+// AddressLookup finds the identity that published a signing address in an ID record,
+// an empty id key is returned for an unknown address (see indexer.Query)
+type AddressLookup interface {
+	IDKeyByAddress(address string) (string, error)
+}
+
+// CounterStore persists the counter of an identity, advancing it atomically (see keystore.Keystore)
+type CounterStore interface {
+	AdvanceCounter(idKey string) (previous, next uint32, err error)
+}
+
+// Identity is an identity key that owns its rotation counter, the counter is the path
+// (0/counter) of its current signing key. It is safe for concurrent use.
+type Identity struct {
+	counter     uint32
+	counters    CounterStore
+	idKey       string
+	mu          sync.Mutex
+	xPrivateKey string
+}
+
+// NewIdentity returns the identity of an xprv at a counter, the id key is derived from the
+// root address (see IdentityKeyFromXPrivateKey) if it is empty
+func NewIdentity(xPrivateKey, idKey string, counter uint32) (*Identity, error) {
+	if len(xPrivateKey) == 0 {
+		return nil, &MissingFieldError{Field: "xPrivateKey"}
+	}
+	hdKey, err := hd.NewKeyFromString(xPrivateKey)
+	if err != nil {
+		return nil, err
+	} else if !hdKey.IsPrivate() {
+		return nil, errors.New("xPrivateKey is not a private key")
+	}
+	if len(idKey) == 0 {
+		if idKey, err = IdentityKeyFromXPrivateKey(xPrivateKey); err != nil {
+			return nil, err
+		}
+	} else if err = ValidateIDKey(idKey); err != nil {
+		return nil, err
+	}
+	return &Identity{counter: counter, idKey: idKey, xPrivateKey: xPrivateKey}, nil
+}
+
+// IdentityKeyFromXPrivateKey returns the BAP identity key of an xprv: the identity key of the
+// address of its first signing key (0/0), which signs the first ID record
+func IdentityKeyFromXPrivateKey(xPrivateKey string) (string, error) {
+	rootKey, err := deriveSigningKey(xPrivateKey, 0)
+	if err != nil {
+		return "", err
+	}
+	return IdentityKeyFromAddress(rootKey.Address(&chaincfg.MainNet)), nil
+}
+
+// IDKey returns the identity key
+func (i *Identity) IDKey() string {
+	return i.idKey
+}
+
+// Counter returns the counter of the current signing key
+func (i *Identity) Counter() uint32 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.counter
+}
+
+// SetCounterStore persists the counter in a store, rotations then advance the stored counter
+func (i *Identity) SetCounterStore(counters CounterStore) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.counters = counters
+}
+
+// SigningKey returns the current signing key and its address
+func (i *Identity) SigningKey() (*ec.PrivateKey, string, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	hdKey, err := deriveSigningKey(i.xPrivateKey, i.counter)
+	if err != nil {
+		return nil, "", err
+	}
+	var key *ec.PrivateKey
+	if key, err = hdKey.ECPrivKey(); err != nil {
+		return nil, "", err
+	}
+	return key, hdKey.Address(&chaincfg.MainNet), nil
+}
+
+// CreateIdentity creates the ID record transaction of the current signing key (see CreateIdentity)
+func (i *Identity) CreateIdentity() (*transaction.Transaction, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return CreateIdentity(i.xPrivateKey, i.idKey, i.counter)
+}
+
+// Rotate creates a transaction rotating to the next signing key and advances the counter
+func (i *Identity) Rotate() (*transaction.Transaction, error) {
+	record, err := i.RotateRecord()
+	if err != nil {
+		return nil, err
+	}
+	return returnTx(record)
+}
+
+// RotateRecord creates the ID record rotating to the next signing key and advances the counter
+// (see CreateIdentityRotationRecord). With a counter store, the record is created before the
+// stored counter is advanced, and created again from the stored counter if another process
+// rotated meanwhile, so concurrent rotations never sign the same path.
+func (i *Identity) RotateRecord() (*Record, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	record, err := CreateIdentityRotationRecord(i.xPrivateKey, i.idKey, i.counter)
+	if err != nil {
+		return nil, err
+	} else if i.counters == nil {
+		i.counter++
+		return record, nil
+	}
+
+	var previous, next uint32
+	if previous, next, err = i.counters.AdvanceCounter(i.idKey); err != nil {
+		return nil, err
+	}
+	rotated := i.counter
+	i.counter = next
+	if previous != rotated {
+		return CreateIdentityRotationRecord(i.xPrivateKey, i.idKey, previous)
+	}
+	return record, nil
+}
+
+// RecoverCounter scans the signing addresses (0/0, 0/1, ...) for addresses published by ID
+// records of the identity until gapLimit consecutive addresses are unknown (DefaultGapLimit
+// if 0), and moves the counter to the last published address. The counter never moves back,
+// so a rotation that is not indexed yet is not signed again.
+func (i *Identity) RecoverCounter(lookup AddressLookup, gapLimit uint32) (uint32, error) {
+	if lookup == nil {
+		return 0, &MissingFieldError{Field: "lookup"}
+	} else if gapLimit == 0 {
+		gapLimit = DefaultGapLimit
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	_, last, found, err := scanAddresses(i.xPrivateKey, i.idKey, lookup, gapLimit)
+	if err != nil {
+		return 0, err
+	} else if !found {
+		return 0, fmt.Errorf("%w: no ID record of %s within %d addresses", ErrNoRecord, i.idKey, gapLimit)
+	}
+	i.counter = max(i.counter, last)
+	return i.counter, nil
+}
+
+// scanAddresses scans the signing addresses (0/0, 0/1, ...) of an xprv until gapLimit consecutive
+// addresses are unknown and returns the last address published by the identity, an empty id key
+// takes the identity of the first published address
+func scanAddresses(xPrivateKey, idKey string, lookup AddressLookup, gapLimit uint32) (string, uint32, bool, error) {
+	var (
+		found bool
+		last  uint32
+	)
+	for counter, misses := uint32(0), uint32(0); misses < gapLimit; counter++ {
+		hdKey, err := deriveSigningKey(xPrivateKey, counter)
+		if err != nil {
+			return "", 0, false, err
+		}
+		var publisher string
+		if publisher, err = lookup.IDKeyByAddress(hdKey.Address(&chaincfg.MainNet)); err != nil {
+			return "", 0, false, err
+		}
+		if len(publisher) > 0 && len(idKey) == 0 {
+			idKey = publisher
+		}
+		if len(publisher) > 0 && publisher == idKey {
+			found, last, misses = true, counter, 0
+		} else {
+			misses++
+		}
+		if counter == ^uint32(0) {
+			break
+		}
+	}
+	return idKey, last, found, nil
+}
@@ -0,0 +1,245 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+// testLookup is an AddressLookup of the addresses published by ID records
+type testLookup map[string]string
+
+// IDKeyByAddress returns the identity of an address
+func (l testLookup) IDKeyByAddress(address string) (string, error) {
+	return l[address], nil
+}
+
+// failingLookup is an AddressLookup that always fails
+type failingLookup struct{}
+
+// IDKeyByAddress fails
+func (failingLookup) IDKeyByAddress(string) (string, error) {
+	return "", errors.New("lookup failed")
+}
+
+// testCounters is a CounterStore in memory
+type testCounters struct {
+	counter uint32
+	err     error
+	mu      sync.Mutex
+}
+
+// AdvanceCounter advances the counter
+func (c *testCounters) AdvanceCounter(string) (previous, next uint32, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return 0, 0, c.err
+	}
+	c.counter++
+	return c.counter - 1, c.counter, nil
+}
+
+// testPublished returns a lookup with the addresses of the given counters of the test identity
+func testPublished(t testing.TB, counters ...uint32) testLookup {
+	lookup := testLookup{}
+	for _, counter := range counters {
+		hdKey, err := deriveSigningKey(privateKey, counter)
+		if err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+		lookup[hdKey.Address(&chaincfg.MainNet)] = idKey
+	}
+	return lookup
+}
+
+// TestNewIdentity will test the method NewIdentity()
+func TestNewIdentity(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			xPrivateKey   string
+			idKey         string
+			expectedIDKey string
+			expectedError bool
+		}{
+			{"id key", privateKey, idKey, idKey, false},
+			{"derived id key", privateKey, "", "oqWsnpcTgXuEGSHRUGJUfY2518b", false},
+			{"missing key", "", idKey, "", true},
+			{"invalid key", "invalid-key", idKey, "", true},
+			{"public key", "xpub661MyMwAqRbcEj3kQ1G5zqykXYJdjcpLGRXCaQjehABtJ7nDodrn5igY9vSJiMcLPtyMaN2XrHqtsLeJ9p9fP5RgBhpc3bwaJGJ3aPdYiM6", idKey, "", true},
+			{"invalid id key", privateKey, "invalid", "", true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if identity, err := NewIdentity(test.xPrivateKey, test.idKey, 0); err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		} else if err == nil && identity.IDKey() != test.expectedIDKey {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.name, test.expectedIDKey, identity.IDKey())
+		}
+	}
+}
+
+// TestIdentity_Rotate will test the method Rotate()
+func TestIdentity_Rotate(t *testing.T) {
+	t.Parallel()
+
+	identity, err := NewIdentity(privateKey, idKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	_, rootAddress, _ := identity.SigningKey()
+
+	for expected := uint32(1); expected <= 3; expected++ {
+		previous, _ := deriveSigningKey(privateKey, expected-1)
+		var records []*SignedBap
+		if tx, rotateErr := identity.Rotate(); rotateErr != nil {
+			t.Fatalf("error occurred: %s", rotateErr.Error())
+		} else if records, err = signedRecordsFromHex(tx.Hex()); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		} else if records[0].Signer.Address != previous.Address(&chaincfg.MainNet) {
+			t.Fatalf("%s Failed: expected the rotation to be signed by 0/%d", t.Name(), expected-1)
+		}
+		_, address, _ := identity.SigningKey()
+		if identity.Counter() != expected || records[0].Address != address || address == rootAddress {
+			t.Fatalf("%s Failed: expected counter %d at %s but got %d at %s", t.Name(), expected, records[0].Address, identity.Counter(), address)
+		}
+	}
+
+	// Concurrent rotations sign different paths
+	var wg sync.WaitGroup
+	for index := 0; index < 10; index++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = identity.RotateRecord()
+		}()
+	}
+	wg.Wait()
+	if identity.Counter() != 13 {
+		t.Fatalf("%s Failed: expected counter 13 but got %d", t.Name(), identity.Counter())
+	}
+}
+
+// TestIdentity_RotateWithStore will test rotations with a counter store
+func TestIdentity_RotateWithStore(t *testing.T) {
+	t.Parallel()
+
+	identity, err := NewIdentity(privateKey, idKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Another process already rotated to 0/5
+	counters := &testCounters{counter: 5}
+	identity.SetCounterStore(counters)
+	if _, err = identity.RotateRecord(); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if identity.Counter() != 6 || counters.counter != 6 {
+		t.Fatalf("%s Failed: expected counter 6 but got %d and %d", t.Name(), identity.Counter(), counters.counter)
+	}
+
+	counters.err = errors.New("store failed")
+	if _, err = identity.Rotate(); err == nil || identity.Counter() != 6 {
+		t.Fatalf("%s Failed: expected the store error and counter 6 but got %v and %d", t.Name(), err, identity.Counter())
+	}
+
+	// A record that cannot be created leaves the stored counter unchanged
+	counters.err = nil
+	if identity, err = NewIdentity(privateKey, idKey, math.MaxUint32); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	identity.SetCounterStore(counters)
+	if _, err = identity.RotateRecord(); err == nil || counters.counter != 6 || identity.Counter() != math.MaxUint32 {
+		t.Fatalf("%s Failed: expected an error and counters 6 and %d but got %v, %d and %d", t.Name(),
+			uint32(math.MaxUint32), err, counters.counter, identity.Counter())
+	}
+}
+
+// TestIdentity_RecoverCounter will test the method RecoverCounter()
+func TestIdentity_RecoverCounter(t *testing.T) {
+	t.Parallel()
+
+	other := testPublished(t, 2)
+	for address := range other {
+		other[address] = "oqWsnpcTgXuEGSHRUGJUfY2518b"
+	}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name            string
+			lookup          AddressLookup
+			counter         uint32
+			gapLimit        uint32
+			expectedCounter uint32
+			expectedError   error
+		}{
+			{"not rotated", testPublished(t, 0), 0, 0, 0, nil},
+			{"rotated", testPublished(t, 0, 1, 2, 3), 0, 0, 3, nil},
+			{"skipped paths", testPublished(t, 0, 4, 9), 0, 5, 9, nil},
+			{"beyond the gap limit", testPublished(t, 0, 4, 9), 0, 4, 4, nil},
+			{"default gap limit", testPublished(t, 0, 20), 0, 0, 20, nil},
+			{"ahead of the index", testPublished(t, 0, 1), 3, 0, 3, nil},
+			{"other identity", other, 0, 0, 0, ErrNoRecord},
+			{"not published", testLookup{}, 0, 3, 0, ErrNoRecord},
+			{"nil lookup", nil, 0, 0, 0, ErrMissingField},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		identity, err := NewIdentity(privateKey, idKey, test.counter)
+		if err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+		var counter uint32
+		if counter, err = identity.RecoverCounter(test.lookup, test.gapLimit); !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		} else if err == nil && (counter != test.expectedCounter || identity.Counter() != test.expectedCounter) {
+			t.Errorf("%s Failed: [%s] inputted and expected counter [%d] but got [%d]", t.Name(), test.name, test.expectedCounter, counter)
+		}
+	}
+
+	// Lookup errors are returned
+	identity, _ := NewIdentity(privateKey, idKey, 0)
+	if _, err := identity.RecoverCounter(failingLookup{}, 0); err == nil {
+		t.Fatalf("%s Failed: expected the lookup error", t.Name())
+	}
+}
+
+// ExampleIdentity_Rotate example using Rotate()
+func ExampleIdentity_Rotate() {
+	identity, err := NewIdentity(privateKey, idKey, 0)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	if _, err = identity.Rotate(); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	_, address, _ := identity.SigningKey()
+	fmt.Printf("counter %d: %s", identity.Counter(), address)
+	// Output:counter 1: 1G2AKzC4XD9iuQQJhS9WXzXXGXKdEN9DRV
+}
+
+// BenchmarkIdentity_RecoverCounter benchmarks the method RecoverCounter()
+func BenchmarkIdentity_RecoverCounter(b *testing.B) {
+	identity, _ := NewIdentity(privateKey, idKey, 0)
+	lookup := testPublished(b, 0, 1, 2)
+	for i := 0; i < b.N; i++ {
+		_, _ = identity.RecoverCounter(lookup, 0)
+	}
+}
@@ -0,0 +1,210 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Journal entry kinds
+const (
+	entryAlias       = "alias"
+	entryAttestation = "attestation"
+	entryData        = "data"
+	entryIdentity    = "identity"
+	entrySubject     = "subject"
+	entryTx          = "tx"
+)
+
+// journalEntry is a single line of the FileStore journal
+type journalEntry struct {
+	Alias       *Alias       `json:"alias,omitempty"`
+	Attestation *Attestation `json:"attestation,omitempty"`
+	Block       *Block       `json:"block,omitempty"`
+	Data        *Data        `json:"data,omitempty"`
+	Identity    *Identity    `json:"identity,omitempty"`
+	IDKey       string       `json:"id_key,omitempty"`
+	Kind        string       `json:"kind"`
+	TxID        string       `json:"txid,omitempty"`
+	URNHash     string       `json:"urn_hash,omitempty"`
+}
+
+// journalFile is the journal file of a FileStore (an *os.File)
+type journalFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// FileStore is an embedded on-disk Store, safe for concurrent use within one process
+//
+// Every change is appended to a JSON lines journal file, which is replayed into
+// memory when the store is opened. An entry that was only partly written (for example
+// after a crash) is dropped when the store is opened, corrupt complete lines are an error.
+// A failed write is truncated from the journal, if that fails as well the store refuses
+// further changes so the journal is never left with an entry in the middle of it.
+type FileStore struct {
+	*MemoryStore
+	failed error // Write that could not be rolled back
+	file   journalFile
+	mu     sync.Mutex
+}
+
+// NewFileStore opens (or creates) the journal file at path and loads its records
+func NewFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FileStore{MemoryStore: NewMemoryStore(), file: file}
+	if err = f.load(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// load will replay the journal into memory. A last line without a newline is a write
+// that was interrupted (the entry was never saved), it is truncated from the journal.
+func (f *FileStore) load() error {
+	reader := bufio.NewReader(f.file)
+	var offset int64
+	for line := 1; ; line++ {
+		raw, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(raw) > 0 {
+				return f.file.Truncate(offset)
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+		offset += int64(len(raw))
+		if err = f.replay(line, bytes.TrimSpace(raw)); err != nil {
+			return err
+		}
+	}
+}
+
+// replay will apply a single journal line to memory
+func (f *FileStore) replay(line int, raw []byte) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	var entry journalEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return fmt.Errorf("invalid journal entry on line %d: %w", line, err)
+	}
+	switch {
+	case entry.Kind == entryIdentity && entry.Identity != nil:
+		return f.MemoryStore.SaveIdentity(entry.Identity)
+	case entry.Kind == entryAttestation && entry.Attestation != nil:
+		return f.MemoryStore.SaveAttestation(entry.Attestation)
+	case entry.Kind == entryAlias && entry.Alias != nil:
+		return f.MemoryStore.SaveAlias(entry.Alias)
+	case entry.Kind == entryData && entry.Data != nil:
+		return f.MemoryStore.SaveData(entry.Data)
+	case entry.Kind == entrySubject && len(entry.URNHash) > 0:
+		return f.MemoryStore.SaveSubject(entry.URNHash, entry.IDKey)
+	case entry.Kind == entryTx && entry.Block != nil:
+		return f.MemoryStore.SaveTx(entry.TxID, *entry.Block)
+	}
+	return fmt.Errorf("unknown journal entry on line %d: %s", line, entry.Kind)
+}
+
+// append will write an entry to the journal and sync it to disk, or remove it again if that fails
+func (f *FileStore) append(entry *journalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failed != nil {
+		return fmt.Errorf("journal is unusable after a failed write: %w", f.failed)
+	}
+
+	// Roll a failed write back, so the next entry does not follow a partial line
+	var offset int64
+	if offset, err = f.file.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	if _, err = f.file.Write(append(line, '\n')); err == nil {
+		err = f.file.Sync()
+	}
+	if err != nil {
+		if truncateErr := f.file.Truncate(offset); truncateErr != nil {
+			f.failed = err
+		}
+		return err
+	}
+	return nil
+}
+
+// SaveIdentity creates or replaces an identity
+func (f *FileStore) SaveIdentity(identity *Identity) error {
+	if err := f.append(&journalEntry{Kind: entryIdentity, Identity: identity}); err != nil {
+		return err
+	}
+	return f.MemoryStore.SaveIdentity(identity)
+}
+
+// SaveAttestation adds an ATTEST or REVOKE record, unless it was already saved
+func (f *FileStore) SaveAttestation(attestation *Attestation) error {
+	if f.hasRecord(attestation.key()) {
+		return nil
+	} else if err := f.append(&journalEntry{Kind: entryAttestation, Attestation: attestation}); err != nil {
+		return err
+	}
+	return f.MemoryStore.SaveAttestation(attestation)
+}
+
+// SaveAlias adds an ALIAS record, unless it was already saved
+func (f *FileStore) SaveAlias(alias *Alias) error {
+	if f.hasRecord(alias.key()) {
+		return nil
+	} else if err := f.append(&journalEntry{Kind: entryAlias, Alias: alias}); err != nil {
+		return err
+	}
+	return f.MemoryStore.SaveAlias(alias)
+}
+
+// SaveData adds a DATA record, unless it was already saved
+func (f *FileStore) SaveData(data *Data) error {
+	if f.hasRecord(data.key()) {
+		return nil
+	} else if err := f.append(&journalEntry{Kind: entryData, Data: data}); err != nil {
+		return err
+	}
+	return f.MemoryStore.SaveData(data)
+}
+
+// SaveSubject links an attestation URN hash to the identity it belongs to
+func (f *FileStore) SaveSubject(urnHash, idKey string) error {
+	if err := f.append(&journalEntry{Kind: entrySubject, URNHash: urnHash, IDKey: idKey}); err != nil {
+		return err
+	}
+	return f.MemoryStore.SaveSubject(urnHash, idKey)
+}
+
+// SaveTx marks the transaction as indexed
+func (f *FileStore) SaveTx(txid string, block Block) error {
+	if err := f.append(&journalEntry{Kind: entryTx, TxID: txid, Block: &block}); err != nil {
+		return err
+	}
+	return f.MemoryStore.SaveTx(txid, block)
+}
+
+// Close closes the journal file
+func (f *FileStore) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,200 @@
+package indexer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestFileStore will test that the FileStore persists records across reopening
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "bap.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	memory := testFixture(t, NewMemoryStore()).Store()
+	testFixture(t, store)
+	if err = store.Close(); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Reopen and compare with an in-memory index of the same transactions
+	if store, err = NewFileStore(path); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	expectedIdentities, _ := memory.Identities()
+	identities, _ := store.Identities()
+	if !reflect.DeepEqual(expectedIdentities, identities) {
+		t.Fatalf("expected identities %+v but got %+v", expectedIdentities, identities)
+	}
+	expectedAttestations, _ := memory.Attestations()
+	attestations, _ := store.Attestations()
+	if !reflect.DeepEqual(expectedAttestations, attestations) {
+		t.Fatalf("expected attestations %+v but got %+v", expectedAttestations, attestations)
+	}
+	expectedAliases, _ := memory.Aliases(testIDKey)
+	aliases, _ := store.Aliases(testIDKey)
+	if !reflect.DeepEqual(expectedAliases, aliases) {
+		t.Fatalf("expected aliases %+v but got %+v", expectedAliases, aliases)
+	}
+	if attestations[0].URNHash == "" {
+		t.Fatalf("expected urn hash")
+	}
+	data, _ := store.Data(attestations[0].URNHash)
+	if len(data) != 1 {
+		t.Fatalf("expected 1 data record but got %d", len(data))
+	}
+
+	// Already indexed transactions are skipped after reopening
+	idx := testNew(t, store)
+	if indexed, _ := store.HasTx(attestations[0].TxID); !indexed {
+		t.Fatalf("expected tx to be indexed")
+	}
+	testFixture(t, store)
+	if attestations, _ = idx.Store().Attestations(); len(attestations) != 2 {
+		t.Fatalf("expected 2 attestations but got %d", len(attestations))
+	}
+}
+
+// TestFileStorePartialEntry will test opening a journal whose last entry was partly written
+func TestFileStorePartialEntry(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "bap.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	testFixture(t, store)
+	_ = store.Close()
+	var journal []byte
+	if journal, err = os.ReadFile(path); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if err = os.WriteFile(path, append(journal, `{"kind":"tx","txid":"ab`...), 0o600); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// The partial entry is dropped and new entries start on their own line
+	if store, err = NewFileStore(path); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if err = store.SaveSubject("hash", testIDKey); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	_ = store.Close()
+	if store, err = NewFileStore(path); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+	if idKey, _ := store.Subject("hash"); idKey != testIDKey {
+		t.Fatalf("expected the subject to be saved but got %q", idKey)
+	} else if attestations, _ := store.Attestations(); len(attestations) != 2 {
+		t.Fatalf("expected 2 attestations but got %d", len(attestations))
+	}
+}
+
+// failingJournal is a journal file whose writes stop halfway, and whose truncation can fail
+type failingJournal struct {
+	*os.File
+	truncateErr error
+}
+
+// Write writes half of the data and fails
+func (j *failingJournal) Write(data []byte) (int, error) {
+	n, _ := j.File.Write(data[:len(data)/2])
+	return n, errors.New("disk full")
+}
+
+// Truncate fails with truncateErr, if set
+func (j *failingJournal) Truncate(size int64) error {
+	if j.truncateErr != nil {
+		return j.truncateErr
+	}
+	return j.File.Truncate(size)
+}
+
+// TestFileStoreFailedWrite will test that a failed write never leaves a partial entry in the journal
+func TestFileStoreFailedWrite(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "bap.jsonl")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	file := store.file.(*os.File)
+
+	// The failed write is rolled back and the next entry is saved on its own line
+	store.file = &failingJournal{File: file}
+	if err = store.SaveSubject("failed", testIDKey); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+	store.file = file
+	if err = store.SaveSubject("saved", testIDKey); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// A write that cannot be rolled back stops further changes
+	store.file = &failingJournal{File: file, truncateErr: errors.New("read-only")}
+	if err = store.SaveSubject("failed", testIDKey); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+	store.file = file
+	if err = store.SaveSubject("refused", testIDKey); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+	_ = store.Close()
+
+	// The journal only ends with the partial entry, which is dropped when it is opened
+	if store, err = NewFileStore(path); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+	for urnHash, expected := range map[string]string{"saved": testIDKey, "failed": "", "refused": ""} {
+		if idKey, _ := store.Subject(urnHash); idKey != expected {
+			t.Fatalf("%s Failed: expected subject %s to be %q but got %q", t.Name(), urnHash, expected, idKey)
+		}
+	}
+}
+
+// TestFileStoreCorrupt will test opening an invalid journal
+func TestFileStoreCorrupt(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "bap.jsonl")
+	if err := os.WriteFile(path, []byte("{\"kind\":\"identity\"}\n"), 0o600); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, err := NewFileStore(path); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+	if err := os.WriteFile(path, []byte("not-json\n"), 0o600); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, err := NewFileStore(path); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+	if err := os.WriteFile(path, []byte("not-json\n{\"kind\":\"tx\"}\n"), 0o600); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, err := NewFileStore(path); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+	if _, err := NewFileStore(filepath.Join(path, "missing", "dir")); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+}
@@ -0,0 +1,328 @@
+// Package indexer turns a stream of transactions into indexed BAP identities,
+// attestations, aliases and data, persisted through a pluggable Store
+//
+// Rules applied to each signed BAP record (records without a valid AIP signature are rejected):
+//
+//   - ID: the first record of an id key creates the identity, and its signer becomes the
+//     root address. Id keys in the BAP spec format (base58 hash of an address) must be
+//     signed by that address. Other id keys (64 character hex) are not derived from an
+//     address, so they are only indexed once their root address is configured (see
+//     Indexer.SetRootAddress) and must be signed by it. Later records rotate the signing
+//     address and must be signed by the current signing address (see bap.ValidateIDSigner).
+//     An address already published by another identity is rejected.
+//   - ATTEST / REVOKE: stored with the signer, resolved to the attestor identity when the
+//     signer was a valid address of a known identity at that height.
+//   - ALIAS: must be signed by the address of the identity that was valid at that height.
+//   - DATA: stored with the signer identity, if known.
+//
+// Transactions should be added in chain order. A transaction is marked as indexed (and
+// skipped afterwards) once all of its records were accepted. A transaction with rejected
+// records, for example an ALIAS indexed before its ID, or one that failed to save, can be
+// added again: records that were already saved are not saved twice.
+package indexer
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bob"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+// ErrUnauthorized is returned when a record is not signed by an address allowed to publish it
+var ErrUnauthorized = bap.ErrUnauthorized
+
+// Indexer applies the BAP rules to transactions and persists the results to a Store
+type Indexer struct {
+	mu      sync.Mutex
+	network *chaincfg.Params
+	roots   map[string]string // id key -> configured root address
+	store   Store
+}
+
+// Result is the outcome of indexing a single transaction
+type Result struct {
+	Accepted  []*bap.SignedBap `json:"accepted,omitempty"`
+	Duplicate bool             `json:"duplicate,omitempty"` // True if the tx was already indexed
+	Rejected  []*Rejection     `json:"rejected,omitempty"`
+	TxID      string           `json:"txid"`
+}
+
+// Rejection is a BAP record that was not indexed, and why
+type Rejection struct {
+	Err    error          `json:"-"`
+	Reason string         `json:"reason"`
+	Record *bap.SignedBap `json:"record"`
+}
+
+// New returns an indexer using the given store and network (mainnet if nil)
+func New(store Store, network *chaincfg.Params) *Indexer {
+	if network == nil {
+		network = &chaincfg.MainNet
+	}
+	return &Indexer{network: network, roots: make(map[string]string), store: store}
+}
+
+// SetRootAddress configures the root address of an id key, the first ID record of the id
+// key must then be signed by that address. Required for id keys that are not derived from
+// their root address (64 character hex), which are rejected otherwise.
+func (i *Indexer) SetRootAddress(idKey, rootAddress string) error {
+	if err := bap.ValidateIDKey(idKey); err != nil {
+		return err
+	} else if err = bap.ValidateAddress(rootAddress, i.network); err != nil {
+		return err
+	} else if len(idKey) != 64 && bap.IdentityKeyFromAddress(rootAddress) != idKey {
+		return fmt.Errorf("id key %s is not derived from %s", idKey, rootAddress)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.roots[idKey] = rootAddress
+	return nil
+}
+
+// Store returns the store of the indexer
+func (i *Indexer) Store() Store {
+	return i.store
+}
+
+// AddTx indexes the BAP records of a transaction
+func (i *Indexer) AddTx(tx *transaction.Transaction, block Block) (*Result, error) {
+	if tx == nil {
+		return nil, &bap.MissingFieldError{Field: "transaction"}
+	}
+	bobTx, err := bob.NewFromTx(tx)
+	if err != nil {
+		return nil, err
+	}
+	return i.add(tx.TxID().String(), bobTx, block)
+}
+
+// AddRawTx indexes the BAP records of a raw transaction (hex)
+func (i *Indexer) AddRawTx(rawTx string, block Block) (*Result, error) {
+	tx, err := transaction.NewTransactionFromHex(rawTx)
+	if err != nil {
+		return nil, err
+	}
+	return i.AddTx(tx, block)
+}
+
+// AddBobTx indexes the BAP records of a BOB transaction, using its block info
+func (i *Indexer) AddBobTx(bobTx *bob.Tx) (*Result, error) {
+	if bobTx == nil {
+		return nil, &bap.MissingFieldError{Field: "transaction"}
+	} else if len(bobTx.Tx.Tx.H) == 0 {
+		return nil, &bap.MissingFieldError{Field: "txid"}
+	}
+	return i.add(bobTx.Tx.Tx.H, bobTx, Block{Height: bobTx.Blk.I, Time: bobTx.Blk.T})
+}
+
+// AddClaim links the attestation URN hash of an identity attribute to the identity, so
+// attestations of the attribute can be found by identity. Only the hash is stored.
+func (i *Indexer) AddClaim(idKey, attributeName, attributeValue, identityAttributeSecret string) (string, error) {
+	if len(idKey) == 0 {
+		return "", &bap.MissingFieldError{Field: "idKey"}
+	} else if len(attributeName) == 0 {
+		return "", &bap.MissingFieldError{Field: "attributeName"}
+	} else if len(identityAttributeSecret) == 0 {
+		return "", &bap.MissingFieldError{Field: "identityAttributeSecret"}
+	}
+	hash := bap.AttestationHash(idKey, attributeName, attributeValue, identityAttributeSecret)
+	urnHash := hex.EncodeToString(hash[:])
+	return urnHash, i.store.SaveSubject(urnHash, idKey)
+}
+
+// add will index every BAP record of the BOB transaction
+func (i *Indexer) add(txid string, bobTx *bob.Tx, block Block) (*Result, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	result := &Result{TxID: txid}
+	if indexed, err := i.store.HasTx(txid); err != nil {
+		return nil, err
+	} else if indexed {
+		result.Duplicate = true
+		return result, nil
+	}
+
+	for output := range bobTx.Out {
+		records, err := bap.NewSignedFromTapes(bobTx.Out[output].Tape)
+		if errors.Is(err, bap.ErrNoRecord) {
+			continue
+		} else if err != nil {
+			result.Rejected = append(result.Rejected, newRejection(nil, err))
+			continue
+		}
+		for _, record := range records {
+			if err = i.apply(txid, output, block, record); err != nil {
+				var storeErr *storeError
+				if errors.As(err, &storeErr) {
+					return nil, storeErr.err
+				}
+				result.Rejected = append(result.Rejected, newRejection(record, err))
+				continue
+			}
+			result.Accepted = append(result.Accepted, record)
+		}
+	}
+
+	// Only applied transactions are marked, a retry skips the records already saved
+	if len(result.Accepted) > 0 && len(result.Rejected) == 0 {
+		if err := i.store.SaveTx(txid, block); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// apply will check a single record against the BAP rules and store it
+func (i *Indexer) apply(txid string, output int, block Block, record *bap.SignedBap) error {
+	if record.Signer == nil {
+		return &bap.SignatureError{Err: errors.New("no AIP signature found")}
+	} else if !record.Signer.Valid {
+		return &bap.SignatureError{Address: record.Signer.Address, Err: errors.New(record.Signer.Error)}
+	} else if err := record.Validate(i.network); err != nil {
+		return err
+	}
+	signer := record.Signer.Address
+
+	switch record.Type {
+	case bap.ID:
+		return i.applyID(txid, block, record.Bap, signer)
+	case bap.ATTEST, bap.REVOKE:
+		attestation := &Attestation{
+			Address:  signer,
+			Block:    block,
+			Expiry:   record.Expiry,
+			Output:   output,
+			Sequence: record.Sequence,
+			TxID:     txid,
+			Type:     record.Type,
+			URNHash:  record.URNHash,
+		}
+		if identity, err := i.signerIdentity(signer, block.Height); err != nil {
+			return err
+		} else if identity != nil {
+			attestation.AttestorIDKey = identity.IDKey
+		}
+		return wrapStore(i.store.SaveAttestation(attestation))
+	case bap.ALIAS:
+		identity, err := i.identity(record.IDKey)
+		if err != nil {
+			return err
+		} else if identity == nil || identity.AddressAt(block.Height) != signer {
+			return fmt.Errorf("%w: alias for %s signed by %s", ErrUnauthorized, record.IDKey, signer)
+		}
+		return wrapStore(i.store.SaveAlias(&Alias{
+			Address: signer,
+			Block:   block,
+			IDKey:   record.IDKey,
+			Output:  output,
+			Profile: record.Profile,
+			TxID:    txid,
+		}))
+	case bap.DATA:
+		data := &Data{
+			Address: signer,
+			Block:   block,
+			Data:    record.Data,
+			Output:  output,
+			TxID:    txid,
+			URNHash: record.URNHash,
+		}
+		if identity, err := i.signerIdentity(signer, block.Height); err != nil {
+			return err
+		} else if identity != nil {
+			data.IDKey = identity.IDKey
+		}
+		return wrapStore(i.store.SaveData(data))
+	}
+	return &bap.RecordTypeError{Type: record.Type}
+}
+
+// applyID will create or rotate an identity
+func (i *Indexer) applyID(txid string, block Block, record *bap.Bap, signer string) error {
+	if err := bap.ValidateIDKey(record.IDKey); err != nil {
+		return err
+	}
+	identity, err := i.identity(record.IDKey)
+	if err != nil {
+		return err
+	}
+
+	address := &IdentityAddress{Address: record.Address, Block: block, TxID: txid}
+	if identity == nil {
+
+		// Spec id keys are derived from the root address, other id keys need a configured root
+		if err = bap.ValidateIDSigner(record.IDKey, signer, i.roots[record.IDKey], ""); err != nil {
+			return err
+		}
+		identity = &Identity{IDKey: record.IDKey, RootAddress: signer}
+	} else if identity.hasTx(txid) {
+		return nil // Already applied
+	} else if err = bap.ValidateIDSigner(record.IDKey, signer, identity.RootAddress, identity.CurrentAddress()); err != nil {
+		return err
+	} else if identity.CurrentAddress() == record.Address {
+		return nil
+	}
+
+	// A signing address belongs to the first identity that published it, in chain order
+	if owner, ownerErr := i.store.IdentityByAddress(record.Address); ownerErr == nil && owner.IDKey != record.IDKey {
+		return fmt.Errorf("%w: address %s already belongs to %s", ErrUnauthorized, record.Address, owner.IDKey)
+	} else if ownerErr != nil && !errors.Is(ownerErr, ErrNotFound) {
+		return wrapStore(ownerErr)
+	}
+
+	identity.Addresses = append(identity.Addresses, address)
+	return wrapStore(i.store.SaveIdentity(identity))
+}
+
+// identity will return the identity for the id key, or nil if it does not exist
+func (i *Indexer) identity(idKey string) (*Identity, error) {
+	identity, err := i.store.Identity(idKey)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, wrapStore(err)
+	}
+	return identity, nil
+}
+
+// signerIdentity will return the identity the address was valid for at the given height, or nil
+func (i *Indexer) signerIdentity(address string, height uint32) (*Identity, error) {
+	identity, err := i.store.IdentityByAddress(address)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, wrapStore(err)
+	} else if identity.AddressAt(height) != address {
+		return nil, nil
+	}
+	return identity, nil
+}
+
+// newRejection will create a rejection from an error
+func newRejection(record *bap.SignedBap, err error) *Rejection {
+	return &Rejection{Err: err, Reason: err.Error(), Record: record}
+}
+
+// storeError marks a failure of the store (as opposed to a rejected record)
+type storeError struct {
+	err error
+}
+
+// Error returns the error message
+func (e *storeError) Error() string {
+	return e.err.Error()
+}
+
+// wrapStore will mark a non-nil error as a store failure
+func wrapStore(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &storeError{err: err}
+}
@@ -0,0 +1,400 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	"github.com/bitcoinschema/go-bob"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Example keys
+const (
+	testIdentityKey = "xprv9s21ZrQH143K2beTKhLXFRWWFwH8jkwUssjk3SVTiApgmge7kNC3jhVc4NgHW8PhW2y7BCDErqnKpKuyQMjqSePPJooPJowAz5BVLThsv6c"
+	testAttestorKey = "xprv9s21ZrQH143K3PZSwbEeXEYq74EbnfMngzAiMCZcfjzyRpUvt2vQJnaHRTZjeuEmLXeN6BzYRoFsEckfobxE9XaRzeLGfQoxzPzTRyRb6oE"
+	testIDKey       = "8bafa4ca97d770276253585cb2a49da1775ec7aeed3178e346c8c1b55eaf5ca2"
+	testAttestorID  = "0d5d1e0b1bd2c0f9b8c7e6a5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5"
+)
+
+// testNew returns an indexer with the root addresses of the test identities configured
+func testNew(t testing.TB, store Store) *Indexer {
+	idx := New(store, nil)
+	for idKey, xPrivateKey := range map[string]string{
+		testAttestorID:  testAttestorKey,
+		testIDKey:       testIdentityKey,
+		testSecondIDKey: testSecondKey,
+	} {
+		if err := idx.SetRootAddress(idKey, testutil.RootAddress(t, xPrivateKey)); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+	return idx
+}
+
+// testIndex will add the transactions at consecutive heights starting at 100
+func testIndex(t testing.TB, idx *Indexer, txs ...*transaction.Transaction) []*Result {
+	results := make([]*Result, 0, len(txs))
+	for index, tx := range txs {
+		result, err := idx.AddTx(tx, Block{Height: uint32(100 + index), Time: uint32(1600000000 + index)})
+		if err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// testFixture indexes an identity with a rotation, an attestor, an attestation, a revocation and aliases
+func testFixture(t testing.TB, store Store) *Indexer {
+	idx := testNew(t, store)
+
+	identityTx, err := bap.CreateIdentity(testIdentityKey, testIDKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var attestorTx *transaction.Transaction
+	if attestorTx, err = bap.CreateIdentity(testAttestorKey, testAttestorID, 0); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	attestorKey, _ := testutil.SigningKey(t, testAttestorKey, 0)
+	var attestTx *transaction.Transaction
+	if attestTx, err = bap.CreateAttestation(testIDKey, attestorKey, "name", "John", "secret"); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	hash := bap.AttestationHash(testIDKey, "name", "John", "secret")
+	identityKey, _ := testutil.SigningKey(t, testIdentityKey, 0)
+	rotatedKey, _ := testutil.SigningKey(t, testIdentityKey, 1)
+
+	testIndex(t, idx,
+		identityTx, // 100
+		attestorTx, // 101
+		attestTx,   // 102
+		testutil.SignedTx(t, identityKey, []byte(bap.ALIAS), []byte(testIDKey), []byte(`{"name":"John"}`)),      // 103
+		testutil.RotationTx(t, testIdentityKey, testIDKey, 1),                                                   // 104
+		testutil.SignedTx(t, rotatedKey, []byte(bap.ALIAS), []byte(testIDKey), []byte(`{"name":"John Adams"}`)), // 105
+		testutil.SignedTx(t, attestorKey, []byte(bap.REVOKE), hash[:], []byte("1")),                             // 106
+		testutil.SignedTx(t, attestorKey, []byte(bap.DATA), hash[:], []byte("encrypted-data")),                  // 107
+	)
+	return idx
+}
+
+// TestIndexer will test indexing identities, attestations, aliases and data
+func TestIndexer(t *testing.T) {
+	t.Parallel()
+
+	idx := testFixture(t, NewMemoryStore())
+	store := idx.Store()
+
+	// Identity with rotation
+	identity, err := store.Identity(testIDKey)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	_, rootAddress := testutil.SigningKey(t, testIdentityKey, 0)
+	_, rotatedAddress := testutil.SigningKey(t, testIdentityKey, 1)
+	if identity.RootAddress != rootAddress {
+		t.Fatalf("expected root address [%s] but got [%s]", rootAddress, identity.RootAddress)
+	} else if len(identity.Addresses) != 2 || identity.CurrentAddress() != rotatedAddress {
+		t.Fatalf("expected rotation to [%s] but got %+v", rotatedAddress, identity.Addresses)
+	} else if identity.AddressAt(103) != rootAddress || identity.AddressAt(104) != rotatedAddress || identity.AddressAt(0) != rotatedAddress {
+		t.Fatalf("unexpected address history")
+	}
+	if identity, err = store.IdentityByAddress(rotatedAddress); err != nil || identity.IDKey != testIDKey {
+		t.Fatalf("expected identity by address but got: %v", err)
+	}
+
+	// Attestation and revocation by the attestor identity
+	var attestations []*Attestation
+	if attestations, err = store.Attestations(); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(attestations) != 2 {
+		t.Fatalf("expected 2 attestations but got %d", len(attestations))
+	}
+	hash := bap.AttestationHash(testIDKey, "name", "John", "secret")
+	for index, expectedType := range []bap.AttestationType{bap.ATTEST, bap.REVOKE} {
+		if attestations[index].Type != expectedType || attestations[index].AttestorIDKey != testAttestorID ||
+			attestations[index].URNHash != fmt.Sprintf("%x", hash) {
+			t.Fatalf("unexpected attestation: %+v", attestations[index])
+		}
+	}
+
+	// Aliases signed by the then-valid address
+	var aliases []*Alias
+	if aliases, err = store.Aliases(testIDKey); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(aliases) != 2 || aliases[1].Profile != `{"name":"John Adams"}` {
+		t.Fatalf("unexpected aliases: %+v", aliases)
+	}
+
+	// Data
+	var data []*Data
+	if data, err = store.Data(fmt.Sprintf("%x", hash)); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(data) != 1 || data[0].Data != "encrypted-data" || data[0].IDKey != testAttestorID {
+		t.Fatalf("unexpected data: %+v", data)
+	}
+}
+
+// TestIndexerRejections will test records that break the BAP rules
+func TestIndexerRejections(t *testing.T) {
+	t.Parallel()
+
+	idx := testFixture(t, NewMemoryStore())
+	identityKey, _ := testutil.SigningKey(t, testIdentityKey, 0)
+	attestorKey, _ := testutil.SigningKey(t, testAttestorKey, 0)
+	_, attestorAddress := testutil.SigningKey(t, testAttestorKey, 5)
+	squatterKey, squatterAddress := testutil.SigningKey(t, testAttestorKey, 7)
+	_, victimAddress := testutil.SigningKey(t, testIdentityKey, 1)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			tx            *transaction.Transaction
+			expectedError error
+		}{
+			{"hijack rotation", testutil.SignedTx(t, attestorKey, []byte(bap.ID), []byte(testIDKey), []byte(attestorAddress)), ErrUnauthorized},
+			{"rotation signed by the replaced root", testutil.SignedTx(t, identityKey, []byte(bap.ID), []byte(testIDKey), []byte(attestorAddress)), ErrUnauthorized},
+			{"squatted address", testutil.SignedTx(t, squatterKey, []byte(bap.ID), []byte(bap.IdentityKeyFromAddress(squatterAddress)), []byte(victimAddress)), ErrUnauthorized},
+			{"rotation to another identity's address", testutil.SignedTx(t, attestorKey, []byte(bap.ID), []byte(testAttestorID), []byte(victimAddress)), ErrUnauthorized},
+			{"foreign alias", testutil.SignedTx(t, attestorKey, []byte(bap.ALIAS), []byte(testIDKey), []byte(`{"name":"Fake"}`)), ErrUnauthorized},
+			{"alias of old address", testutil.SignedTx(t, identityKey, []byte(bap.ALIAS), []byte(testIDKey), []byte(`{}`)), ErrUnauthorized},
+			{"unknown identity alias", testutil.SignedTx(t, attestorKey, []byte(bap.ALIAS), []byte("unknown"), []byte(`{}`)), bap.ErrMalformedTape},
+			{"unconfigured id key", testutil.SignedTx(t, attestorKey, []byte(bap.ID), []byte(testUnknownIDKey), []byte(attestorAddress)), ErrUnauthorized},
+			{"id key of another root", testutil.SignedTx(t, attestorKey, []byte(bap.ID), []byte(testSecondIDKey), []byte(attestorAddress)), ErrUnauthorized},
+			{"spec id key not derived", testutil.SignedTx(t, attestorKey, []byte(bap.ID), []byte(bap.IdentityKeyFromAddress("1A9VQqdNJrvVF73nf879n2fES6cd5nWNid")), []byte(attestorAddress)), ErrUnauthorized},
+			{"unknown type", testutil.SignedTx(t, attestorKey, []byte("UNKNOWN"), []byte("a"), []byte("b")), bap.ErrInvalidRecordType},
+		}
+	)
+
+	for _, test := range tests {
+		result, err := idx.AddTx(test.tx, Block{Height: 200})
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if len(result.Accepted) != 0 || len(result.Rejected) != 1 {
+			t.Errorf("%s Failed: [%s] inputted and expected 1 rejection but got %+v", t.Name(), test.name, result)
+		} else if !errors.Is(result.Rejected[0].Err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.name, test.expectedError, result.Rejected[0].Err)
+		}
+	}
+
+	// Invalid signature
+	tx := testutil.SignedTx(t, attestorKey, []byte(bap.ATTEST), []byte("cf39fc55da24dc23eff1809e6e6cf32a0fe6aecc81296543e9ac84b8c501bac5"), []byte("0"))
+	(*tx.Outputs[0].LockingScript)[40] ^= 0x01
+	result, err := idx.AddTx(tx, Block{Height: 200})
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(result.Rejected) != 1 || !errors.Is(result.Rejected[0].Err, bap.ErrInvalidSignature) {
+		t.Fatalf("expected invalid signature but got %+v", result)
+	}
+}
+
+// TestIndexerSpecIdentity will test an identity whose key is derived from its root address
+func TestIndexerSpecIdentity(t *testing.T) {
+	t.Parallel()
+
+	idx := New(NewMemoryStore(), nil)
+	rootKey, rootAddress := testutil.SigningKey(t, testAttestorKey, 0)
+	_, address := testutil.SigningKey(t, testAttestorKey, 1)
+	idKey := bap.IdentityKeyFromAddress(rootAddress)
+
+	results := testIndex(t, idx, testutil.SignedTx(t, rootKey, []byte(bap.ID), []byte(idKey), []byte(address)))
+	if len(results[0].Accepted) != 1 {
+		t.Fatalf("expected record to be accepted but got %+v", results[0].Rejected[0].Reason)
+	}
+	identity, err := idx.Store().Identity(idKey)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if identity.RootAddress != rootAddress || identity.CurrentAddress() != address {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+}
+
+// TestIndexer_SetRootAddress will test the method SetRootAddress()
+func TestIndexer_SetRootAddress(t *testing.T) {
+	t.Parallel()
+
+	_, rootAddress := testutil.SigningKey(t, testAttestorKey, 0)
+	_, otherAddress := testutil.SigningKey(t, testAttestorKey, 1)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			idKey         string
+			rootAddress   string
+			expectedError bool
+		}{
+			{"hex id key", testAttestorID, rootAddress, false},
+			{"spec id key", bap.IdentityKeyFromAddress(rootAddress), rootAddress, false},
+			{"spec id key of another address", bap.IdentityKeyFromAddress(rootAddress), otherAddress, true},
+			{"invalid id key", "not-an-id-key", rootAddress, true},
+			{"missing id key", "", rootAddress, true},
+			{"invalid address", testAttestorID, "1InvalidAddress", true},
+			{"missing address", testAttestorID, "", true},
+		}
+	)
+
+	for _, test := range tests {
+		if err := New(NewMemoryStore(), nil).SetRootAddress(test.idKey, test.rootAddress); err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		}
+	}
+
+	// Malformed id keys are rejected even if the record was not validated
+	idx := New(NewMemoryStore(), nil)
+	if err := idx.applyID("txid", Block{}, &bap.Bap{Type: bap.ID, IDKey: "not-an-id-key", Address: otherAddress}, rootAddress); err == nil {
+		t.Fatalf("%s Failed: expected the malformed id key to be rejected", t.Name())
+	}
+}
+
+// failingStore is a store that fails to save attestations after a number of saves
+type failingStore struct {
+	*MemoryStore
+	saves int
+}
+
+// SaveAttestation fails once the saves are used up
+func (f *failingStore) SaveAttestation(attestation *Attestation) error {
+	if f.saves == 0 {
+		return errors.New("store unavailable")
+	}
+	f.saves--
+	return f.MemoryStore.SaveAttestation(attestation)
+}
+
+// TestIndexerRetry will test adding transactions again after rejections and failed saves
+func TestIndexerRetry(t *testing.T) {
+	t.Parallel()
+
+	store := &failingStore{MemoryStore: NewMemoryStore(), saves: 1}
+	idx := testNew(t, store)
+	identityTx, err := bap.CreateIdentity(testIdentityKey, testIDKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	identityKey, _ := testutil.SigningKey(t, testIdentityKey, 0)
+	aliasTx := testutil.SignedTx(t, identityKey, []byte(bap.ALIAS), []byte(testIDKey), []byte(`{"name":"John"}`))
+
+	// An ALIAS before its ID is rejected, and accepted once the ID is indexed
+	var result *Result
+	if result, err = idx.AddTx(aliasTx, Block{Height: 101}); err != nil || len(result.Rejected) != 1 {
+		t.Fatalf("expected the alias to be rejected: %v", err)
+	}
+	testIndex(t, idx, identityTx)
+	if result, err = idx.AddTx(aliasTx, Block{Height: 101}); err != nil || result.Duplicate || len(result.Accepted) != 1 {
+		t.Fatalf("expected the alias to be accepted but got %+v (%v)", result, err)
+	} else if result, err = idx.AddTx(aliasTx, Block{Height: 101}); err != nil || !result.Duplicate {
+		t.Fatalf("expected duplicate: %v", err)
+	}
+
+	// A batch that failed to save is not duplicated by the retry
+	requests := []bap.AttestationRequest{
+		{IDKey: testIDKey, AttributeName: "name", AttributeValue: "John", IdentityAttributeSecret: "secret"},
+		{IDKey: testIDKey, AttributeName: "email", AttributeValue: "john@example.com", IdentityAttributeSecret: "secret"},
+	}
+	var batchTx *transaction.Transaction
+	if batchTx, err = bap.CreateAttestations(identityKey, requests); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, err = idx.AddTx(batchTx, Block{Height: 102}); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+	store.saves = 2
+	if result, err = idx.AddTx(batchTx, Block{Height: 102}); err != nil || len(result.Accepted) != 2 {
+		t.Fatalf("expected the batch to be accepted but got %+v (%v)", result, err)
+	}
+	if attestations, _ := store.Attestations(); len(attestations) != 2 {
+		t.Fatalf("%s Failed: expected 2 attestations but got %d", t.Name(), len(attestations))
+	}
+
+	// Applying a rotation again does not rotate back
+	rotationTx := testutil.RotationTx(t, testIdentityKey, testIDKey, 1)
+	testIndex(t, idx, rotationTx, testutil.RotationTx(t, testIdentityKey, testIDKey, 2))
+	_, rootAddress := testutil.SigningKey(t, testIdentityKey, 0)
+	_, address := testutil.SigningKey(t, testIdentityKey, 1)
+	if err = idx.applyID(rotationTx.TxID().String(), Block{Height: 100}, &bap.Bap{Type: bap.ID, IDKey: testIDKey, Address: address}, rootAddress); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	_, currentAddress := testutil.SigningKey(t, testIdentityKey, 2)
+	if identity, _ := store.Identity(testIDKey); identity.CurrentAddress() != currentAddress || len(identity.Addresses) != 3 {
+		t.Fatalf("%s Failed: expected the current address %s but got %+v", t.Name(), currentAddress, identity)
+	}
+}
+
+// TestIndexerDuplicates will test that transactions are only indexed once
+func TestIndexerDuplicates(t *testing.T) {
+	t.Parallel()
+
+	idx := testNew(t, NewMemoryStore())
+	tx, err := bap.CreateIdentity(testIdentityKey, testIDKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Raw, then again as a transaction and BOB
+	var result *Result
+	if result, err = idx.AddRawTx(tx.Hex(), Block{Height: 100}); err != nil || len(result.Accepted) != 1 {
+		t.Fatalf("expected record to be accepted: %v", err)
+	}
+	if result, err = idx.AddTx(tx, Block{Height: 100}); err != nil || !result.Duplicate {
+		t.Fatalf("expected duplicate: %v", err)
+	}
+	var bobTx *bob.Tx
+	if bobTx, err = bob.NewFromTx(tx); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	bobTx.Tx.Tx.H = tx.TxID().String()
+	if result, err = idx.AddBobTx(bobTx); err != nil || !result.Duplicate {
+		t.Fatalf("expected duplicate: %v", err)
+	}
+
+	// Bad input
+	if _, err = idx.AddTx(nil, Block{}); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	}
+	if _, err = idx.AddBobTx(&bob.Tx{}); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	}
+	if _, err = idx.AddRawTx("not-hex", Block{}); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+}
+
+// ExampleIndexer_AddTx example using AddTx()
+func ExampleIndexer_AddTx() {
+	idx := New(NewMemoryStore(), nil)
+	if err := idx.SetRootAddress(testIDKey, "1A9VQqdNJrvVF73nf879n2fES6cd5nWNid"); err != nil {
+		fmt.Printf("invalid root address: %s", err.Error())
+		return
+	}
+	tx, err := bap.CreateIdentity(testIdentityKey, testIDKey, 0)
+	if err != nil {
+		fmt.Printf("failed to create identity: %s", err.Error())
+		return
+	}
+	if _, err = idx.AddTx(tx, Block{Height: 100}); err != nil {
+		fmt.Printf("failed to index: %s", err.Error())
+		return
+	}
+	var identity *Identity
+	if identity, err = idx.Store().Identity(testIDKey); err != nil {
+		fmt.Printf("identity not found: %s", err.Error())
+		return
+	}
+	fmt.Printf("current address: %s", identity.CurrentAddress())
+	// Output:current address: 1A9VQqdNJrvVF73nf879n2fES6cd5nWNid
+}
+
+// BenchmarkIndexer_AddTx benchmarks the method AddTx()
+func BenchmarkIndexer_AddTx(b *testing.B) {
+	tx, _ := bap.CreateIdentity(testIdentityKey, testIDKey, 0)
+	for i := 0; i < b.N; i++ {
+		_, _ = testNew(b, NewMemoryStore()).AddTx(tx, Block{Height: 100})
+	}
+}
@@ -0,0 +1,205 @@
+package indexer
+
+import "sync"
+
+// MemoryStore is an in-memory Store, safe for concurrent use
+type MemoryStore struct {
+	addresses    map[string]string // address -> id key
+	aliases      map[string][]*Alias
+	attestations []*Attestation
+	data         map[string][]*Data
+	identities   map[string]*Identity
+	identityKeys []string // id keys in the order they were first saved
+	mu           sync.RWMutex
+	records      map[string]struct{} // keys of the saved attestations, aliases and data
+	subjects     map[string]string   // urn hash -> id key
+	txs          map[string]Block
+}
+
+// NewMemoryStore returns a new, empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		addresses:  make(map[string]string),
+		aliases:    make(map[string][]*Alias),
+		data:       make(map[string][]*Data),
+		identities: make(map[string]*Identity),
+		records:    make(map[string]struct{}),
+		subjects:   make(map[string]string),
+		txs:        make(map[string]Block),
+	}
+}
+
+// Identity returns the identity with the given id key
+func (m *MemoryStore) Identity(idKey string) (*Identity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if identity, ok := m.identities[idKey]; ok {
+		return identity.clone(), nil
+	}
+	return nil, ErrNotFound
+}
+
+// IdentityByAddress returns the identity that has (or had) the given signing address
+func (m *MemoryStore) IdentityByAddress(address string) (*Identity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if idKey, ok := m.addresses[address]; ok {
+		return m.identities[idKey].clone(), nil
+	}
+	return nil, ErrNotFound
+}
+
+// Identities returns all identities, in the order they were first saved
+func (m *MemoryStore) Identities() ([]*Identity, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	identities := make([]*Identity, 0, len(m.identityKeys))
+	for _, idKey := range m.identityKeys {
+		identities = append(identities, m.identities[idKey].clone())
+	}
+	return identities, nil
+}
+
+// SaveIdentity creates or replaces an identity
+func (m *MemoryStore) SaveIdentity(identity *Identity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.identities[identity.IDKey]; !ok {
+		m.identityKeys = append(m.identityKeys, identity.IDKey)
+	}
+	m.identities[identity.IDKey] = identity.clone()
+	for _, a := range identity.Addresses {
+		if _, ok := m.addresses[a.Address]; !ok {
+			m.addresses[a.Address] = identity.IDKey
+		}
+	}
+	return nil
+}
+
+// Attestations returns all ATTEST and REVOKE records, in the order they were saved
+func (m *MemoryStore) Attestations() ([]*Attestation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	attestations := make([]*Attestation, len(m.attestations))
+	for index, a := range m.attestations {
+		attestation := *a
+		attestations[index] = &attestation
+	}
+	return attestations, nil
+}
+
+// SaveAttestation adds an ATTEST or REVOKE record, unless it was already saved
+func (m *MemoryStore) SaveAttestation(attestation *Attestation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.addRecord(attestation.key()) {
+		return nil
+	}
+	a := *attestation
+	m.attestations = append(m.attestations, &a)
+	return nil
+}
+
+// Aliases returns the ALIAS records of an identity, in the order they were saved
+func (m *MemoryStore) Aliases(idKey string) ([]*Alias, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	aliases := make([]*Alias, len(m.aliases[idKey]))
+	for index, a := range m.aliases[idKey] {
+		alias := *a
+		aliases[index] = &alias
+	}
+	return aliases, nil
+}
+
+// SaveAlias adds an ALIAS record, unless it was already saved
+func (m *MemoryStore) SaveAlias(alias *Alias) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.addRecord(alias.key()) {
+		return nil
+	}
+	a := *alias
+	m.aliases[alias.IDKey] = append(m.aliases[alias.IDKey], &a)
+	return nil
+}
+
+// Data returns the DATA records for a URN hash, in the order they were saved
+func (m *MemoryStore) Data(urnHash string) ([]*Data, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	records := make([]*Data, len(m.data[urnHash]))
+	for index, d := range m.data[urnHash] {
+		record := *d
+		records[index] = &record
+	}
+	return records, nil
+}
+
+// SaveData adds a DATA record, unless it was already saved
+func (m *MemoryStore) SaveData(data *Data) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.addRecord(data.key()) {
+		return nil
+	}
+	d := *data
+	m.data[data.URNHash] = append(m.data[data.URNHash], &d)
+	return nil
+}
+
+// Subject returns the id key of the identity an attestation URN hash belongs to
+func (m *MemoryStore) Subject(urnHash string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if idKey, ok := m.subjects[urnHash]; ok {
+		return idKey, nil
+	}
+	return "", ErrNotFound
+}
+
+// SaveSubject links an attestation URN hash to the identity it belongs to
+func (m *MemoryStore) SaveSubject(urnHash, idKey string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects[urnHash] = idKey
+	return nil
+}
+
+// HasTx returns true if the transaction has already been indexed
+func (m *MemoryStore) HasTx(txid string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.txs[txid]
+	return ok, nil
+}
+
+// SaveTx marks the transaction as indexed
+func (m *MemoryStore) SaveTx(txid string, block Block) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.txs[txid] = block
+	return nil
+}
+
+// hasRecord returns true if the attestation, alias or data record with the key was saved
+func (m *MemoryStore) hasRecord(key string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.records[key]
+	return ok
+}
+
+// addRecord will add the key of a record, returning false if it was already saved
+func (m *MemoryStore) addRecord(key string) bool {
+	if _, ok := m.records[key]; ok {
+		return false
+	}
+	m.records[key] = struct{}{}
+	return true
+}
+
+// Close does nothing for the in-memory store
+func (m *MemoryStore) Close() error {
+	return nil
+}
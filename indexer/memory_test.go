@@ -0,0 +1,78 @@
+package indexer
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMemoryStore will test the MemoryStore
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	store := NewMemoryStore()
+	identity := &Identity{
+		IDKey:       testIDKey,
+		RootAddress: "root",
+		Addresses:   []*IdentityAddress{{Address: "root", Block: Block{Height: 1}}},
+	}
+	if err := store.SaveIdentity(identity); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Returned records are copies
+	identity.Addresses[0].Address = "changed"
+	found, err := store.Identity(testIDKey)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if found.CurrentAddress() != "root" {
+		t.Fatalf("store should keep its own copy")
+	}
+	found.Addresses = append(found.Addresses, &IdentityAddress{Address: "next"})
+	if found, _ = store.Identity(testIDKey); len(found.Addresses) != 1 {
+		t.Fatalf("store should return copies")
+	}
+
+	// Not found
+	if _, err = store.Identity("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got: %v", err)
+	}
+	if _, err = store.IdentityByAddress("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got: %v", err)
+	}
+
+	// Saving a record again does nothing
+	attestation := &Attestation{Address: "root", Output: 1, TxID: "txid", Type: "ATTEST", URNHash: "hash"}
+	for range 2 {
+		if err = store.SaveAttestation(attestation); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		} else if err = store.SaveAlias(&Alias{Address: "root", IDKey: testIDKey, TxID: "txid"}); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		} else if err = store.SaveData(&Data{Address: "root", TxID: "txid", URNHash: "hash"}); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+	attestations, _ := store.Attestations()
+	aliases, _ := store.Aliases(testIDKey)
+	data, _ := store.Data("hash")
+	if len(attestations) != 1 || len(aliases) != 1 || len(data) != 1 {
+		t.Fatalf("expected 1 record of each but got %d, %d and %d", len(attestations), len(aliases), len(data))
+	}
+	attestation.Output = 2
+	if err = store.SaveAttestation(attestation); err != nil || len(store.attestations) != 2 {
+		t.Fatalf("expected a record of another output to be saved")
+	}
+
+	// Empty lists
+	if aliases, _ := store.Aliases("missing"); len(aliases) != 0 {
+		t.Fatalf("expected no aliases")
+	}
+	if data, _ := store.Data("missing"); len(data) != 0 {
+		t.Fatalf("expected no data")
+	}
+	if indexed, _ := store.HasTx("missing"); indexed {
+		t.Fatalf("expected tx to not be indexed")
+	}
+	if err = store.Close(); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+}
@@ -0,0 +1,454 @@
+package indexer
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+)
+
+// Order is the block height ordering of query results
+type Order int
+
+// Orderings by effective height, where unconfirmed records count as the highest height
+const (
+	Ascending  Order = iota // Oldest first, unconfirmed records last
+	Descending              // Newest first, unconfirmed records first
+)
+
+// Page selects a window of query results (a Limit of 0 returns all results)
+type Page struct {
+	Limit  int   `json:"limit,omitempty"`
+	Offset int   `json:"offset,omitempty"`
+	Order  Order `json:"order,omitempty"`
+}
+
+// AttestationFilter narrows down attestation queries (empty fields match everything)
+type AttestationFilter struct {
+	Address       string              `json:"address,omitempty"`         // Signing address
+	AttestorIDKey string              `json:"attestor_id_key,omitempty"` // Identity of the signing address
+	FromHeight    uint32              `json:"from_height,omitempty"`     // Minimum block height (inclusive)
+	Subject       string              `json:"subject,omitempty"`         // Identity the attestation belongs to
+	ToHeight      uint32              `json:"to_height,omitempty"`       // Maximum block height (inclusive)
+	Type          bap.AttestationType `json:"type,omitempty"`            // ATTEST or REVOKE
+	URNHashes     []string            `json:"urn_hashes,omitempty"`      // Any of the URN hashes
+}
+
+// Record is a BAP record with the transaction and block it was published in
+type Record struct {
+	*bap.Bap
+	Block  Block  `json:"block"`
+	Output int    `json:"output"`
+	TxID   string `json:"txid"`
+}
+
+// AttestationRecord is an ATTEST or REVOKE record with its attestor and subject, if known
+type AttestationRecord struct {
+	Record
+	AttestorIDKey string `json:"attestor_id_key,omitempty"`
+	Subject       string `json:"subject,omitempty"`
+}
+
+// IdentityRecord is an identity with its current address and profile
+type IdentityRecord struct {
+	*Identity
+	CurrentAddress string  `json:"current_address"`
+	FirstSeen      Block   `json:"first_seen"`
+	Profile        *Record `json:"profile,omitempty"`
+}
+
+// ProfileVersion is a version of the profile of an identity, published by an ALIAS record
+type ProfileVersion struct {
+	Record
+	Authorized bool `json:"authorized"` // Signed by the signing address of the identity valid at its block
+	Version    int  `json:"version"`    // Position in the history, from 1 in block order
+}
+
+// Query answers questions about the records of a Store
+type Query struct {
+	store Store
+}
+
+// NewQuery returns a query layer over the given store
+func NewQuery(store Store) *Query {
+	return &Query{store: store}
+}
+
+// Attestations returns the attestations matching the filter, and the total number of matches
+func (q *Query) Attestations(filter AttestationFilter, page Page) ([]*AttestationRecord, int, error) {
+	attestations, err := q.store.Attestations()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	urnHashes := make(map[string]bool, len(filter.URNHashes))
+	for _, urnHash := range filter.URNHashes {
+		urnHashes[urnHash] = true
+	}
+
+	var records []*AttestationRecord
+	for _, a := range attestations {
+		if (len(urnHashes) > 0 && !urnHashes[a.URNHash]) ||
+			(len(filter.Address) > 0 && a.Address != filter.Address) ||
+			(len(filter.AttestorIDKey) > 0 && a.AttestorIDKey != filter.AttestorIDKey) ||
+			(len(filter.Type) > 0 && a.Type != filter.Type) ||
+			!inHeightRange(a.Block.Height, filter.FromHeight, filter.ToHeight) {
+			continue
+		}
+		var record *AttestationRecord
+		if record, err = q.attestationRecord(a); err != nil {
+			return nil, 0, err
+		} else if len(filter.Subject) > 0 && record.Subject != filter.Subject {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	sortByHeight(records, page.Order, func(r *AttestationRecord) uint32 { return r.Block.Height })
+	return paginate(records, page), len(records), nil
+}
+
+// AttestationsForIdentity returns the attestations of an identity's attributes
+// (the attributes must have been linked with Indexer.AddClaim)
+func (q *Query) AttestationsForIdentity(idKey string, page Page) ([]*AttestationRecord, int, error) {
+	if len(idKey) == 0 {
+		return nil, 0, &bap.MissingFieldError{Field: "idKey"}
+	}
+	return q.Attestations(AttestationFilter{Subject: idKey}, page)
+}
+
+// IdentitiesAttestedBy returns the identities with an attribute attested by the attestor,
+// ordered by the height of their first attestation
+func (q *Query) IdentitiesAttestedBy(attestorIDKey string, page Page) ([]*IdentityRecord, int, error) {
+	if len(attestorIDKey) == 0 {
+		return nil, 0, &bap.MissingFieldError{Field: "attestorIDKey"}
+	}
+	attestations, _, err := q.Attestations(AttestationFilter{AttestorIDKey: attestorIDKey, Type: bap.ATTEST}, Page{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	type attested struct {
+		height uint32
+		record *IdentityRecord
+	}
+	var identities []*attested
+	seen := make(map[string]bool)
+	for _, a := range attestations {
+		if len(a.Subject) == 0 || seen[a.Subject] {
+			continue
+		}
+		seen[a.Subject] = true
+		var record *IdentityRecord
+		if record, err = q.Identity(a.Subject); errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, 0, err
+		}
+		identities = append(identities, &attested{height: a.Block.Height, record: record})
+	}
+
+	total := len(identities)
+	sortByHeight(identities, page.Order, func(a *attested) uint32 { return a.height })
+	identities = paginate(identities, page)
+	records := make([]*IdentityRecord, len(identities))
+	for index, a := range identities {
+		records[index] = a.record
+	}
+	return records, total, nil
+}
+
+// Identities returns all identities ordered by the height they were first seen
+func (q *Query) Identities(page Page) ([]*IdentityRecord, int, error) {
+	identities, err := q.store.Identities()
+	if err != nil {
+		return nil, 0, err
+	}
+	records := make([]*IdentityRecord, 0, len(identities))
+	for _, identity := range identities {
+		var record *IdentityRecord
+		if record, err = q.identityRecord(identity); err != nil {
+			return nil, 0, err
+		}
+		records = append(records, record)
+	}
+	sortByHeight(records, page.Order, func(r *IdentityRecord) uint32 { return r.FirstSeen.Height })
+	return paginate(records, page), len(records), nil
+}
+
+// Identity returns the identity with the given id key
+func (q *Query) Identity(idKey string) (*IdentityRecord, error) {
+	identity, err := q.store.Identity(idKey)
+	if err != nil {
+		return nil, err
+	}
+	return q.identityRecord(identity)
+}
+
+// IdentityByAddress returns the identity that has (or had) the given signing address
+func (q *Query) IdentityByAddress(address string) (*IdentityRecord, error) {
+	identity, err := q.store.IdentityByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return q.identityRecord(identity)
+}
+
+// IDKeyByAddress returns the id key of the identity that has (or had) the given signing address,
+// or an empty id key if the address is unknown (see bap.AddressLookup)
+func (q *Query) IDKeyByAddress(address string) (string, error) {
+	identity, err := q.store.IdentityByAddress(address)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return identity.IDKey, nil
+}
+
+// Profile returns the current profile of an identity: the latest version signed by the
+// signing address of the identity that was valid at its block
+func (q *Query) Profile(idKey string) (*Record, error) {
+	versions, err := q.profileVersions(idKey)
+	if err != nil {
+		return nil, err
+	}
+	for index := len(versions) - 1; index >= 0; index-- {
+		if versions[index].Authorized {
+			return &versions[index].Record, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ProfileHistory returns every version of the profile of an identity with the block it was
+// published in, and the total number of versions. Versions are checked against the address
+// history of the identity, a rotation indexed after an ALIAS can make it unauthorized.
+func (q *Query) ProfileHistory(idKey string, page Page) ([]*ProfileVersion, int, error) {
+	versions, err := q.profileVersions(idKey)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, 0, err
+	}
+	if page.Order == Descending {
+		for i, j := 0, len(versions)-1; i < j; i, j = i+1, j-1 {
+			versions[i], versions[j] = versions[j], versions[i]
+		}
+	}
+	return paginate(versions, page), len(versions), nil
+}
+
+// ProfileDiff returns the field changes of the profile of an identity from one version to
+// another (see bap.DiffProfiles), version 0 is the empty profile before the first version
+func (q *Query) ProfileDiff(idKey string, from, to int) ([]*bap.ProfileChange, error) {
+	versions, err := q.profileVersions(idKey)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	profile := func(version int) (string, error) {
+		if version == 0 {
+			return "", nil
+		} else if version < 0 || version > len(versions) {
+			return "", ErrNotFound
+		}
+		return versions[version-1].Profile, nil
+	}
+	var fromProfile, toProfile string
+	if fromProfile, err = profile(from); err != nil {
+		return nil, err
+	} else if toProfile, err = profile(to); err != nil {
+		return nil, err
+	}
+	return bap.DiffProfiles(fromProfile, toProfile)
+}
+
+// profileVersions returns the profile versions of an identity in block order
+func (q *Query) profileVersions(idKey string) ([]*ProfileVersion, error) {
+	aliases, err := q.store.Aliases(idKey)
+	if err != nil {
+		return nil, err
+	} else if len(aliases) == 0 {
+		return nil, ErrNotFound
+	}
+	var identity *Identity
+	if identity, err = q.store.Identity(idKey); err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	sortByHeight(aliases, Ascending, func(a *Alias) uint32 { return a.Block.Height })
+	versions := make([]*ProfileVersion, len(aliases))
+	for index, a := range aliases {
+		versions[index] = &ProfileVersion{
+			Authorized: identity != nil && identity.AddressAt(a.Block.Height) == a.Address,
+			Record:     *aliasRecord(a),
+			Version:    index + 1,
+		}
+	}
+	return versions, nil
+}
+
+// AttestationStatus returns the status of an attestation of a URN hash by an attestor, from all
+// of the attestor's ATTEST and REVOKE records of the hash (see bap.ResolveStatus). The status of
+// the latest attestation is returned if txid is empty. Expiry is checked against the highest
+// indexed block and the current time, use AttestationStatusAt to give the chain tip.
+func (q *Query) AttestationStatus(urnHash, attestorIDKey, txid string) (*bap.Status, error) {
+	return q.attestationStatus(urnHash, attestorIDKey, txid, nil, time.Now())
+}
+
+// AttestationStatusAt returns the status of an attestation (see AttestationStatus) with its
+// expiry checked at the given chain height and time
+func (q *Query) AttestationStatusAt(urnHash, attestorIDKey, txid string, height uint32, now time.Time) (*bap.Status, error) {
+	return q.attestationStatus(urnHash, attestorIDKey, txid, &height, now)
+}
+
+// Threshold checks an M-of-N policy for a URN hash: the attestation of each policy attestor is
+// resolved (see AttestationStatus) and bap.ErrThresholdNotMet is returned with the result if
+// too few are active
+func (q *Query) Threshold(urnHash string, policy *bap.ThresholdPolicy) (*bap.ThresholdResult, error) {
+	return q.threshold(urnHash, policy, nil, time.Now())
+}
+
+// ThresholdAt checks an M-of-N policy (see Threshold) with expiry checked at the given chain height and time
+func (q *Query) ThresholdAt(urnHash string, policy *bap.ThresholdPolicy, height uint32, now time.Time) (*bap.ThresholdResult, error) {
+	return q.threshold(urnHash, policy, &height, now)
+}
+
+// threshold resolves the attestation of each policy attestor and checks the policy
+func (q *Query) threshold(urnHash string, policy *bap.ThresholdPolicy, height *uint32, now time.Time) (*bap.ThresholdResult, error) {
+	if policy == nil {
+		return nil, &bap.MissingFieldError{Field: "policy"}
+	} else if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	statuses := make(map[string]*bap.Status, len(policy.Attestors))
+	for _, attestor := range policy.Attestors {
+		status, err := q.attestationStatus(urnHash, attestor, "", height, now)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		statuses[attestor] = status
+	}
+	return policy.Check(statuses)
+}
+
+// attestationStatus resolves the status of an attestation, the chain height defaults to the highest indexed block
+func (q *Query) attestationStatus(urnHash, attestorIDKey, txid string, height *uint32, now time.Time) (*bap.Status, error) {
+	if len(urnHash) == 0 {
+		return nil, &bap.MissingFieldError{Field: "urnHash"}
+	} else if len(attestorIDKey) == 0 {
+		return nil, &bap.MissingFieldError{Field: "attestorIDKey"}
+	}
+	attestations, err := q.store.Attestations()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		records []*bap.StatusRecord
+		tip     uint32
+	)
+	for _, a := range attestations {
+		tip = max(tip, a.Block.Height)
+		if a.URNHash == urnHash && a.AttestorIDKey == attestorIDKey {
+			records = append(records, &bap.StatusRecord{
+				Expiry:   a.Expiry,
+				Height:   a.Block.Height,
+				Sequence: a.Sequence,
+				TxID:     a.TxID,
+				Type:     a.Type,
+			})
+		}
+	}
+	var status *bap.Status
+	if status, err = bap.ResolveStatus(records, txid); errors.Is(err, bap.ErrNoRecord) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if height != nil {
+		tip = *height
+	}
+	status.CheckExpiry(tip, now)
+	return status, nil
+}
+
+// identityRecord will build the identity record
+func (q *Query) identityRecord(identity *Identity) (*IdentityRecord, error) {
+	record := &IdentityRecord{Identity: identity, CurrentAddress: identity.CurrentAddress()}
+	if len(identity.Addresses) > 0 {
+		record.FirstSeen = identity.Addresses[0].Block
+	}
+	profile, err := q.Profile(identity.IDKey)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	record.Profile = profile
+	return record, nil
+}
+
+// attestationRecord will build the attestation record
+func (q *Query) attestationRecord(a *Attestation) (*AttestationRecord, error) {
+	subject, err := q.store.Subject(a.URNHash)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	return &AttestationRecord{
+		Record: Record{
+			Bap: &bap.Bap{
+				Address:  a.Address,
+				Expiry:   a.Expiry,
+				Sequence: a.Sequence,
+				Type:     a.Type,
+				URNHash:  a.URNHash,
+			},
+			Block:  a.Block,
+			Output: a.Output,
+			TxID:   a.TxID,
+		},
+		AttestorIDKey: a.AttestorIDKey,
+		Subject:       subject,
+	}, nil
+}
+
+// aliasRecord will build the record of an alias
+func aliasRecord(a *Alias) *Record {
+	return &Record{
+		Bap: &bap.Bap{
+			Address: a.Address,
+			IDKey:   a.IDKey,
+			Profile: a.Profile,
+			Type:    bap.ALIAS,
+		},
+		Block:  a.Block,
+		Output: a.Output,
+		TxID:   a.TxID,
+	}
+}
+
+// inHeightRange returns true if the height is within the (optional) range
+func inHeightRange(height, from, to uint32) bool {
+	return (from == 0 || effectiveHeight(height) >= from) && (to == 0 || effectiveHeight(height) <= to)
+}
+
+// sortByHeight will stable sort the items by block height
+func sortByHeight[T any](items []T, order Order, height func(T) uint32) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if order == Descending {
+			return effectiveHeight(height(items[i])) > effectiveHeight(height(items[j]))
+		}
+		return effectiveHeight(height(items[i])) < effectiveHeight(height(items[j]))
+	})
+}
+
+// paginate will return the page of items
+func paginate[T any](items []T, page Page) []T {
+	if page.Offset < 0 || page.Offset >= len(items) {
+		return []T{}
+	}
+	items = items[page.Offset:]
+	if page.Limit > 0 && page.Limit < len(items) {
+		items = items[:page.Limit]
+	}
+	return items
+}
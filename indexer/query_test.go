@@ -0,0 +1,516 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Second identity of the query tests
+const (
+	testSecondKey    = "xprv9s21ZrQH143K2JF8RafpqtKiTbsbaxEeUaMnNHsm5o6wCW3z8ySyH4UxFVSfZ8n7ESu7fgir8imbZKLYVBxFPND1pniTZ81vKfd45EHKX73"
+	testSecondIDKey  = "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+	testUnknownIDKey = "00112233445566778899aabbccddeeff00112233445566778899aabbccddeeff"
+)
+
+// testQueryFixture indexes the fixture plus a second identity attested by the attestor
+func testQueryFixture(t testing.TB) (*Indexer, *Query) {
+	idx := testFixture(t, NewMemoryStore())
+
+	identityTx, err := bap.CreateIdentity(testSecondKey, testSecondIDKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	attestorKey, _ := testutil.SigningKey(t, testAttestorKey, 0)
+	requests := []bap.AttestationRequest{
+		{IDKey: testSecondIDKey, AttributeName: "name", AttributeValue: "Jane", IdentityAttributeSecret: "jane-secret"},
+		{IDKey: testIDKey, AttributeName: "email", AttributeValue: "john@example.com", IdentityAttributeSecret: "email-secret"},
+	}
+	attestTx, err := bap.CreateAttestations(attestorKey, requests)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, err = idx.AddTx(identityTx, Block{Height: 110}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, err = idx.AddTx(attestTx, Block{Height: 111}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Link the attributes to their identities
+	for _, claim := range []bap.AttestationRequest{
+		{IDKey: testIDKey, AttributeName: "name", AttributeValue: "John", IdentityAttributeSecret: "secret"},
+		requests[0],
+		requests[1],
+	} {
+		if _, err = idx.AddClaim(claim.IDKey, claim.AttributeName, claim.AttributeValue, claim.IdentityAttributeSecret); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+	return idx, NewQuery(idx.Store())
+}
+
+// TestQuery_Attestations will test the method Attestations()
+func TestQuery_Attestations(t *testing.T) {
+	t.Parallel()
+
+	_, q := testQueryFixture(t)
+	hash := bap.AttestationHash(testIDKey, "name", "John", "secret")
+	urnHash := fmt.Sprintf("%x", hash)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name            string
+			filter          AttestationFilter
+			page            Page
+			expectedTotal   int
+			expectedHeights []uint32
+		}{
+			{"all", AttestationFilter{}, Page{}, 4, []uint32{102, 106, 111, 111}},
+			{"descending", AttestationFilter{}, Page{Order: Descending}, 4, []uint32{111, 111, 106, 102}},
+			{"paged", AttestationFilter{}, Page{Offset: 1, Limit: 2}, 4, []uint32{106, 111}},
+			{"past end", AttestationFilter{}, Page{Offset: 10}, 4, []uint32{}},
+			{"urn hash", AttestationFilter{URNHashes: []string{urnHash}}, Page{}, 2, []uint32{102, 106}},
+			{"type", AttestationFilter{Type: bap.REVOKE}, Page{}, 1, []uint32{106}},
+			{"height range", AttestationFilter{FromHeight: 103, ToHeight: 110}, Page{}, 1, []uint32{106}},
+			{"attestor", AttestationFilter{AttestorIDKey: testAttestorID}, Page{Limit: 1}, 4, []uint32{102}},
+			{"subject", AttestationFilter{Subject: testIDKey}, Page{}, 3, []uint32{102, 106, 111}},
+			{"unknown attestor", AttestationFilter{AttestorIDKey: "unknown"}, Page{}, 0, []uint32{}},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		records, total, err := q.Attestations(test.filter, test.page)
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		} else if total != test.expectedTotal {
+			t.Errorf("%s Failed: [%s] inputted and expected total [%d] but got [%d]", t.Name(), test.name, test.expectedTotal, total)
+		}
+		heights := make([]uint32, len(records))
+		for index, record := range records {
+			heights[index] = record.Block.Height
+		}
+		if fmt.Sprint(heights) != fmt.Sprint(test.expectedHeights) {
+			t.Errorf("%s Failed: [%s] inputted and expected heights %v but got %v", t.Name(), test.name, test.expectedHeights, heights)
+		}
+	}
+}
+
+// TestQuery_Identities will test the identity queries
+func TestQuery_Identities(t *testing.T) {
+	t.Parallel()
+
+	_, q := testQueryFixture(t)
+
+	// Attestations for an identity
+	records, total, err := q.AttestationsForIdentity(testIDKey, Page{})
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if total != 3 || records[0].AttestorIDKey != testAttestorID || records[0].TxID == "" {
+		t.Fatalf("unexpected attestations: %d %+v", total, records)
+	}
+	if _, _, err = q.AttestationsForIdentity("", Page{}); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	}
+
+	// Identities attested by the attestor
+	var identities []*IdentityRecord
+	if identities, total, err = q.IdentitiesAttestedBy(testAttestorID, Page{Order: Descending}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if total != 2 || len(identities) != 2 || identities[0].IDKey == testIDKey || identities[1].IDKey != testIDKey {
+		t.Fatalf("unexpected identities: %d %+v", total, identities)
+	}
+	if _, _, err = q.IdentitiesAttestedBy("", Page{}); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	}
+
+	// All identities
+	if identities, total, err = q.Identities(Page{}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if total != 3 || identities[0].IDKey != testIDKey || identities[2].FirstSeen.Height != 110 {
+		t.Fatalf("unexpected identities: %d %+v", total, identities)
+	}
+
+	// Identity by address (old and current) with its current profile
+	_, rootAddress := testutil.SigningKey(t, testIdentityKey, 0)
+	_, currentAddress := testutil.SigningKey(t, testIdentityKey, 1)
+	var identity *IdentityRecord
+	if identity, err = q.IdentityByAddress(rootAddress); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if identity.IDKey != testIDKey || identity.CurrentAddress != currentAddress {
+		t.Fatalf("unexpected identity: %+v", identity)
+	} else if identity.Profile == nil || identity.Profile.Profile != `{"name":"John Adams"}` || identity.Profile.Block.Height != 105 {
+		t.Fatalf("unexpected profile: %+v", identity.Profile)
+	}
+	if _, err = q.IdentityByAddress("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got: %v", err)
+	}
+
+	// Profile
+	var profile *Record
+	if profile, err = q.Profile(testIDKey); err != nil || profile.Type != bap.ALIAS || profile.IDKey != testIDKey {
+		t.Fatalf("unexpected profile: %+v %v", profile, err)
+	}
+	if _, err = q.Profile("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound but got: %v", err)
+	}
+}
+
+// TestQuery_ProfileHistory will test the method ProfileHistory()
+func TestQuery_ProfileHistory(t *testing.T) {
+	t.Parallel()
+
+	_, q := testQueryFixture(t)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name             string
+			idKey            string
+			page             Page
+			expectedVersions string
+			expectedTotal    int
+		}{
+			{"all", testIDKey, Page{}, `1@103 {"name":"John"}, 2@105 {"name":"John Adams"}`, 2},
+			{"descending", testIDKey, Page{Order: Descending, Limit: 1}, `2@105 {"name":"John Adams"}`, 2},
+			{"offset", testIDKey, Page{Offset: 1}, `2@105 {"name":"John Adams"}`, 2},
+			{"no profile", testSecondIDKey, Page{}, ``, 0},
+			{"unknown", "unknown", Page{}, ``, 0},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		versions, total, err := q.ProfileHistory(test.idKey, test.page)
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		}
+		var list []string
+		for _, version := range versions {
+			if !version.Authorized || version.Type != bap.ALIAS {
+				t.Errorf("%s Failed: [%s] inputted and expected authorized versions but got %+v", t.Name(), test.name, version)
+			}
+			list = append(list, fmt.Sprintf("%d@%d %s", version.Version, version.Block.Height, version.Profile))
+		}
+		if strings.Join(list, ", ") != test.expectedVersions || total != test.expectedTotal {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] of %d but got [%s] of %d", t.Name(), test.name,
+				test.expectedVersions, test.expectedTotal, strings.Join(list, ", "), total)
+		}
+	}
+}
+
+// TestQuery_ProfileAuthorization will test that the current profile skips versions signed by a
+// replaced address, when the rotation is indexed after the ALIAS
+func TestQuery_ProfileAuthorization(t *testing.T) {
+	t.Parallel()
+
+	idx := testNew(t, NewMemoryStore())
+	identityTx, err := bap.CreateIdentity(testIdentityKey, testIDKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	rootKey, _ := testutil.SigningKey(t, testIdentityKey, 0)
+	for _, indexed := range []struct {
+		height uint32
+		tx     *transaction.Transaction
+	}{
+		{100, identityTx},
+		{103, testutil.SignedTx(t, rootKey, []byte(bap.ALIAS), []byte(testIDKey), []byte(`{"name":"John"}`))},
+		{105, testutil.SignedTx(t, rootKey, []byte(bap.ALIAS), []byte(testIDKey), []byte(`{"name":"Mallory"}`))},
+		{104, testutil.RotationTx(t, testIdentityKey, testIDKey, 1)},
+	} {
+		if _, err = idx.AddTx(indexed.tx, Block{Height: indexed.height}); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+
+	q := NewQuery(idx.Store())
+	var versions []*ProfileVersion
+	if versions, _, err = q.ProfileHistory(testIDKey, Page{}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(versions) != 2 || !versions[0].Authorized || versions[1].Authorized {
+		t.Fatalf("%s Failed: expected the version at 105 to be unauthorized but got %+v", t.Name(), versions)
+	}
+	var profile *Record
+	if profile, err = q.Profile(testIDKey); err != nil || profile.Profile != `{"name":"John"}` {
+		t.Fatalf("%s Failed: expected the profile at 103 but got %+v %v", t.Name(), profile, err)
+	}
+}
+
+// TestQuery_ProfileDiff will test the method ProfileDiff()
+func TestQuery_ProfileDiff(t *testing.T) {
+	t.Parallel()
+
+	_, q := testQueryFixture(t)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			idKey         string
+			from          int
+			to            int
+			expected      string
+			expectedError error
+		}{
+			{"update", testIDKey, 1, 2, `name changed "John" "John Adams"`, nil},
+			{"reverse", testIDKey, 2, 1, `name changed "John Adams" "John"`, nil},
+			{"first version", testIDKey, 0, 1, `name added "John"`, nil},
+			{"same version", testIDKey, 2, 2, ``, nil},
+			{"unknown version", testIDKey, 1, 3, ``, ErrNotFound},
+			{"negative version", testIDKey, -1, 1, ``, ErrNotFound},
+			{"unknown identity", "unknown", 0, 1, ``, ErrNotFound},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		changes, err := q.ProfileDiff(test.idKey, test.from, test.to)
+		if !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+			continue
+		}
+		var list []string
+		for _, change := range changes {
+			list = append(list, strings.Join(strings.Fields(fmt.Sprintf("%s %s %s %s", change.Field, change.Type, string(change.From), string(change.To))), " "))
+		}
+		if strings.Join(list, ", ") != test.expected {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.name, test.expected, strings.Join(list, ", "))
+		}
+	}
+}
+
+// TestQuery_IDKeyByAddress will test the method IDKeyByAddress() and counter recovery with it
+func TestQuery_IDKeyByAddress(t *testing.T) {
+	t.Parallel()
+
+	_, q := testQueryFixture(t)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			counter       uint32
+			expectedIDKey string
+		}{
+			{"root address", 0, testIDKey},
+			{"rotated address", 1, testIDKey},
+			{"unused address", 2, ""},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		_, address := testutil.SigningKey(t, testIdentityKey, test.counter)
+		if idKey, err := q.IDKeyByAddress(address); err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if idKey != test.expectedIDKey {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.name, test.expectedIDKey, idKey)
+		}
+	}
+
+	// The identity restored from its key continues after the indexed rotation
+	identity, err := bap.NewIdentity(testIdentityKey, testIDKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var counter uint32
+	if counter, err = identity.RecoverCounter(q, 0); err != nil || counter != 1 {
+		t.Fatalf("%s Failed: expected counter 1 but got %d %v", t.Name(), counter, err)
+	}
+}
+
+// TestQuery_DiscoverIdentities will test discovering the indexed identities of a key
+func TestQuery_DiscoverIdentities(t *testing.T) {
+	t.Parallel()
+
+	_, q := testQueryFixture(t)
+	identities, err := bap.DiscoverIdentities(testIdentityKey, q, nil)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(identities) != 1 {
+		t.Fatalf("%s Failed: expected 1 identity but got %d", t.Name(), len(identities))
+	}
+
+	_, rootAddress := testutil.SigningKey(t, testIdentityKey, 0)
+	if identities[0].IDKey != testIDKey || identities[0].Counter != 1 || identities[0].RootAddress != rootAddress {
+		t.Fatalf("%s Failed: expected %s at counter 1 but got %+v", t.Name(), testIDKey, identities[0])
+	}
+}
+
+// TestQuery_AttestationStatus will test the method AttestationStatus()
+func TestQuery_AttestationStatus(t *testing.T) {
+	t.Parallel()
+
+	_, q := testQueryFixture(t)
+	hash := bap.AttestationHash(testIDKey, "name", "John", "secret")
+	urnHash := fmt.Sprintf("%x", hash)
+	emailHash := bap.AttestationHash(testIDKey, "email", "john@example.com", "email-secret")
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name           string
+			urnHash        string
+			attestorIDKey  string
+			expectedStatus bap.AttestationStatus
+			expectedHeight uint32
+			expectedError  error
+		}{
+			{"revoked", urnHash, testAttestorID, bap.StatusRevoked, 106, nil},
+			{"active", fmt.Sprintf("%x", emailHash), testAttestorID, bap.StatusActive, 111, nil},
+			{"other attestor", urnHash, testIDKey, "", 0, ErrNotFound},
+			{"unknown hash", "unknown", testAttestorID, "", 0, ErrNotFound},
+			{"no hash", "", testAttestorID, "", 0, bap.ErrMissingField},
+			{"no attestor", urnHash, "", "", 0, bap.ErrMissingField},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if status, err := q.AttestationStatus(test.urnHash, test.attestorIDKey, ""); test.expectedError != nil && !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		} else if test.expectedError == nil && err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if test.expectedError == nil && (status.Status != test.expectedStatus || status.Height != test.expectedHeight) {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s at %d] but got [%s at %d]", t.Name(), test.name,
+				test.expectedStatus, test.expectedHeight, status.Status, status.Height)
+		}
+	}
+}
+
+// TestQuery_AttestationStatusAt will test the expiry checks of AttestationStatusAt()
+func TestQuery_AttestationStatusAt(t *testing.T) {
+	t.Parallel()
+
+	idx, q := testQueryFixture(t)
+	attestorKey, _ := testutil.SigningKey(t, testAttestorKey, 0)
+	tx, err := bap.CreateAttestationWithExpiry(testIDKey, attestorKey, "age", "21+", "age-secret", 200)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if _, err = idx.AddTx(tx, Block{Height: 120}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	hash := bap.AttestationHash(testIDKey, "age", "21+", "age-secret")
+	urnHash := fmt.Sprintf("%x", hash)
+
+	var status *bap.Status
+	if status, err = q.AttestationStatusAt(urnHash, testAttestorID, "", 199, time.Now()); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if status.Status != bap.StatusActive || status.Expiry != 200 {
+		t.Fatalf("%s Failed: expected an active attestation expiring at 200 but got: %+v", t.Name(), status)
+	}
+	if status, err = q.AttestationStatusAt(urnHash, testAttestorID, "", 200, time.Now()); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if status.Status != bap.StatusExpired || status.Height != 200 {
+		t.Fatalf("%s Failed: expected an attestation expired at 200 but got: %+v", t.Name(), status)
+	}
+
+	// The highest indexed block (120) is the default chain height
+	if status, err = q.AttestationStatus(urnHash, testAttestorID, ""); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if status.Status != bap.StatusActive {
+		t.Fatalf("%s Failed: expected an active attestation but got: %+v", t.Name(), status)
+	}
+
+	// The expiry is part of the attestation record
+	var records []*AttestationRecord
+	if records, _, err = q.Attestations(AttestationFilter{URNHashes: []string{urnHash}}, Page{}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(records) != 1 || records[0].Expiry != 200 {
+		t.Fatalf("%s Failed: expected the expiry in the attestation record but got: %+v", t.Name(), records)
+	}
+}
+
+// TestQuery_Threshold will test the method Threshold()
+func TestQuery_Threshold(t *testing.T) {
+	t.Parallel()
+
+	idx, q := testQueryFixture(t)
+	attestorKey, _ := testutil.SigningKey(t, testAttestorKey, 0)
+	identityKey, _ := testutil.SigningKey(t, testIdentityKey, 1) // Rotated at 104
+	secondKey, _ := testutil.SigningKey(t, testSecondKey, 0)
+	request := bap.AttestationRequest{IDKey: testSecondIDKey, AttributeName: "kyc", AttributeValue: "passed", IdentityAttributeSecret: "kyc-secret"}
+	tx, err := bap.CreateThresholdAttestation([]*ec.PrivateKey{attestorKey, identityKey, secondKey}, request)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if _, err = idx.AddTx(tx, Block{Height: 120}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	hash := bap.AttestationHash(request.IDKey, request.AttributeName, request.AttributeValue, request.IdentityAttributeSecret)
+	urnHash := fmt.Sprintf("%x", hash)
+
+	// The attestor revokes its attestation
+	if _, err = idx.AddTx(testutil.SignedTx(t, attestorKey, []byte(bap.REVOKE), hash[:], []byte("1")), Block{Height: 121}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name             string
+			threshold        int
+			attestors        []string
+			expectedApproved int
+			expectedError    error
+		}{
+			{"2 of 3", 2, []string{testAttestorID, testIDKey, testSecondIDKey}, 2, nil},
+			{"3 of 3", 3, []string{testAttestorID, testIDKey, testSecondIDKey}, 2, bap.ErrThresholdNotMet},
+			{"revoked attestor", 1, []string{testAttestorID}, 0, bap.ErrThresholdNotMet},
+			{"attestor without attestation", 2, []string{testIDKey, testUnknownIDKey}, 1, bap.ErrThresholdNotMet},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		policy := &bap.ThresholdPolicy{Attestors: test.attestors, Threshold: test.threshold}
+		if result, thresholdErr := q.Threshold(urnHash, policy); test.expectedError == nil && thresholdErr != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, thresholdErr.Error())
+		} else if test.expectedError != nil && !errors.Is(thresholdErr, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, thresholdErr)
+		} else if len(result.Approved) != test.expectedApproved || result.Met != (test.expectedError == nil) {
+			t.Errorf("%s Failed: [%s] inputted and expected [%d] approved but got %+v", t.Name(), test.name, test.expectedApproved, result)
+		}
+	}
+
+	if _, err = q.Threshold(urnHash, nil); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("%s Failed: expected ErrMissingField but got: %v", t.Name(), err)
+	}
+}
+
+// ExampleQuery_Attestations example using Attestations()
+func ExampleQuery_Attestations() {
+	store := NewMemoryStore()
+	idx := New(store, nil)
+	if err := idx.SetRootAddress(testIDKey, "1A9VQqdNJrvVF73nf879n2fES6cd5nWNid"); err != nil {
+		fmt.Printf("invalid root address: %s", err.Error())
+		return
+	}
+	tx, err := bap.CreateIdentity(testIdentityKey, testIDKey, 0)
+	if err != nil {
+		fmt.Printf("failed to create identity: %s", err.Error())
+		return
+	}
+	if _, err = idx.AddTx(tx, Block{Height: 100}); err != nil {
+		fmt.Printf("failed to index: %s", err.Error())
+		return
+	}
+	_, total, err := NewQuery(store).Attestations(AttestationFilter{Type: bap.ATTEST}, Page{Limit: 10})
+	if err != nil {
+		fmt.Printf("failed to query: %s", err.Error())
+		return
+	}
+	fmt.Printf("attestations: %d", total)
+	// Output:attestations: 0
+}
@@ -0,0 +1,138 @@
+package indexer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bitcoinschema/go-bap"
+)
+
+// Block is the block metadata of an indexed transaction (Height 0 is unconfirmed)
+type Block struct {
+	Hash   string `json:"hash,omitempty"`
+	Height uint32 `json:"height,omitempty"`
+	Time   uint32 `json:"time,omitempty"`
+}
+
+// Identity is an indexed BAP identity and its address rotation history
+type Identity struct {
+	Addresses   []*IdentityAddress `json:"addresses"`
+	IDKey       string             `json:"id_key"`
+	RootAddress string             `json:"root_address"`
+}
+
+// IdentityAddress is a signing address of an identity, valid from its block until the next rotation
+type IdentityAddress struct {
+	Address string `json:"address"`
+	Block   Block  `json:"block"`
+	TxID    string `json:"txid"`
+}
+
+// Attestation is an indexed ATTEST or REVOKE record
+type Attestation struct {
+	Address       string              `json:"address"`                   // Address that signed the record
+	AttestorIDKey string              `json:"attestor_id_key,omitempty"` // Identity of the signing address, if known
+	Block         Block               `json:"block"`
+	Expiry        bap.Expiry          `json:"expiry,omitempty"` // Block height or time an ATTEST expires, if any
+	Output        int                 `json:"output"`
+	Sequence      uint64              `json:"sequence"`
+	TxID          string              `json:"txid"`
+	Type          bap.AttestationType `json:"type"`
+	URNHash       string              `json:"urn_hash"`
+}
+
+// Alias is an indexed ALIAS (profile) record
+type Alias struct {
+	Address string `json:"address"` // Address that signed the record
+	Block   Block  `json:"block"`
+	IDKey   string `json:"id_key"`
+	Output  int    `json:"output"`
+	Profile string `json:"profile"`
+	TxID    string `json:"txid"`
+}
+
+// Data is an indexed DATA record
+type Data struct {
+	Address string `json:"address"` // Address that signed the record
+	Block   Block  `json:"block"`
+	Data    string `json:"data"`
+	IDKey   string `json:"id_key,omitempty"` // Identity of the signing address, if known
+	Output  int    `json:"output"`
+	TxID    string `json:"txid"`
+	URNHash string `json:"urn_hash"`
+}
+
+// CurrentAddress returns the latest signing address of the identity
+func (i *Identity) CurrentAddress() string {
+	if len(i.Addresses) == 0 {
+		return ""
+	}
+	return i.Addresses[len(i.Addresses)-1].Address
+}
+
+// AddressAt returns the signing address that was valid at the given block height
+// (0 is treated as the mempool, after every mined block)
+func (i *Identity) AddressAt(height uint32) string {
+	var address string
+	for _, a := range i.Addresses {
+		if effectiveHeight(a.Block.Height) > effectiveHeight(height) {
+			break
+		}
+		address = a.Address
+	}
+	return address
+}
+
+// HasAddress returns true if the address was ever a signing address of the identity
+func (i *Identity) HasAddress(address string) bool {
+	for _, a := range i.Addresses {
+		if a.Address == address {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTx returns true if an address of the identity was set by the transaction
+func (i *Identity) hasTx(txid string) bool {
+	for _, a := range i.Addresses {
+		if a.TxID == txid {
+			return true
+		}
+	}
+	return false
+}
+
+// key returns the key that identifies the record: its output, signer, type and URN hash
+func (a *Attestation) key() string {
+	return fmt.Sprintf("%s %s:%d %s %s %s", entryAttestation, a.TxID, a.Output, a.Address, a.Type, a.URNHash)
+}
+
+// key returns the key that identifies the record: its output and signer
+func (a *Alias) key() string {
+	return fmt.Sprintf("%s %s:%d %s", entryAlias, a.TxID, a.Output, a.Address)
+}
+
+// key returns the key that identifies the record: its output, signer and URN hash
+func (d *Data) key() string {
+	return fmt.Sprintf("%s %s:%d %s %s", entryData, d.TxID, d.Output, d.Address, d.URNHash)
+}
+
+// clone returns a deep copy of the identity
+func (i *Identity) clone() *Identity {
+	c := *i
+	c.Addresses = make([]*IdentityAddress, len(i.Addresses))
+	for index, a := range i.Addresses {
+		address := *a
+		c.Addresses[index] = &address
+	}
+	return &c
+}
+
+// effectiveHeight sorts unconfirmed (height 0) records after all mined blocks
+func effectiveHeight(height uint32) uint32 {
+	if height == 0 {
+		return math.MaxUint32
+	}
+	return height
+}
@@ -0,0 +1,58 @@
+package indexer
+
+import "errors"
+
+// ErrNotFound is returned by a Store when a record does not exist
+var ErrNotFound = errors.New("not found")
+
+// Store persists indexed BAP records
+//
+// Records returned by a Store are copies and can be modified freely. Saving an attestation,
+// alias or data record that was already saved (same transaction, output, signer, type and
+// URN hash) must do nothing, so a transaction can be indexed again after a partial failure.
+type Store interface {
+	// Identity returns the identity with the given id key
+	Identity(idKey string) (*Identity, error)
+
+	// IdentityByAddress returns the identity that has (or had) the given signing address
+	IdentityByAddress(address string) (*Identity, error)
+
+	// Identities returns all identities
+	Identities() ([]*Identity, error)
+
+	// SaveIdentity creates or replaces an identity
+	SaveIdentity(identity *Identity) error
+
+	// Attestations returns all ATTEST and REVOKE records, in the order they were saved
+	Attestations() ([]*Attestation, error)
+
+	// SaveAttestation adds an ATTEST or REVOKE record, unless it was already saved
+	SaveAttestation(attestation *Attestation) error
+
+	// Aliases returns the ALIAS records of an identity, in the order they were saved
+	Aliases(idKey string) ([]*Alias, error)
+
+	// SaveAlias adds an ALIAS record, unless it was already saved
+	SaveAlias(alias *Alias) error
+
+	// Data returns the DATA records for a URN hash, in the order they were saved
+	Data(urnHash string) ([]*Data, error)
+
+	// SaveData adds a DATA record, unless it was already saved
+	SaveData(data *Data) error
+
+	// Subject returns the id key of the identity an attestation URN hash belongs to
+	Subject(urnHash string) (string, error)
+
+	// SaveSubject links an attestation URN hash to the identity it belongs to
+	SaveSubject(urnHash, idKey string) error
+
+	// HasTx returns true if the transaction has already been indexed
+	HasTx(txid string) (bool, error)
+
+	// SaveTx marks the transaction as indexed
+	SaveTx(txid string, block Block) error
+
+	// Close releases any resources held by the store
+	Close() error
+}
@@ -0,0 +1,66 @@
+// Package testindex builds the indexers shared by the tests of the packages on top of the
+// indexer: seeded with the test identities of the testutil package
+package testindex
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Secret is the identity attribute secret of the test attestations
+const Secret = "secret"
+
+// New returns an indexer over a memory store, with the root addresses of the test identities
+// configured and the ID records of the given identities indexed from height 100, in order
+func New(t testing.TB, idKeys ...string) *indexer.Indexer {
+	idx := indexer.New(indexer.NewMemoryStore(), nil)
+	for idKey, xPrivateKey := range testutil.RootKeys {
+		if err := idx.SetRootAddress(idKey, testutil.RootAddress(t, xPrivateKey)); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+
+	txs := make([]*transaction.Transaction, len(idKeys))
+	for index, idKey := range idKeys {
+		var err error
+		if txs[index], err = bap.CreateIdentity(testutil.RootKeys[idKey], idKey, 0); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+	Add(t, idx, 100, txs...)
+	return idx
+}
+
+// Add will index the transactions at consecutive heights from height, mined at 1600000000 + height
+func Add(t testing.TB, idx *indexer.Indexer, height uint32, txs ...*transaction.Transaction) {
+	for index, tx := range txs {
+		at := height + uint32(index)
+		if _, err := idx.AddTx(tx, indexer.Block{Height: at, Time: 1600000000 + at}); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+}
+
+// AddHeights will index the transactions by height, lowest first
+func AddHeights(t testing.TB, idx *indexer.Indexer, txs map[uint32]*transaction.Transaction) {
+	heights := make([]uint32, 0, len(txs))
+	for height := range txs {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	for _, height := range heights {
+		Add(t, idx, height, txs[height])
+	}
+}
+
+// Attestation returns an ATTEST or REVOKE record of an attribute (hashed with Secret), signed by the key
+func Attestation(t testing.TB, key *ec.PrivateKey, recordType bap.AttestationType, idKey, name, value, sequence string) *transaction.Transaction {
+	hash := bap.AttestationHash(idKey, name, value, Secret)
+	return testutil.SignedTx(t, key, []byte(recordType), hash[:], []byte(sequence))
+}
@@ -0,0 +1,87 @@
+// Package testutil provides the keys and signed BAP transactions shared by the tests
+//
+// It does not import the bap package, so the bap package tests can use it too. Indexers
+// seeded with the test identities are built by the testindex package.
+package testutil
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-aip"
+	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+const (
+	// bapPrefix is the bitcom prefix of BAP (bap.Prefix)
+	bapPrefix = "1BAPSuaPnfGnSBM3GLV9yhxUdYe4vGbdMT"
+
+	// idType is the ID record type (bap.ID)
+	idType = "ID"
+)
+
+// Test identities: root keys (xprv) and their 64 character hex id keys, which are not derived
+// from the root address so indexers need their root address configured (see RootKeys)
+const (
+	AttestorID  = "0d5d1e0b1bd2c0f9b8c7e6a5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5"
+	AttestorKey = "xprv9s21ZrQH143K3PZSwbEeXEYq74EbnfMngzAiMCZcfjzyRpUvt2vQJnaHRTZjeuEmLXeN6BzYRoFsEckfobxE9XaRzeLGfQoxzPzTRyRb6oE"
+	IDKey       = "8bafa4ca97d770276253585cb2a49da1775ec7aeed3178e346c8c1b55eaf5ca2"
+	IdentityKey = "xprv9s21ZrQH143K2beTKhLXFRWWFwH8jkwUssjk3SVTiApgmge7kNC3jhVc4NgHW8PhW2y7BCDErqnKpKuyQMjqSePPJooPJowAz5BVLThsv6c"
+	OtherID     = "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+	OtherKey    = "xprv9s21ZrQH143K2JF8RafpqtKiTbsbaxEeUaMnNHsm5o6wCW3z8ySyH4UxFVSfZ8n7ESu7fgir8imbZKLYVBxFPND1pniTZ81vKfd45EHKX73"
+)
+
+// RootKeys are the root keys of the test identities by id key
+var RootKeys = map[string]string{
+	AttestorID: AttestorKey,
+	IDKey:      IdentityKey,
+	OtherID:    OtherKey,
+}
+
+// SigningKey returns the signing key (and mainnet address) of an xprv at 0/counter
+func SigningKey(t testing.TB, xPrivateKey string, counter uint32) (*ec.PrivateKey, string) {
+	hdKey, err := hd.NewKeyFromString(xPrivateKey)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var child *hd.ExtendedKey
+	if child, err = hdKey.DeriveChildFromPath(fmt.Sprintf("0/%d", counter)); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var key *ec.PrivateKey
+	if key, err = child.ECPrivKey(); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return key, child.Address(&chaincfg.MainNet)
+}
+
+// RootAddress returns the root address (0/0) of an xprv
+func RootAddress(t testing.TB, xPrivateKey string) string {
+	_, address := SigningKey(t, xPrivateKey, 0)
+	return address
+}
+
+// SignedTx returns a transaction with a BAP record (type and fields) signed by the given key
+func SignedTx(t testing.TB, key *ec.PrivateKey, fields ...[]byte) *transaction.Transaction {
+	data := append([][]byte{[]byte(bapPrefix)}, fields...)
+	data = append(data, []byte("|"))
+	parts, _, err := aip.SignOpReturnData(key, aip.BitcoinECDSA, data)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	tx := transaction.NewTransaction()
+	if err = tx.AddOpReturnPartsOutput(parts); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return tx
+}
+
+// RotationTx returns an ID record rotating to the address at 0/counter, signed by the previous key (0/counter-1)
+func RotationTx(t testing.TB, xPrivateKey, idKey string, counter uint32) *transaction.Transaction {
+	previousKey, _ := SigningKey(t, xPrivateKey, counter-1)
+	_, address := SigningKey(t, xPrivateKey, counter)
+	return SignedTx(t, previousKey, []byte(idType), []byte(idKey), []byte(address))
+}
@@ -0,0 +1,133 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Encryption constants
+const (
+	cipherName = "aes-256-gcm"
+	kdfName    = "argon2id"
+	keyLength  = 32
+	saltLength = 16
+)
+
+// Limits of the KDF parameters, so a keystore file cannot make unlocking exhaust the machine
+const (
+	maxMemory  = 4 * 1024 * 1024 // KiB (4 GiB)
+	maxThreads = 255
+	maxTime    = 16
+)
+
+// KDFParams are the argon2id parameters deriving the encryption key from the password
+type KDFParams struct {
+	Memory  uint32 `json:"memory"`  // KiB
+	Threads uint8  `json:"threads"` // Parallelism
+	Time    uint32 `json:"time"`    // Iterations
+}
+
+// DefaultKDFParams are the argon2id parameters used for new keystores (64 MiB, 3 iterations, 4 threads)
+var DefaultKDFParams = KDFParams{Memory: 64 * 1024, Threads: 4, Time: 3}
+
+// validate checks that the parameters can derive a key, within the limits
+func (p *KDFParams) validate() error {
+	if p.Memory < 8*uint32(p.Threads) || p.Memory > maxMemory ||
+		p.Threads == 0 || p.Threads > maxThreads || p.Time == 0 || p.Time > maxTime {
+		return fmt.Errorf("%w: invalid kdf parameters %+v", ErrInvalidKeystore, *p)
+	}
+	return nil
+}
+
+// kdf is the key derivation of a keystore file
+type kdf struct {
+	KDFParams
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+}
+
+// envelope is the keystore file: the encrypted identities and how to decrypt them
+type envelope struct {
+	Cipher     string `json:"cipher"`
+	Ciphertext []byte `json:"ciphertext"`
+	KDF        *kdf   `json:"kdf"`
+	Nonce      []byte `json:"nonce"`
+	Version    int    `json:"version"`
+}
+
+// newKDF returns a key derivation with a random salt
+func newKDF(params KDFParams) (*kdf, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	k := &kdf{KDFParams: params, Name: kdfName, Salt: make([]byte, saltLength)}
+	if _, err := rand.Read(k.Salt); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// deriveKey derives the encryption key from the password
+func (k *kdf) deriveKey(password string) []byte {
+	return argon2.IDKey([]byte(password), k.Salt, k.Time, k.Memory, k.Threads, keyLength)
+}
+
+// additionalData binds the envelope header to the ciphertext
+func (e *envelope) additionalData() ([]byte, error) {
+	return json.Marshal(struct {
+		Cipher  string `json:"cipher"`
+		KDF     *kdf   `json:"kdf"`
+		Version int    `json:"version"`
+	}{e.Cipher, e.KDF, e.Version})
+}
+
+// seal will encrypt the plaintext with a fresh nonce
+func (e *envelope) seal(key, plaintext []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	e.Nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(e.Nonce); err != nil {
+		return err
+	}
+	var ad []byte
+	if ad, err = e.additionalData(); err != nil {
+		return err
+	}
+	e.Ciphertext = aead.Seal(nil, e.Nonce, plaintext, ad)
+	return nil
+}
+
+// open will decrypt the ciphertext, failing with ErrWrongPassword for a wrong key
+func (e *envelope) open(key []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	} else if len(e.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid nonce", ErrInvalidKeystore)
+	}
+	var ad []byte
+	if ad, err = e.additionalData(); err != nil {
+		return nil, err
+	}
+	var plaintext []byte
+	if plaintext, err = aead.Open(nil, e.Nonce, e.Ciphertext, ad); err != nil {
+		return nil, ErrWrongPassword
+	}
+	return plaintext, nil
+}
+
+// newAEAD returns the AES-256-GCM cipher of a key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
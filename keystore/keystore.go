@@ -0,0 +1,424 @@
+// Package keystore stores BAP identities (root keys, rotation counters and attributes) in a
+// password-encrypted file
+//
+// The identities are encrypted with AES-256-GCM using a key derived from the password with
+// argon2id. A keystore opens locked; Unlock derives the key and keeps it in memory until Lock.
+// Every change takes a lock file and re-reads the file first, so several processes sharing a
+// keystore never lose updates or advance a counter to the same signing path.
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/bitcoinschema/go-bap"
+	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
+)
+
+// version is the keystore file format version
+const version = 1
+
+// Keystore errors
+var (
+	// ErrBusy is returned when another process holds the keystore lock for longer than LockTimeout
+	ErrBusy = errors.New("keystore is busy")
+
+	// ErrExists is returned when adding an identity that is already stored, or creating an existing keystore
+	ErrExists = errors.New("already exists")
+
+	// ErrInvalidKeystore is returned for a file that is not a keystore
+	ErrInvalidKeystore = errors.New("invalid keystore")
+
+	// ErrLocked is returned when reading or changing identities of a locked keystore
+	ErrLocked = errors.New("keystore is locked")
+
+	// ErrNotFound is returned for an identity that is not stored
+	ErrNotFound = errors.New("identity not found")
+
+	// ErrWrongPassword is returned when the password does not decrypt the keystore
+	ErrWrongPassword = errors.New("wrong password")
+)
+
+// Attribute is an identity attribute and the secret its attestations are hashed with
+type Attribute struct {
+	Secret string `json:"secret"`
+	Value  string `json:"value"`
+}
+
+// Identity is a stored identity, the counter is the path (0/counter) of its current signing key
+type Identity struct {
+	Attributes map[string]*Attribute `json:"attributes,omitempty"` // By attribute name
+	Counter    uint32                `json:"counter"`
+	IDKey      string                `json:"id_key"`
+	Name       string                `json:"name,omitempty"` // Local label
+	RootKey    string                `json:"root_key"`       // xprv
+}
+
+// contents are the encrypted contents of a keystore
+type contents struct {
+	Identities []*Identity `json:"identities"`
+}
+
+// Keystore is a password-encrypted identity file, safe for concurrent use by goroutines and processes
+type Keystore struct {
+	key  []byte
+	mu   sync.Mutex
+	path string
+}
+
+// Create creates an empty keystore at path encrypted with the password, and returns it unlocked
+//
+// The default KDF parameters are used if params is nil.
+func Create(path, password string, params *KDFParams) (*Keystore, error) {
+	if len(password) == 0 {
+		return nil, &bap.MissingFieldError{Field: "password"}
+	} else if params == nil {
+		params = &DefaultKDFParams
+	}
+	derivation, err := newKDF(*params)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Keystore{key: derivation.deriveKey(password), path: path}
+	unlock, err := fileLock(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if _, err = os.Stat(path); err == nil {
+		return nil, fmt.Errorf("keystore %s %w", path, ErrExists)
+	}
+	if err = k.save(&envelope{Cipher: cipherName, KDF: derivation, Version: version}, &contents{}); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Open opens the keystore at path, locked
+func Open(path string) (*Keystore, error) {
+	k := &Keystore{path: path}
+	if _, err := k.read(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Path returns the path of the keystore file
+func (k *Keystore) Path() string {
+	return k.path
+}
+
+// Unlock derives the key from the password and keeps it in memory until Lock
+func (k *Keystore) Unlock(password string) error {
+	e, err := k.read()
+	if err != nil {
+		return err
+	}
+	key := e.KDF.deriveKey(password)
+	if _, err = e.open(key); err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.key = key
+	return nil
+}
+
+// Lock forgets the key, identities can no longer be read or changed until Unlock
+func (k *Keystore) Lock() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for index := range k.key {
+		k.key[index] = 0
+	}
+	k.key = nil
+}
+
+// Locked returns true if the keystore is locked
+func (k *Keystore) Locked() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.key == nil
+}
+
+// Identities returns every stored identity, sorted by id key
+func (k *Keystore) Identities() ([]*Identity, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	_, c, err := k.load()
+	if err != nil {
+		return nil, err
+	}
+	identities := make([]*Identity, 0, len(c.Identities))
+	for _, identity := range c.Identities {
+		identities = append(identities, identity.clone())
+	}
+	return identities, nil
+}
+
+// Identity returns a stored identity
+func (k *Keystore) Identity(idKey string) (*Identity, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	_, c, err := k.load()
+	if err != nil {
+		return nil, err
+	}
+	identity, err := c.identity(idKey)
+	if err != nil {
+		return nil, err
+	}
+	return identity.clone(), nil
+}
+
+// Add stores a new identity, its id key is derived from the root key if it is empty
+func (k *Keystore) Add(identity *Identity) (*Identity, error) {
+	if identity == nil {
+		return nil, &bap.MissingFieldError{Field: "identity"}
+	}
+	added := identity.clone()
+	if err := added.validate(); err != nil {
+		return nil, err
+	}
+	err := k.update(func(c *contents) error {
+		if _, err := c.identity(added.IDKey); err == nil {
+			return fmt.Errorf("identity %s %w", added.IDKey, ErrExists)
+		}
+		c.Identities = append(c.Identities, added)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added.clone(), nil
+}
+
+// Remove deletes a stored identity
+func (k *Keystore) Remove(idKey string) error {
+	return k.update(func(c *contents) error {
+		for index, identity := range c.Identities {
+			if identity.IDKey == idKey {
+				c.Identities = append(c.Identities[:index], c.Identities[index+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrNotFound, idKey)
+	})
+}
+
+// Update changes a stored identity, fn runs while the keystore is locked against other processes
+// and the change is saved unless it returns an error. The id key and root key cannot be changed.
+func (k *Keystore) Update(idKey string, fn func(identity *Identity) error) (*Identity, error) {
+	if fn == nil {
+		return nil, &bap.MissingFieldError{Field: "fn"}
+	}
+	var updated *Identity
+	err := k.update(func(c *contents) error {
+		identity, err := c.identity(idKey)
+		if err != nil {
+			return err
+		}
+		changed := identity.clone()
+		if err = fn(changed); err != nil {
+			return err
+		} else if changed.IDKey != identity.IDKey || changed.RootKey != identity.RootKey {
+			return errors.New("the id key and root key of an identity cannot be changed")
+		} else if err = changed.validate(); err != nil {
+			return err
+		}
+		*identity = *changed
+		updated = changed.clone()
+		return nil
+	})
+	return updated, err
+}
+
+// SetAttribute stores (or replaces) an attribute of an identity
+func (k *Keystore) SetAttribute(idKey, name string, attribute *Attribute) (*Identity, error) {
+	if len(name) == 0 {
+		return nil, &bap.MissingFieldError{Field: "name"}
+	} else if attribute == nil {
+		return nil, &bap.MissingFieldError{Field: "attribute"}
+	}
+	return k.Update(idKey, func(identity *Identity) error {
+		if identity.Attributes == nil {
+			identity.Attributes = make(map[string]*Attribute)
+		}
+		identity.Attributes[name] = &Attribute{Secret: attribute.Secret, Value: attribute.Value}
+		return nil
+	})
+}
+
+// AdvanceCounter moves an identity to its next signing path and returns the previous and next
+// counters, create the rotation with bap.CreateIdentityRotation(rootKey, idKey, previous)
+//
+// Concurrent callers, in any process, always get different counters.
+func (k *Keystore) AdvanceCounter(idKey string) (previous, next uint32, err error) {
+	_, err = k.Update(idKey, func(identity *Identity) error {
+		if identity.Counter == ^uint32(0) {
+			return fmt.Errorf("counter %d cannot be advanced", identity.Counter)
+		}
+		previous = identity.Counter
+		identity.Counter++
+		next = identity.Counter
+		return nil
+	})
+	return
+}
+
+// update will change the contents under the lock file and save them
+func (k *Keystore) update(fn func(c *contents) error) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.key == nil {
+		return ErrLocked
+	}
+
+	unlock, err := fileLock(k.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	e, c, err := k.load()
+	if err != nil {
+		return err
+	} else if err = fn(c); err != nil {
+		return err
+	}
+	return k.save(e, c)
+}
+
+// load will read and decrypt the keystore, the caller holds mu
+func (k *Keystore) load() (*envelope, *contents, error) {
+	if k.key == nil {
+		return nil, nil, ErrLocked
+	}
+	e, err := k.read()
+	if err != nil {
+		return nil, nil, err
+	}
+	var plaintext []byte
+	if plaintext, err = e.open(k.key); err != nil {
+		return nil, nil, err
+	}
+	c := new(contents)
+	if err = json.Unmarshal(plaintext, c); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidKeystore, err.Error())
+	}
+	return e, c, nil
+}
+
+// read will read the keystore file
+func (k *Keystore) read() (*envelope, error) {
+	raw, err := os.ReadFile(filepath.Clean(k.path))
+	if err != nil {
+		return nil, err
+	}
+	e := new(envelope)
+	if err = json.Unmarshal(raw, e); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeystore, err.Error())
+	} else if e.Version != version || e.Cipher != cipherName || e.KDF == nil || e.KDF.Name != kdfName {
+		return nil, fmt.Errorf("%w: unsupported format", ErrInvalidKeystore)
+	} else if err = e.KDF.validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// save will encrypt the contents and replace the keystore file atomically, readable by the owner only
+func (k *Keystore) save(e *envelope, c *contents) error {
+	sort.Slice(c.Identities, func(i, j int) bool {
+		return c.Identities[i].IDKey < c.Identities[j].IDKey
+	})
+	plaintext, err := json.Marshal(c)
+	if err != nil {
+		return err
+	} else if err = e.seal(k.key, plaintext); err != nil {
+		return err
+	}
+	var raw []byte
+	if raw, err = json.MarshalIndent(e, "", "  "); err != nil {
+		return err
+	}
+
+	var tmp *os.File
+	if tmp, err = os.CreateTemp(filepath.Dir(k.path), ".keystore-*"); err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err = tmp.Write(append(raw, '\n')); err == nil {
+		if err = tmp.Chmod(0o600); err == nil {
+			err = tmp.Sync()
+		}
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), k.path)
+}
+
+// identity returns a stored identity
+func (c *contents) identity(idKey string) (*Identity, error) {
+	for _, identity := range c.Identities {
+		if identity.IDKey == idKey {
+			return identity, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, idKey)
+}
+
+// validate checks the root key and attributes, and derives a missing id key from the root address (0/0)
+func (i *Identity) validate() error {
+	if len(i.RootKey) == 0 {
+		return &bap.MissingFieldError{Field: "rootKey"}
+	}
+	hdKey, err := hd.NewKeyFromString(i.RootKey)
+	if err != nil {
+		return err
+	} else if !hdKey.IsPrivate() {
+		return errors.New("root key is not a private key")
+	}
+	if len(i.IDKey) == 0 {
+		if i.IDKey, err = bap.IdentityKeyFromXPrivateKey(i.RootKey); err != nil {
+			return err
+		}
+	} else if err = bap.ValidateIDKey(i.IDKey); err != nil {
+		return err
+	}
+	for name, attribute := range i.Attributes {
+		if len(name) == 0 {
+			return &bap.MissingFieldError{Field: "attributeName"}
+		} else if attribute == nil || len(attribute.Secret) == 0 {
+			return &bap.MissingFieldError{Field: "identityAttributeSecret"}
+		}
+	}
+	return nil
+}
+
+// clone returns a deep copy of the identity
+func (i *Identity) clone() *Identity {
+	c := *i
+	if i.Attributes != nil {
+		c.Attributes = make(map[string]*Attribute, len(i.Attributes))
+		for name, attribute := range i.Attributes {
+			if attribute != nil {
+				copied := *attribute
+				attribute = &copied
+			}
+			c.Attributes[name] = attribute
+		}
+	}
+	return &c
+}
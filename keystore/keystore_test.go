@@ -0,0 +1,464 @@
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+)
+
+// Example keys
+const (
+	testAttestorKey = "xprv9s21ZrQH143K3PZSwbEeXEYq74EbnfMngzAiMCZcfjzyRpUvt2vQJnaHRTZjeuEmLXeN6BzYRoFsEckfobxE9XaRzeLGfQoxzPzTRyRb6oE"
+	testIdentityKey = "xprv9s21ZrQH143K2beTKhLXFRWWFwH8jkwUssjk3SVTiApgmge7kNC3jhVc4NgHW8PhW2y7BCDErqnKpKuyQMjqSePPJooPJowAz5BVLThsv6c"
+	testDerivedID   = "oqWsnpcTgXuEGSHRUGJUfY2518b"
+	testIDKey       = "8bafa4ca97d770276253585cb2a49da1775ec7aeed3178e346c8c1b55eaf5ca2"
+	testPassword    = "correct horse battery staple"
+	testPublicKey   = "xpub661MyMwAqRbcEj3kQ1G5zqykXYJdjcpLGRXCaQjehABtJ7nDodrn5igY9vSJiMcLPtyMaN2XrHqtsLeJ9p9fP5RgBhpc3bwaJGJ3aPdYiM6"
+)
+
+// testParams are cheap KDF parameters for tests
+var testParams = &KDFParams{Memory: 64, Threads: 1, Time: 1}
+
+// testKeystore creates an unlocked keystore in a temporary directory
+func testKeystore(t testing.TB) *Keystore {
+	k, err := Create(filepath.Join(t.TempDir(), "keystore.json"), testPassword, testParams)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return k
+}
+
+// TestCreate will test the method Create()
+func TestCreate(t *testing.T) {
+	t.Parallel()
+
+	k := testKeystore(t)
+	if _, err := k.Add(&Identity{RootKey: testIdentityKey, Attributes: map[string]*Attribute{"name": {Secret: "secret", Value: "John"}}}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Nothing is stored in clear text
+	raw, err := os.ReadFile(k.Path())
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if strings.Contains(string(raw), testIdentityKey[:20]) || strings.Contains(string(raw), "John") {
+		t.Fatalf("%s Failed: keystore is not encrypted: %s", t.Name(), raw)
+	}
+	var info os.FileInfo
+	if info, err = os.Stat(k.Path()); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if info.Mode().Perm() != 0o600 {
+		t.Fatalf("%s Failed: expected mode 0600 but got %v", t.Name(), info.Mode().Perm())
+	}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			path          string
+			password      string
+			params        *KDFParams
+			expectedError error
+		}{
+			{"existing keystore", k.Path(), testPassword, testParams, ErrExists},
+			{"no password", filepath.Join(t.TempDir(), "keystore.json"), "", testParams, bap.ErrMissingField},
+			{"no iterations", filepath.Join(t.TempDir(), "keystore.json"), testPassword, &KDFParams{Memory: 64, Threads: 1}, ErrInvalidKeystore},
+			{"no threads", filepath.Join(t.TempDir(), "keystore.json"), testPassword, &KDFParams{Memory: 64, Time: 1}, ErrInvalidKeystore},
+			{"too little memory", filepath.Join(t.TempDir(), "keystore.json"), testPassword, &KDFParams{Memory: 7, Threads: 1, Time: 1}, ErrInvalidKeystore},
+			{"too much memory", filepath.Join(t.TempDir(), "keystore.json"), testPassword, &KDFParams{Memory: maxMemory + 1, Threads: 1, Time: 1}, ErrInvalidKeystore},
+			{"too many iterations", filepath.Join(t.TempDir(), "keystore.json"), testPassword, &KDFParams{Memory: 64, Threads: 1, Time: maxTime + 1}, ErrInvalidKeystore},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err = Create(test.path, test.password, test.params); !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		}
+	}
+}
+
+// TestKeystore_Unlock will test the methods Unlock() and Lock()
+func TestKeystore_Unlock(t *testing.T) {
+	t.Parallel()
+
+	created := testKeystore(t)
+	if _, err := created.Add(&Identity{IDKey: testIDKey, RootKey: testIdentityKey}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	k, err := Open(created.Path())
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if !k.Locked() {
+		t.Fatalf("%s Failed: expected an opened keystore to be locked", t.Name())
+	}
+	if _, err = k.Identities(); !errors.Is(err, ErrLocked) {
+		t.Fatalf("%s Failed: expected ErrLocked but got: %v", t.Name(), err)
+	} else if _, err = k.Add(&Identity{RootKey: testAttestorKey}); !errors.Is(err, ErrLocked) {
+		t.Fatalf("%s Failed: expected ErrLocked but got: %v", t.Name(), err)
+	} else if err = k.Unlock("wrong password"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("%s Failed: expected ErrWrongPassword but got: %v", t.Name(), err)
+	} else if err = k.Unlock(testPassword); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	var identity *Identity
+	if identity, err = k.Identity(testIDKey); err != nil || identity.RootKey != testIdentityKey {
+		t.Fatalf("%s Failed: unexpected identity %+v %v", t.Name(), identity, err)
+	}
+
+	k.Lock()
+	if _, err = k.Identity(testIDKey); !errors.Is(err, ErrLocked) || !k.Locked() {
+		t.Fatalf("%s Failed: expected ErrLocked but got: %v", t.Name(), err)
+	}
+}
+
+// TestOpen will test the method Open() with invalid files
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	k := testKeystore(t)
+	raw, err := os.ReadFile(k.Path())
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err = os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+		return path
+	}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			path          string
+			expectedError error
+		}{
+			{"missing", filepath.Join(dir, "missing.json"), os.ErrNotExist},
+			{"not json", write("text.json", "keystore"), ErrInvalidKeystore},
+			{"no kdf", write("nokdf.json", `{"version":1,"cipher":"aes-256-gcm"}`), ErrInvalidKeystore},
+			{"unknown version", write("version.json", strings.Replace(string(raw), `"version": 1`, `"version": 2`, 1)), ErrInvalidKeystore},
+			{"too much memory", write("memory.json", strings.Replace(string(raw), `"memory": 64`, `"memory": 4294967295`, 1)), ErrInvalidKeystore},
+			{"too many iterations", write("time.json", strings.Replace(string(raw), `"time": 1`, `"time": 4294967295`, 1)), ErrInvalidKeystore},
+			{"too many threads", write("threads.json", strings.Replace(string(raw), `"threads": 1`, `"threads": 256`, 1)), ErrInvalidKeystore},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err = Open(test.path); !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		}
+	}
+
+	// A changed header no longer decrypts
+	tampered := strings.Replace(string(raw), `"time": 1`, `"time": 2`, 1)
+	var opened *Keystore
+	if opened, err = Open(write("tampered.json", tampered)); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if err = opened.Unlock(testPassword); !errors.Is(err, ErrWrongPassword) {
+		t.Fatalf("%s Failed: expected ErrWrongPassword but got: %v", t.Name(), err)
+	}
+}
+
+// TestKeystore_Add will test the method Add()
+func TestKeystore_Add(t *testing.T) {
+	t.Parallel()
+
+	k := testKeystore(t)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			identity      *Identity
+			expectedIDKey string
+			expectedError bool
+		}{
+			{"derived id key", &Identity{RootKey: testIdentityKey, Name: "personal"}, testDerivedID, false},
+			{"duplicate", &Identity{RootKey: testIdentityKey}, "", true},
+			{"explicit id key", &Identity{RootKey: testAttestorKey, IDKey: testIDKey, Counter: 3}, testIDKey, false},
+			{"nil", nil, "", true},
+			{"no root key", &Identity{IDKey: testIDKey}, "", true},
+			{"invalid root key", &Identity{RootKey: "xprv-invalid"}, "", true},
+			{"public root key", &Identity{RootKey: testPublicKey}, "", true},
+			{"invalid id key", &Identity{RootKey: testAttestorKey, IDKey: "invalid"}, "", true},
+			{"attribute without secret", &Identity{RootKey: testAttestorKey, Attributes: map[string]*Attribute{"name": {Value: "John"}}}, "", true},
+			{"nil attribute", &Identity{RootKey: testAttestorKey, Attributes: map[string]*Attribute{"name": nil}}, "", true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if added, err := k.Add(test.identity); err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		} else if err == nil && added.IDKey != test.expectedIDKey {
+			t.Errorf("%s Failed: [%s] inputted and expected id key [%s] but got [%s]", t.Name(), test.name, test.expectedIDKey, added.IDKey)
+		}
+	}
+
+	identities, err := k.Identities()
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(identities) != 2 || identities[0].IDKey != testIDKey || identities[0].Counter != 3 || identities[1].Name != "personal" {
+		t.Fatalf("%s Failed: unexpected identities %+v", t.Name(), identities)
+	}
+}
+
+// TestKeystore_Update will test the methods Update(), SetAttribute() and Remove()
+func TestKeystore_Update(t *testing.T) {
+	t.Parallel()
+
+	k := testKeystore(t)
+	if _, err := k.Add(&Identity{IDKey: testIDKey, RootKey: testIdentityKey}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	identity, err := k.SetAttribute(testIDKey, "name", &Attribute{Secret: "secret", Value: "John"})
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if identity.Attributes["name"].Value != "John" {
+		t.Fatalf("%s Failed: unexpected attributes %+v", t.Name(), identity.Attributes)
+	}
+
+	// Returned identities are copies
+	identity.Attributes["name"].Value = "Jane"
+	if identity, err = k.Identity(testIDKey); err != nil || identity.Attributes["name"].Value != "John" {
+		t.Fatalf("%s Failed: unexpected identity %+v %v", t.Name(), identity, err)
+	}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			idKey         string
+			fn            func(identity *Identity) error
+			expectedError bool
+		}{
+			{"label", testIDKey, func(identity *Identity) error { identity.Name = "work"; return nil }, false},
+			{"unknown identity", testDerivedID, func(*Identity) error { return nil }, true},
+			{"nil fn", testIDKey, nil, true},
+			{"fn error", testIDKey, func(identity *Identity) error { identity.Name = "lost"; return errors.New("failed") }, true},
+			{"root key change", testIDKey, func(identity *Identity) error { identity.RootKey = testAttestorKey; return nil }, true},
+			{"id key change", testIDKey, func(identity *Identity) error { identity.IDKey = ""; return nil }, true},
+			{"invalid attribute", testIDKey, func(identity *Identity) error { identity.Attributes["age"] = &Attribute{}; return nil }, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err = k.Update(test.idKey, test.fn); err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		}
+	}
+	if identity, err = k.Identity(testIDKey); err != nil || identity.Name != "work" || len(identity.Attributes) != 1 {
+		t.Fatalf("%s Failed: failed updates were saved: %+v %v", t.Name(), identity, err)
+	}
+
+	if _, err = k.SetAttribute(testIDKey, "", &Attribute{Secret: "secret"}); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("%s Failed: expected ErrMissingField but got: %v", t.Name(), err)
+	} else if err = k.Remove(testIDKey); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if _, err = k.Identity(testIDKey); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("%s Failed: expected ErrNotFound but got: %v", t.Name(), err)
+	} else if err = k.Remove(testIDKey); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("%s Failed: expected ErrNotFound but got: %v", t.Name(), err)
+	}
+}
+
+// TestKeystore_AdvanceCounter will test that concurrent processes never share a counter
+func TestKeystore_AdvanceCounter(t *testing.T) {
+	t.Parallel()
+
+	first := testKeystore(t)
+	if _, err := first.Add(&Identity{IDKey: testIDKey, RootKey: testIdentityKey}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// A second handle on the same file stands in for another process
+	second, err := Open(first.Path())
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if err = second.Unlock(testPassword); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	const rotations = 20
+	var (
+		mu   sync.Mutex
+		seen = make(map[uint32]bool)
+		wg   sync.WaitGroup
+	)
+	for index := 0; index < rotations; index++ {
+		k := first
+		if index%2 == 1 {
+			k = second
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			previous, next, advanceErr := k.AdvanceCounter(testIDKey)
+			mu.Lock()
+			defer mu.Unlock()
+			if advanceErr != nil {
+				t.Errorf("error occurred: %s", advanceErr.Error())
+			} else if next != previous+1 || seen[next] {
+				t.Errorf("%s Failed: counter %d was handed out twice", t.Name(), next)
+			}
+			seen[next] = true
+		}()
+	}
+	wg.Wait()
+
+	var identity *Identity
+	if identity, err = second.Identity(testIDKey); err != nil || identity.Counter != rotations {
+		t.Fatalf("%s Failed: expected counter %d but got %+v %v", t.Name(), rotations, identity, err)
+	}
+}
+
+// TestKeystore_CounterStore will test rotating a bap.Identity with the keystore as its counter store
+func TestKeystore_CounterStore(t *testing.T) {
+	t.Parallel()
+
+	k := testKeystore(t)
+	if _, err := k.Add(&Identity{IDKey: testIDKey, RootKey: testIdentityKey, Counter: 4}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// The identity was loaded before another rotation
+	identity, err := bap.NewIdentity(testIdentityKey, testIDKey, 2)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	identity.SetCounterStore(k)
+	if _, err = identity.Rotate(); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var stored *Identity
+	if stored, err = k.Identity(testIDKey); err != nil || stored.Counter != 5 || identity.Counter() != 5 {
+		t.Fatalf("%s Failed: expected counter 5 but got %+v %d %v", t.Name(), stored, identity.Counter(), err)
+	}
+}
+
+// TestFileLock will test stale and held lock files
+func TestFileLock(t *testing.T) {
+	k := testKeystore(t)
+	if _, err := k.Add(&Identity{IDKey: testIDKey, RootKey: testIdentityKey}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// A lock held by another process
+	defer func(timeout time.Duration) {
+		LockTimeout = timeout
+	}(LockTimeout)
+	LockTimeout = 50 * time.Millisecond
+	lockPath := k.Path() + ".lock"
+	if err := os.WriteFile(lockPath, []byte("1\n"), 0o600); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, _, err := k.AdvanceCounter(testIDKey); !errors.Is(err, ErrBusy) {
+		t.Fatalf("%s Failed: expected ErrBusy but got: %v", t.Name(), err)
+	}
+
+	// A lock left behind by a crashed process
+	stale := time.Now().Add(-2 * StaleLockAge)
+	if err := os.Chtimes(lockPath, stale, stale); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, next, err := k.AdvanceCounter(testIDKey); err != nil || next != 1 {
+		t.Fatalf("%s Failed: expected counter 1 but got %d %v", t.Name(), next, err)
+	} else if _, err = os.Stat(lockPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("%s Failed: expected the lock file to be removed but got: %v", t.Name(), err)
+	} else if matches, _ := filepath.Glob(lockPath + ".*"); len(matches) > 0 {
+		t.Fatalf("%s Failed: expected no stale lock files but got: %v", t.Name(), matches)
+	}
+
+	// A lock taken over by another process is not removed by the previous owner
+	unlock, err := fileLock(k.Path())
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if err = os.WriteFile(lockPath, []byte("1 other\n"), 0o600); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	unlock()
+	if owner, readErr := os.ReadFile(lockPath); readErr != nil || string(owner) != "1 other\n" {
+		t.Fatalf("%s Failed: expected the lock of another process to be kept but got: %q %v", t.Name(), owner, readErr)
+	}
+	if err = os.Remove(lockPath); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// The lock file holds the owner, and is removed on unlock
+	if unlock, err = fileLock(k.Path()); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if owner, readErr := os.ReadFile(lockPath); readErr != nil || !strings.HasPrefix(string(owner), fmt.Sprintf("%d ", os.Getpid())) {
+		t.Fatalf("%s Failed: expected the lock file to hold the process id but got: %q %v", t.Name(), owner, readErr)
+	}
+	unlock()
+	if _, err = os.Stat(lockPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("%s Failed: expected the lock file to be removed but got: %v", t.Name(), err)
+	}
+}
+
+// ExampleKeystore_AdvanceCounter example using AdvanceCounter()
+func ExampleKeystore_AdvanceCounter() {
+	dir, err := os.MkdirTemp("", "keystore")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	var k *Keystore
+	if k, err = Create(filepath.Join(dir, "keystore.json"), "password", nil); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	var identity *Identity
+	if identity, err = k.Add(&Identity{RootKey: testIdentityKey}); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	previous, next, err := k.AdvanceCounter(identity.IDKey)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	if _, err = bap.CreateIdentityRotation(identity.RootKey, identity.IDKey, previous); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("rotated %s from 0/%d to 0/%d", identity.IDKey[:8], previous, next)
+	// Output:rotated oqWsnpcT from 0/0 to 0/1
+}
+
+// BenchmarkKeystore_AdvanceCounter benchmarks the method AdvanceCounter()
+func BenchmarkKeystore_AdvanceCounter(b *testing.B) {
+	k := testKeystore(b)
+	if _, err := k.Add(&Identity{IDKey: testIDKey, RootKey: testIdentityKey}); err != nil {
+		b.Fatalf("error occurred: %s", err.Error())
+	}
+	for i := 0; i < b.N; i++ {
+		_, _, _ = k.AdvanceCounter(testIDKey)
+	}
+}
@@ -0,0 +1,107 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Lock file timings
+var (
+	// LockTimeout is how long an update waits for another process to release the keystore
+	LockTimeout = 10 * time.Second
+
+	// StaleLockAge is the age after which a lock file left by a crashed process is removed
+	StaleLockAge = 2 * time.Minute
+
+	// lockRetry is the delay between attempts to take the lock
+	lockRetry = 10 * time.Millisecond
+)
+
+// fileLock takes the lock file of a keystore, so a single process updates it at a time
+//
+// The lock file is created exclusively (O_EXCL), which works on every platform and file system.
+// It holds the process id and a random token: unlock only removes the lock file while it still
+// holds the token, so a lock taken over by another process is never removed.
+func fileLock(path string) (unlock func(), err error) {
+	var id string
+	if id, err = randomID(); err != nil {
+		return nil, err
+	}
+	token := []byte(fmt.Sprintf("%d %s\n", os.Getpid(), id))
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(LockTimeout)
+	for {
+		var file *os.File
+		if file, err = os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600); err == nil {
+			_, err = file.Write(token)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(lockPath)
+				return nil, err
+			}
+			return func() {
+				if owner, readErr := os.ReadFile(lockPath); readErr == nil && bytes.Equal(owner, token) {
+					_ = os.Remove(lockPath)
+				}
+			}, nil
+		} else if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if removeStaleLock(lockPath, id) {
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w: %s", ErrBusy, lockPath)
+		}
+		time.Sleep(lockRetry)
+	}
+}
+
+// randomID returns a random hex id
+func randomID() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
+
+// removeStaleLock removes a lock file left behind by a crashed process, it reports whether it did
+//
+// The lock file is renamed aside before it is removed, and put back unless it still holds the
+// owner read when it was found stale: another process removing the same stale lock and taking
+// a new one meanwhile keeps its lock.
+func removeStaleLock(lockPath, id string) bool {
+	info, err := os.Stat(lockPath)
+	if err != nil || time.Since(info.ModTime()) <= StaleLockAge {
+		return false
+	}
+	var owner []byte
+	if owner, err = os.ReadFile(lockPath); err != nil {
+		return false
+	}
+
+	aside := lockPath + "." + id + ".stale"
+	if err = os.Rename(lockPath, aside); err != nil {
+		return false
+	}
+	defer func() {
+		_ = os.Remove(aside)
+	}()
+	moved, err := os.ReadFile(aside)
+	if err == nil && bytes.Equal(moved, owner) {
+		return true
+	}
+
+	// Another process took the lock meanwhile: give it back unless a new lock was taken since
+	_ = os.Link(aside, lockPath)
+	return false
+}
@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+)
+
+// ErrNotAccepted is returned when no acceptable attestor attested the attribute
+var ErrNotAccepted = errors.New("attribute is not attested by an acceptable attestor")
+
+// Rejection is an attestation of the attribute that was not acceptable, and why
+type Rejection struct {
+	Address       string `json:"address"` // Address that signed the attestation
+	AttestorIDKey string `json:"attestor_id_key,omitempty"`
+	Reason        string `json:"reason"`
+	TxID          string `json:"txid"`
+}
+
+// Decision is the outcome of evaluating an attribute against the registry
+type Decision struct {
+	Accepted    bool                       `json:"accepted"`
+	Attestation *indexer.AttestationRecord `json:"attestation,omitempty"` // The accepted attestation
+	Attestor    *Attestor                  `json:"attestor,omitempty"`    // The attestor of the accepted attestation
+	Rejections  []*Rejection               `json:"rejections,omitempty"`  // Attestations that were not acceptable
+	Status      *bap.Status                `json:"status,omitempty"`      // Status of the accepted attestation
+	URNHash     string                     `json:"urn_hash"`
+}
+
+// Evaluator answers whether an attribute of an identity is attested by an acceptable attestor,
+// from the ATTEST records of an indexer Store
+type Evaluator struct {
+	query    *indexer.Query
+	registry *Registry
+}
+
+// NewEvaluator returns an evaluator of the registry over the store
+func NewEvaluator(store indexer.Store, registry *Registry) *Evaluator {
+	return &Evaluator{query: indexer.NewQuery(store), registry: registry}
+}
+
+// Evaluate checks the ATTEST records of the attribute in chain order and accepts the first one
+// that was signed by a trusted attestor (the indexer links a signing address to an identity
+// only while it is the identity's current address), that may attest the attribute name, that
+// was mined in one of its validity windows and is still active (not revoked, superseded or
+// expired, at the highest indexed block and the current time). ErrNotAccepted is returned
+// with the decision, listing the rejections, if there is no acceptable attestation.
+func (e *Evaluator) Evaluate(attribute *bap.AttestationRequest) (*Decision, error) {
+	return e.evaluate(attribute, func(urnHash, attestorIDKey string) (*bap.Status, error) {
+		return e.query.AttestationStatus(urnHash, attestorIDKey, "")
+	})
+}
+
+// EvaluateAt evaluates the attribute (see Evaluate) with expiry checked at the given chain height and time
+func (e *Evaluator) EvaluateAt(attribute *bap.AttestationRequest, height uint32, now time.Time) (*Decision, error) {
+	return e.evaluate(attribute, func(urnHash, attestorIDKey string) (*bap.Status, error) {
+		return e.query.AttestationStatusAt(urnHash, attestorIDKey, "", height, now)
+	})
+}
+
+// evaluate checks the attestations of the attribute, status returns the current status of an attestor's attestation
+func (e *Evaluator) evaluate(attribute *bap.AttestationRequest,
+	status func(urnHash, attestorIDKey string) (*bap.Status, error)) (*Decision, error) {
+	if attribute == nil {
+		return nil, &bap.MissingFieldError{Field: "attribute"}
+	} else if len(attribute.IDKey) == 0 {
+		return nil, &bap.MissingFieldError{Field: "idKey"}
+	} else if len(attribute.AttributeName) == 0 {
+		return nil, &bap.MissingFieldError{Field: "attributeName"}
+	} else if e.registry == nil {
+		return nil, &bap.MissingFieldError{Field: "registry"}
+	}
+
+	hash := bap.AttestationHash(attribute.IDKey, attribute.AttributeName, attribute.AttributeValue, attribute.IdentityAttributeSecret)
+	decision := &Decision{URNHash: hex.EncodeToString(hash[:])}
+	records, _, err := e.query.Attestations(indexer.AttestationFilter{
+		Type:      bap.ATTEST,
+		URNHashes: []string{decision.URNHash},
+	}, indexer.Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, record := range records {
+		reject := func(reason string) {
+			decision.Rejections = append(decision.Rejections, &Rejection{
+				Address:       record.Address,
+				AttestorIDKey: record.AttestorIDKey,
+				Reason:        reason,
+				TxID:          record.TxID,
+			})
+		}
+		if len(record.AttestorIDKey) == 0 {
+			reject("signed by an address of no known identity")
+			continue
+		} else if seen[record.AttestorIDKey] {
+			continue
+		}
+		seen[record.AttestorIDKey] = true
+
+		attestor, ok := e.registry.Attestor(record.AttestorIDKey)
+		if !ok {
+			reject("not a trusted attestor")
+			continue
+		} else if !attestor.AllowsAttribute(attribute.AttributeName) {
+			reject(fmt.Sprintf("not allowed to attest %s", attribute.AttributeName))
+			continue
+		}
+
+		// The current attestation of the attestor must be active and within a window
+		var current *bap.Status
+		if current, err = status(decision.URNHash, record.AttestorIDKey); err != nil {
+			return nil, err
+		} else if current.Status != bap.StatusActive {
+			reject(fmt.Sprintf("attestation is %s", current.Status))
+			continue
+		}
+		active := record
+		for _, other := range records {
+			if other.TxID == current.Attestation.TxID && other.AttestorIDKey == record.AttestorIDKey {
+				active = other
+				break
+			}
+		}
+		if !attestor.AllowsBlock(active.Block) {
+			reject("attested outside the validity windows")
+			continue
+		}
+
+		decision.Accepted, decision.Attestation, decision.Attestor, decision.Status = true, active, attestor, current
+		return decision, nil
+	}
+	return decision, ErrNotAccepted
+}
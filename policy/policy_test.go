@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	"github.com/bitcoinschema/go-bap/internal/testutil/testindex"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// testAttribute returns an attribute of the test identity
+func testAttribute(name, value string) *bap.AttestationRequest {
+	return &bap.AttestationRequest{IDKey: testutil.IDKey, AttributeName: name, AttributeValue: value, IdentityAttributeSecret: testindex.Secret}
+}
+
+// testEvaluator indexes three identities and attestations of the test identity's attributes:
+// the registry trusts the attestor for names (heights 100 to 200) and the identity for emails
+func testEvaluator(t testing.TB) *Evaluator {
+	idx := testindex.New(t, testutil.AttestorID, testutil.IDKey, testutil.OtherID)
+	attestorKey, _ := testutil.SigningKey(t, testutil.AttestorKey, 0)
+	identityKey, _ := testutil.SigningKey(t, testutil.IdentityKey, 0)
+	otherKey, _ := testutil.SigningKey(t, testutil.OtherKey, 0)
+	anonymousKey, _ := testutil.SigningKey(t, testutil.OtherKey, 9)
+
+	txs := map[uint32]*transaction.Transaction{
+		110: testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.IDKey, "name", "John", "0"),
+		111: testindex.Attestation(t, otherKey, bap.ATTEST, testutil.IDKey, "name", "untrusted", "0"),
+		112: testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.IDKey, "name", "revoked", "0"),
+		113: testindex.Attestation(t, attestorKey, bap.REVOKE, testutil.IDKey, "name", "revoked", "1"),
+		114: testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.IDKey, "email", "john@example.com", "0"),
+		115: testindex.Attestation(t, identityKey, bap.ATTEST, testutil.IDKey, "email", "john@example.com", "0"),
+		116: testindex.Attestation(t, anonymousKey, bap.ATTEST, testutil.IDKey, "name", "anonymous", "0"),
+		117: testindex.Attestation(t, otherKey, bap.ATTEST, testutil.IDKey, "name", "multi", "0"),
+		118: testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.IDKey, "name", "multi", "0"),
+		250: testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.IDKey, "name", "late", "0"),
+	}
+	var err error
+	if txs[119], err = bap.CreateAttestationWithExpiry(testutil.IDKey, attestorKey, "name", "expiring", testindex.Secret, 150); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	testindex.AddHeights(t, idx, txs)
+
+	var registry *Registry
+	if registry, err = LoadRegistry("testdata/registry.json"); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return NewEvaluator(idx.Store(), registry)
+}
+
+// TestEvaluator_EvaluateAt will test the method EvaluateAt()
+func TestEvaluator_EvaluateAt(t *testing.T) {
+	t.Parallel()
+
+	evaluator := testEvaluator(t)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name             string
+			attribute        *bap.AttestationRequest
+			expectedAttestor string
+			expectedReasons  []string
+		}{
+			{"trusted attestor", testAttribute("name", "John"), testutil.AttestorID, nil},
+			{"untrusted attestor", testAttribute("name", "untrusted"), "", []string{"not a trusted attestor"}},
+			{"revoked", testAttribute("name", "revoked"), "", []string{"attestation is revoked"}},
+			{"attribute not allowed", testAttribute("email", "john@example.com"), testutil.IDKey, []string{"not allowed to attest email"}},
+			{"unknown signer", testAttribute("name", "anonymous"), "", []string{"signed by an address of no known identity"}},
+			{"untrusted then trusted", testAttribute("name", "multi"), testutil.AttestorID, []string{"not a trusted attestor"}},
+			{"outside the window", testAttribute("name", "late"), "", []string{"attested outside the validity windows"}},
+			{"expired", testAttribute("name", "expiring"), "", []string{"attestation is expired"}},
+			{"not attested", testAttribute("name", "Jane"), "", nil},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		decision, err := evaluator.EvaluateAt(test.attribute, 300, time.Now())
+		if len(test.expectedAttestor) > 0 && err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		} else if len(test.expectedAttestor) == 0 && !errors.Is(err, ErrNotAccepted) {
+			t.Errorf("%s Failed: [%s] inputted and expected ErrNotAccepted but got [%v]", t.Name(), test.name, err)
+			continue
+		}
+		reasons := make([]string, len(decision.Rejections))
+		for index, rejection := range decision.Rejections {
+			reasons[index] = rejection.Reason
+		}
+		if decision.Accepted != (len(test.expectedAttestor) > 0) || fmt.Sprint(reasons) != fmt.Sprint(test.expectedReasons) {
+			t.Errorf("%s Failed: [%s] inputted and expected reasons %v but got %v", t.Name(), test.name, test.expectedReasons, reasons)
+		} else if decision.Accepted && (decision.Attestor.IDKey != test.expectedAttestor || decision.Attestation.AttestorIDKey != test.expectedAttestor ||
+			decision.Status.Status != bap.StatusActive) {
+			t.Errorf("%s Failed: [%s] inputted and unexpected decision: %+v", t.Name(), test.name, decision)
+		}
+	}
+}
+
+// TestEvaluator_Evaluate will test the method Evaluate()
+func TestEvaluator_Evaluate(t *testing.T) {
+	t.Parallel()
+
+	evaluator := testEvaluator(t)
+	if decision, err := evaluator.Evaluate(testAttribute("name", "John")); err != nil || !decision.Accepted {
+		t.Fatalf("%s Failed: expected an accepted attribute but got: %+v %v", t.Name(), decision, err)
+	}
+
+	// The highest indexed block (250) is past the expiry
+	if _, err := evaluator.Evaluate(testAttribute("name", "expiring")); !errors.Is(err, ErrNotAccepted) {
+		t.Fatalf("%s Failed: expected ErrNotAccepted but got: %v", t.Name(), err)
+	}
+
+	for _, attribute := range []*bap.AttestationRequest{nil, {AttributeName: "name"}, {IDKey: testutil.IDKey}} {
+		if _, err := evaluator.Evaluate(attribute); !errors.Is(err, bap.ErrMissingField) {
+			t.Fatalf("%s Failed: expected ErrMissingField but got: %v", t.Name(), err)
+		}
+	}
+}
+
+// ExampleEvaluator_Evaluate example using Evaluate()
+func ExampleEvaluator_Evaluate() {
+	registry, err := LoadRegistry("testdata/registry.json")
+	if err != nil {
+		fmt.Printf("failed to load registry: %s", err.Error())
+		return
+	}
+	evaluator := NewEvaluator(indexer.NewMemoryStore(), registry)
+	_, err = evaluator.Evaluate(testAttribute("name", "John"))
+	fmt.Printf("error: %s", err.Error())
+	// Output:error: attribute is not attested by an acceptable attestor
+}
+
+// BenchmarkEvaluator_Evaluate benchmarks the method Evaluate()
+func BenchmarkEvaluator_Evaluate(b *testing.B) {
+	evaluator := testEvaluator(b)
+	attribute := testAttribute("name", "John")
+	for i := 0; i < b.N; i++ {
+		_, _ = evaluator.Evaluate(attribute)
+	}
+}
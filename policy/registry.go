@@ -0,0 +1,164 @@
+// Package policy decides whether an attribute of an identity is attested by an acceptable
+// attestor: a Registry of trusted attestors (id keys, allowed attribute names and validity
+// windows), loaded from a JSON config file, is evaluated against the ATTEST records of an
+// indexer Store and the identities that signed them
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+)
+
+// AnyAttribute allows an attestor to attest any attribute name
+const AnyAttribute = "*"
+
+// Window limits when an attestor's attestations are accepted, by the block height and time they
+// were mined at (zero fields are unbounded, bounds are inclusive)
+type Window struct {
+	FromHeight uint32 `json:"from_height,omitempty"`
+	FromTime   uint32 `json:"from_time,omitempty"` // Unix time
+	ToHeight   uint32 `json:"to_height,omitempty"`
+	ToTime     uint32 `json:"to_time,omitempty"` // Unix time
+}
+
+// Attestor is a trusted attestor of the registry
+type Attestor struct {
+	Attributes []string `json:"attributes"` // Attribute names it may attest, or AnyAttribute
+	IDKey      string   `json:"id_key"`
+	Name       string   `json:"name,omitempty"`
+	Windows    []Window `json:"windows,omitempty"` // Accepted if any window matches, always if empty
+}
+
+// Registry is the set of trusted attestors, by id key
+type Registry struct {
+	attestors map[string]*Attestor
+	order     []string
+}
+
+// registryConfig is the config file format
+type registryConfig struct {
+	Attestors []*Attestor `json:"attestors"`
+}
+
+// NewRegistry returns a registry of the attestors
+func NewRegistry(attestors ...*Attestor) (*Registry, error) {
+	r := &Registry{attestors: make(map[string]*Attestor, len(attestors))}
+	for index, attestor := range attestors {
+		if attestor == nil {
+			return nil, &bap.MissingFieldError{Field: fmt.Sprintf("attestors[%d]", index)}
+		} else if err := attestor.Validate(); err != nil {
+			return nil, fmt.Errorf("attestor %d: %w", index, err)
+		} else if _, ok := r.attestors[attestor.IDKey]; ok {
+			return nil, fmt.Errorf("attestor %d: duplicate id key %s", index, attestor.IDKey)
+		}
+		r.attestors[attestor.IDKey] = attestor
+		r.order = append(r.order, attestor.IDKey)
+	}
+	return r, nil
+}
+
+// ReadRegistry reads a JSON registry config:
+//
+//	{"attestors": [{"id_key": "...", "name": "...", "attributes": ["name", "email"],
+//	  "windows": [{"from_height": 800000, "to_height": 900000}]}]}
+func ReadRegistry(r io.Reader) (*Registry, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var config registryConfig
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid registry config: %w", err)
+	}
+	return NewRegistry(config.Attestors...)
+}
+
+// LoadRegistry reads a JSON registry config file, see ReadRegistry
+func LoadRegistry(path string) (*Registry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return ReadRegistry(file)
+}
+
+// Attestor returns the trusted attestor with the id key
+func (r *Registry) Attestor(idKey string) (*Attestor, bool) {
+	attestor, ok := r.attestors[idKey]
+	return attestor, ok
+}
+
+// Attestors returns the trusted attestors in config order
+func (r *Registry) Attestors() []*Attestor {
+	attestors := make([]*Attestor, len(r.order))
+	for index, idKey := range r.order {
+		attestors[index] = r.attestors[idKey]
+	}
+	return attestors
+}
+
+// MarshalJSON writes the registry in the config file format
+func (r *Registry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&registryConfig{Attestors: r.Attestors()})
+}
+
+// Validate checks the id key, the attribute names and the windows of the attestor
+func (a *Attestor) Validate() error {
+	if err := bap.ValidateIDKey(a.IDKey); err != nil {
+		return err
+	} else if len(a.Attributes) == 0 {
+		return &bap.MissingFieldError{Field: "attributes"}
+	}
+	for _, attribute := range a.Attributes {
+		if len(attribute) == 0 {
+			return &bap.MissingFieldError{Field: "attributes"}
+		}
+	}
+	for index, window := range a.Windows {
+		if (window.ToHeight > 0 && window.FromHeight > window.ToHeight) || (window.ToTime > 0 && window.FromTime > window.ToTime) {
+			return fmt.Errorf("window %d ends before it starts", index)
+		}
+	}
+	return nil
+}
+
+// AllowsAttribute returns true if the attestor may attest the attribute name
+func (a *Attestor) AllowsAttribute(attributeName string) bool {
+	for _, attribute := range a.Attributes {
+		if attribute == AnyAttribute || attribute == attributeName {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsBlock returns true if an attestation mined in the block falls in a validity window.
+// Unconfirmed attestations (height 0) only match windows without bounds.
+func (a *Attestor) AllowsBlock(block indexer.Block) bool {
+	if len(a.Windows) == 0 {
+		return true
+	}
+	for _, window := range a.Windows {
+		if window.contains(block) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns true if the block is within the window
+func (w Window) contains(block indexer.Block) bool {
+	if (w.FromHeight > 0 || w.ToHeight > 0) && block.Height == 0 {
+		return false
+	} else if (w.FromTime > 0 || w.ToTime > 0) && block.Time == 0 {
+		return false
+	}
+	return block.Height >= w.FromHeight && (w.ToHeight == 0 || block.Height <= w.ToHeight) &&
+		block.Time >= w.FromTime && (w.ToTime == 0 || block.Time <= w.ToTime)
+}
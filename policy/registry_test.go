@@ -0,0 +1,123 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap/indexer"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+)
+
+// TestLoadRegistry will test the method LoadRegistry()
+func TestLoadRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry, err := LoadRegistry("testdata/registry.json")
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	attestors := registry.Attestors()
+	if len(attestors) != 2 || attestors[0].IDKey != testutil.AttestorID || attestors[1].IDKey != testutil.IDKey {
+		t.Fatalf("%s Failed: unexpected attestors: %+v", t.Name(), attestors)
+	}
+	if attestor, ok := registry.Attestor(testutil.AttestorID); !ok || attestor.Name != "Example KYC Provider" || len(attestor.Windows) != 1 {
+		t.Fatalf("%s Failed: unexpected attestor: %+v", t.Name(), attestor)
+	}
+	if _, ok := registry.Attestor("unknown"); ok {
+		t.Fatalf("%s Failed: expected no attestor", t.Name())
+	}
+
+	// The config round trips
+	var raw []byte
+	if raw, err = json.Marshal(registry); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var decoded *Registry
+	if decoded, err = ReadRegistry(strings.NewReader(string(raw))); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(decoded.Attestors()) != 2 {
+		t.Fatalf("%s Failed: unexpected round trip: %s", t.Name(), raw)
+	}
+
+	if _, err = LoadRegistry("testdata/missing.json"); err == nil {
+		t.Fatalf("%s Failed: expected an error for a missing file", t.Name())
+	}
+}
+
+// TestReadRegistry will test the method ReadRegistry()
+func TestReadRegistry(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			config        string
+			expectedError bool
+		}{
+			{"valid", fmt.Sprintf(`{"attestors":[{"id_key":"%s","attributes":["*"]}]}`, testutil.AttestorID), false},
+			{"empty", `{"attestors":[]}`, false},
+			{"not json", `attestors`, true},
+			{"unknown field", fmt.Sprintf(`{"attestors":[{"id_key":"%s","attributes":["*"],"trust":1}]}`, testutil.AttestorID), true},
+			{"invalid id key", `{"attestors":[{"id_key":"bad","attributes":["*"]}]}`, true},
+			{"no attributes", fmt.Sprintf(`{"attestors":[{"id_key":"%s"}]}`, testutil.AttestorID), true},
+			{"empty attribute", fmt.Sprintf(`{"attestors":[{"id_key":"%s","attributes":[""]}]}`, testutil.AttestorID), true},
+			{"duplicate", fmt.Sprintf(`{"attestors":[{"id_key":"%[1]s","attributes":["*"]},{"id_key":"%[1]s","attributes":["*"]}]}`, testutil.AttestorID), true},
+			{"backwards window", fmt.Sprintf(`{"attestors":[{"id_key":"%s","attributes":["*"],"windows":[{"from_height":10,"to_height":5}]}]}`, testutil.AttestorID), true},
+			{"null attestor", `{"attestors":[null]}`, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err := ReadRegistry(strings.NewReader(test.config)); err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		}
+	}
+}
+
+// TestAttestor_Allows will test the methods AllowsAttribute() and AllowsBlock()
+func TestAttestor_Allows(t *testing.T) {
+	t.Parallel()
+
+	attestor := &Attestor{
+		Attributes: []string{"name", "email"},
+		IDKey:      testutil.AttestorID,
+		Windows:    []Window{{FromHeight: 100, ToHeight: 200}, {FromTime: 1700000000}},
+	}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name     string
+			block    indexer.Block
+			expected bool
+		}{
+			{"in height window", indexer.Block{Height: 150}, true},
+			{"first height", indexer.Block{Height: 100}, true},
+			{"last height", indexer.Block{Height: 200}, true},
+			{"after height window", indexer.Block{Height: 201, Time: 1600000000}, false},
+			{"in time window", indexer.Block{Height: 300, Time: 1700000000}, true},
+			{"unconfirmed", indexer.Block{}, false},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if allowed := attestor.AllowsBlock(test.block); allowed != test.expected {
+			t.Errorf("%s Failed: [%s] inputted and expected [%t] but got [%t]", t.Name(), test.name, test.expected, allowed)
+		}
+	}
+
+	if !attestor.AllowsAttribute("email") || attestor.AllowsAttribute("age") {
+		t.Fatalf("%s Failed: unexpected allowed attributes", t.Name())
+	} else if !(&Attestor{Attributes: []string{AnyAttribute}}).AllowsAttribute("age") {
+		t.Fatalf("%s Failed: expected any attribute to be allowed", t.Name())
+	} else if !(&Attestor{}).AllowsBlock(indexer.Block{}) {
+		t.Fatalf("%s Failed: expected any block without windows", t.Name())
+	}
+}
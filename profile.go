@@ -0,0 +1,335 @@
+package bap
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ProfileType is the schema.org type of a profile
+type ProfileType string
+
+// Profile types and context
+const (
+	ProfileContext                  = "https://schema.org"
+	ProfileOrganization ProfileType = "Organization"
+	ProfilePerson       ProfileType = "Person"
+)
+
+const (
+	// bScheme is the URI scheme of on-chain B:// content
+	bScheme = "b://"
+
+	// maxDisplayName is the maximum length of a display name in characters
+	maxDisplayName = 256
+)
+
+// Profile is the schema.org Person or Organization published by an ALIAS record. The
+// fields are as published, use the accessors for values that are safe to display or follow.
+type Profile struct {
+	Context       string      `json:"@context,omitempty"`
+	Type          ProfileType `json:"@type,omitempty"`
+	AlternateName string      `json:"alternateName,omitempty"`
+	Description   string      `json:"description,omitempty"`
+	Image         string      `json:"image,omitempty"`
+	Logo          string      `json:"logo,omitempty"` // Organizations
+	Name          string      `json:"name,omitempty"`
+	Paymail       string      `json:"paymail,omitempty"`
+	URL           string      `json:"url,omitempty"`
+
+	// Fields holds every published field, including fields not mapped above
+	Fields map[string]json.RawMessage `json:"-"`
+}
+
+// ContentRef is a reference to profile media: a web URL or on-chain B:// content by txid
+type ContentRef struct {
+	TxID string // Transaction of the B:// content
+	URL  string // Web URL (http or https)
+}
+
+// ParseProfile validates an ALIAS profile against the bundled profile schema (see
+// ValidateProfile) and parses it, a profile without a type is a Person
+func ParseProfile(profile string) (*Profile, error) {
+	if err := ValidateProfile(profile); err != nil {
+		return nil, err
+	}
+	p := new(Profile)
+	if err := json.Unmarshal([]byte(profile), p); err != nil {
+		return nil, &ProfileError{Reason: err.Error()}
+	} else if err = json.Unmarshal([]byte(profile), &p.Fields); err != nil {
+		return nil, &ProfileError{Reason: err.Error()}
+	}
+	if len(p.Type) == 0 {
+		p.Type = ProfilePerson
+	}
+	return p, nil
+}
+
+// ParsedProfile parses the profile of an ALIAS record (see ParseProfile)
+func (b *Bap) ParsedProfile() (*Profile, error) {
+	if b.Type != ALIAS {
+		return nil, &RecordTypeError{Type: b.Type}
+	}
+	return ParseProfile(b.Profile)
+}
+
+// IsOrganization returns true if the profile is an Organization
+func (p *Profile) IsOrganization() bool {
+	return p != nil && p.Type == ProfileOrganization
+}
+
+// DisplayName returns the name (or alternate name) without control or formatting
+// characters, such as bidirectional overrides, truncated to 256 characters
+func (p *Profile) DisplayName() string {
+	if p == nil {
+		return ""
+	}
+	for _, name := range []string{p.Name, p.AlternateName} {
+		if name = sanitizeText(name, maxDisplayName); len(name) > 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// Website returns the URL if it is an absolute http or https URL
+func (p *Profile) Website() (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	return webURL(p.URL)
+}
+
+// PaymailAddress returns the lowercase paymail if it is a well-formed alias@domain address
+func (p *Profile) PaymailAddress() (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	alias, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(p.Paymail)), "@")
+	if !ok || len(alias) == 0 || strings.ContainsAny(alias, "@ \t\r\n/") {
+		return "", false
+	} else if u, err := url.Parse("https://" + domain); err != nil || u.Host != domain ||
+		u.Port() != "" || !strings.Contains(domain, ".") {
+		return "", false
+	}
+	return alias + "@" + domain, true
+}
+
+// ImageRef returns the reference of the image
+func (p *Profile) ImageRef() (*ContentRef, bool) {
+	if p == nil {
+		return nil, false
+	}
+	return parseContentRef(p.Image)
+}
+
+// LogoRef returns the reference of the logo, or of the image if an Organization has no logo
+func (p *Profile) LogoRef() (*ContentRef, bool) {
+	if p == nil {
+		return nil, false
+	} else if len(p.Logo) == 0 && p.IsOrganization() {
+		return parseContentRef(p.Image)
+	}
+	return parseContentRef(p.Logo)
+}
+
+// ParseContentRef parses a media reference: b://<txid> (case-insensitive scheme) or an
+// http or https URL
+func ParseContentRef(ref string) (*ContentRef, error) {
+	if c, ok := parseContentRef(ref); ok {
+		return c, nil
+	}
+	return nil, &ProfileError{Reason: "unsupported content reference: " + sanitizeText(ref, maxDisplayName)}
+}
+
+// OnChain returns true if the content is on-chain B:// content
+func (c *ContentRef) OnChain() bool {
+	return c != nil && len(c.TxID) > 0
+}
+
+// Resolve returns the URL of the content, on-chain content is requested from a gateway
+// that serves B:// content at <gateway>/<txid>
+func (c *ContentRef) Resolve(gateway string) string {
+	if c == nil {
+		return ""
+	} else if !c.OnChain() {
+		return c.URL
+	}
+	return strings.TrimRight(gateway, "/") + "/" + c.TxID
+}
+
+// String returns the reference as b://<txid> or the URL
+func (c *ContentRef) String() string {
+	if c == nil {
+		return ""
+	} else if c.OnChain() {
+		return bScheme + c.TxID
+	}
+	return c.URL
+}
+
+// parseContentRef parses a media reference (see ParseContentRef)
+func parseContentRef(ref string) (*ContentRef, bool) {
+	ref = strings.TrimSpace(ref)
+	if len(ref) > len(bScheme) && strings.EqualFold(ref[:len(bScheme)], bScheme) {
+		txID := strings.ToLower(ref[len(bScheme):])
+		if decoded, err := hex.DecodeString(txID); err != nil || len(decoded) != 32 {
+			return nil, false
+		}
+		return &ContentRef{TxID: txID}, true
+	}
+	if u, ok := webURL(ref); ok {
+		return &ContentRef{URL: u}, true
+	}
+	return nil, false
+}
+
+// webURL returns a URL if it is an absolute http or https URL with a host
+func webURL(raw string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 || u.User != nil {
+		return "", false
+	}
+	return u.String(), true
+}
+
+// sanitizeText removes control and formatting characters, trims spaces and truncates to limit characters
+func sanitizeText(text string, limit int) string {
+	text = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, text))
+	if utf8.RuneCountInString(text) > limit {
+		text = strings.TrimSpace(string([]rune(text)[:limit]))
+	}
+	return text
+}
+
+// ChangeType is the kind of change of a profile field between two versions
+type ChangeType string
+
+// Change types
+const (
+	FieldAdded   ChangeType = "added"
+	FieldChanged ChangeType = "changed"
+	FieldRemoved ChangeType = "removed"
+)
+
+// ProfileChange is a change of a profile field, nested objects are compared field by
+// field with dotted paths (e.g. homeLocation.name)
+type ProfileChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from,omitempty"` // Previous value, unless added
+	To    json.RawMessage `json:"to,omitempty"`   // New value, unless removed
+	Type  ChangeType      `json:"type"`
+}
+
+// DiffProfiles returns the field changes from one profile to another, sorted by field.
+// Profiles must be JSON objects but do not have to match the profile schema, an empty
+// profile is an empty object. Values are compared by content, not formatting.
+func DiffProfiles(from, to string) ([]*ProfileChange, error) {
+	fromFields, err := profileObject(from)
+	if err != nil {
+		return nil, err
+	}
+	var toFields map[string]interface{}
+	if toFields, err = profileObject(to); err != nil {
+		return nil, err
+	}
+	changes := diffFields("", fromFields, toFields)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// profileObject decodes a profile JSON object, an empty profile is an empty object
+func profileObject(profile string) (map[string]interface{}, error) {
+	if len(strings.TrimSpace(profile)) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	value, err := decodeJSON([]byte(profile))
+	if err != nil {
+		return nil, &ProfileError{Reason: "not valid JSON: " + err.Error()}
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, &ProfileError{Reason: "expected object but got " + jsonType(value)}
+	}
+	return fields, nil
+}
+
+// diffFields returns the changes between the fields of two objects
+func diffFields(prefix string, from, to map[string]interface{}) []*ProfileChange {
+	var changes []*ProfileChange
+	for name, fromValue := range from {
+		field := joinField(prefix, name)
+		toValue, ok := to[name]
+		if !ok {
+			changes = append(changes, &ProfileChange{Field: field, From: encodeValue(fromValue), Type: FieldRemoved})
+			continue
+		}
+		fromObject, fromIsObject := fromValue.(map[string]interface{})
+		toObject, toIsObject := toValue.(map[string]interface{})
+		if fromIsObject && toIsObject {
+			changes = append(changes, diffFields(field, fromObject, toObject)...)
+		} else if !equalJSON(fromValue, toValue) {
+			changes = append(changes, &ProfileChange{Field: field, From: encodeValue(fromValue), To: encodeValue(toValue), Type: FieldChanged})
+		}
+	}
+	for name, toValue := range to {
+		if _, ok := from[name]; !ok {
+			changes = append(changes, &ProfileChange{Field: joinField(prefix, name), To: encodeValue(toValue), Type: FieldAdded})
+		}
+	}
+	return changes
+}
+
+// equalJSON returns true if two decoded JSON values are equal, numbers are compared by value
+func equalJSON(a, b interface{}) bool {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, _, aErr := big.ParseFloat(av.String(), 10, 256, big.ToNearestEven)
+		bf, _, bErr := big.ParseFloat(bv.String(), 10, 256, big.ToNearestEven)
+		return aErr == nil && bErr == nil && af.Cmp(bf) == 0
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for index := range av {
+			if !equalJSON(av[index], bv[index]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for name, value := range av {
+			if other, exists := bv[name]; !exists || !equalJSON(value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// encodeValue encodes a decoded JSON value
+func encodeValue(value interface{}) json.RawMessage {
+	raw, _ := json.Marshal(value)
+	return raw
+}
@@ -0,0 +1,179 @@
+package bap
+
+import (
+	"bytes"
+	_ "embed" // Bundled profile schema
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// profileSchema is the bundled JSON schema of ALIAS profiles
+//
+//go:embed schema/profile.json
+var profileSchema []byte
+
+// ProfileSchema returns the bundled JSON schema (draft-07) of ALIAS profiles
+func ProfileSchema() []byte {
+	return bytes.Clone(profileSchema)
+}
+
+// ValidateProfile checks an ALIAS profile against the bundled profile schema
+func ValidateProfile(profile string) error {
+	schema, err := loadProfileSchema()
+	if err != nil {
+		return err
+	}
+	var value interface{}
+	if value, err = decodeJSON([]byte(profile)); err != nil {
+		return &ProfileError{Reason: "not valid JSON: " + err.Error()}
+	}
+	return schema.validate("", value)
+}
+
+// jsonSchema is the subset of JSON schema keywords used by the bundled profile schema
+type jsonSchema struct {
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Enum                 []interface{}          `json:"enum"`
+	MaxLength            *int                   `json:"maxLength"`
+	MinLength            *int                   `json:"minLength"`
+	Pattern              string                 `json:"pattern"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	Required             []string               `json:"required"`
+	Type                 string                 `json:"type"`
+	pattern              *regexp.Regexp
+}
+
+// loadProfileSchema parses and compiles the bundled profile schema once
+var loadProfileSchema = sync.OnceValues(func() (*jsonSchema, error) {
+	schema := new(jsonSchema)
+	decoder := json.NewDecoder(bytes.NewReader(profileSchema))
+	decoder.UseNumber()
+	if err := decoder.Decode(schema); err != nil {
+		return nil, fmt.Errorf("invalid profile schema: %w", err)
+	}
+	return schema, schema.compile()
+})
+
+// compile compiles the patterns of the schema and its properties
+func (s *jsonSchema) compile() error {
+	if len(s.Pattern) > 0 {
+		var err error
+		if s.pattern, err = regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("invalid profile schema pattern %q: %w", s.Pattern, err)
+		}
+	}
+	for _, property := range s.Properties {
+		if err := property.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate checks a decoded JSON value against the schema
+func (s *jsonSchema) validate(field string, value interface{}) error {
+	if len(s.Type) > 0 && !isJSONType(value, s.Type) {
+		return &ProfileError{Field: field, Reason: fmt.Sprintf("expected %s but got %s", s.Type, jsonType(value))}
+	}
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		return &ProfileError{Field: field, Reason: fmt.Sprintf("%v is not one of %v", value, s.Enum)}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			return &ProfileError{Field: field, Reason: fmt.Sprintf("shorter than %d characters", *s.MinLength)}
+		} else if s.MaxLength != nil && length > *s.MaxLength {
+			return &ProfileError{Field: field, Reason: fmt.Sprintf("longer than %d characters", *s.MaxLength)}
+		} else if s.pattern != nil && !s.pattern.MatchString(v) {
+			return &ProfileError{Field: field, Reason: fmt.Sprintf("does not match the pattern %s", s.Pattern)}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return &ProfileError{Field: joinField(field, name), Reason: "is required"}
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				if err := property.validate(joinField(field, name), v[name]); err != nil {
+					return err
+				}
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return &ProfileError{Field: joinField(field, name), Reason: "is not allowed"}
+			}
+		}
+	}
+	return nil
+}
+
+// decodeJSON decodes a single JSON value, keeping numbers as json.Number
+func decodeJSON(raw []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	} else if _, err = decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// jsonType returns the JSON schema type of a decoded value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// isJSONType returns true if a decoded value is of a JSON schema type
+func isJSONType(value interface{}, schemaType string) bool {
+	if number, ok := value.(json.Number); ok && schemaType == "integer" {
+		return !strings.ContainsAny(number.String(), ".eE")
+	}
+	return jsonType(value) == schemaType
+}
+
+// inEnum returns true if a decoded value is one of the enum values
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinField returns the path of a property of a field
+func joinField(field, name string) string {
+	if len(field) == 0 {
+		return name
+	}
+	return field + "." + name
+}
@@ -0,0 +1,277 @@
+package bap
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bitcoinschema/go-bob"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+// proofMagic and proofVersion start the binary encoding of a ProofBundle
+const (
+	proofMagic   = "BAPP"
+	proofVersion = byte(1)
+)
+
+// ProofBundle is everything a relying party needs to verify an attested attribute offline:
+// the disclosed attribute, the attestation tx, and the ID history of the attestor (and
+// optionally of the identity) that shows its signing addresses. Transactions are raw hex,
+// ID histories are in chain order. The attestor history ends with the last ID record
+// before the attestation, so its last address is the one that was valid when it was signed.
+type ProofBundle struct {
+	AttestationTx           string   `json:"attestation_tx"`
+	AttestorHistory         []string `json:"attestor_history"`
+	AttestorIDKey           string   `json:"attestor_id_key"`
+	AttributeName           string   `json:"attribute_name"`
+	AttributeValue          string   `json:"attribute_value"`
+	IDKey                   string   `json:"id_key"`
+	IdentityAttributeSecret string   `json:"identity_attribute_secret"`
+	IdentityHistory         []string `json:"identity_history,omitempty"`
+}
+
+// VerifiedProof is the result of a successful ProofBundle verification
+type VerifiedProof struct {
+	AttestorAddress string `json:"attestor_address"` // Address that signed the attestation
+	AttestorIDKey   string `json:"attestor_id_key"`
+	Expiry          Expiry `json:"expiry,omitempty"`           // Expiry of the attestation, check it with Expiry.Expired
+	IdentityAddress string `json:"identity_address,omitempty"` // Current address, if the identity history was included
+	IDKey           string `json:"id_key"`
+	URNHash         string `json:"urn_hash"`
+}
+
+// NewProofBundle builds the proof bundle of an attested attribute (the identity history is optional)
+func NewProofBundle(attribute *AttestationRequest, attestationTx *transaction.Transaction, attestorIDKey string,
+	attestorHistory, identityHistory []*transaction.Transaction) (*ProofBundle, error) {
+	if attribute == nil {
+		return nil, &MissingFieldError{Field: "attribute"}
+	} else if len(attribute.IDKey) == 0 {
+		return nil, &MissingFieldError{Field: "idKey"}
+	} else if attestationTx == nil {
+		return nil, &MissingFieldError{Field: "attestationTx"}
+	} else if len(attestorIDKey) == 0 {
+		return nil, &MissingFieldError{Field: "attestorIDKey"}
+	} else if len(attestorHistory) == 0 {
+		return nil, &MissingFieldError{Field: "attestorHistory"}
+	}
+	return &ProofBundle{
+		AttestationTx:           attestationTx.Hex(),
+		AttestorHistory:         hexTxs(attestorHistory),
+		AttestorIDKey:           attestorIDKey,
+		AttributeName:           attribute.AttributeName,
+		AttributeValue:          attribute.AttributeValue,
+		IDKey:                   attribute.IDKey,
+		IdentityAttributeSecret: attribute.IdentityAttributeSecret,
+		IdentityHistory:         hexTxs(identityHistory),
+	}, nil
+}
+
+// Verify checks the proof without any network access: the attribute hashes to the URN of an
+// ATTEST record in the attestation tx, the AIP signatures are valid, and the attestation was
+// signed by the attestor's address at the end of its history, the address valid at the
+// attestation (network defaults to mainnet). Records of other attestors in the same tx, such
+// as a threshold attestation, are skipped.
+//
+// The id keys must be derived from their root address (the BAP spec format), use
+// VerifyWithRoots for 64 character hex id keys. Revocations and block heights are not part
+// of the bundle, combine with an index or SPV for those. The expiry of the attestation is
+// returned, not checked.
+func (p *ProofBundle) Verify(network *chaincfg.Params) (*VerifiedProof, error) {
+	return p.VerifyWithRoots(network, nil)
+}
+
+// VerifyWithRoots checks the proof (see Verify) with the root addresses the verifier trusts
+// for id keys (id key -> root address), which are required for id keys that are not derived
+// from their root address (64 character hex). The root addresses must come from a trusted
+// source, not from the bundle, or anyone could create the history of the identity.
+func (p *ProofBundle) VerifyWithRoots(network *chaincfg.Params, rootAddresses map[string]string) (*VerifiedProof, error) {
+	if len(p.IDKey) == 0 {
+		return nil, &MissingFieldError{Field: "idKey"}
+	} else if len(p.AttestorIDKey) == 0 {
+		return nil, &MissingFieldError{Field: "attestorIDKey"}
+	}
+
+	attestorAddresses, err := identityChain(p.AttestorIDKey, rootAddresses[p.AttestorIDKey], p.AttestorHistory, network)
+	if err != nil {
+		return nil, fmt.Errorf("attestor history: %w", err)
+	}
+	result := &VerifiedProof{AttestorIDKey: p.AttestorIDKey, IDKey: p.IDKey}
+	if len(p.IdentityHistory) > 0 {
+		var addresses []string
+		if addresses, err = identityChain(p.IDKey, rootAddresses[p.IDKey], p.IdentityHistory, network); err != nil {
+			return nil, fmt.Errorf("identity history: %w", err)
+		}
+		result.IdentityAddress = addresses[len(addresses)-1]
+	}
+
+	hash := AttestationHash(p.IDKey, p.AttributeName, p.AttributeValue, p.IdentityAttributeSecret)
+	result.URNHash = hex.EncodeToString(hash[:])
+	var records []*SignedBap
+	if records, err = signedRecordsFromHex(p.AttestationTx); err != nil {
+		return nil, fmt.Errorf("attestation tx: %w", err)
+	}
+	attestorAddress := attestorAddresses[len(attestorAddresses)-1]
+	for _, record := range records {
+		if record.Type != ATTEST || record.URNHash != result.URNHash {
+			continue
+		} else if record.Signer == nil || !record.Signer.Valid {
+			return nil, &SignatureError{Err: errors.New("attestation signature is not valid")}
+		} else if record.Signer.Address != attestorAddress {
+			continue // Attestation of another attestor
+		}
+		result.AttestorAddress, result.Expiry = attestorAddress, record.Expiry
+		return result, nil
+	}
+	return nil, fmt.Errorf("%w: no attestation of the attribute signed by the attestor address %s", ErrInvalidProof, attestorAddress)
+}
+
+// Bytes returns the compact binary encoding of the proof bundle
+func (p *ProofBundle) Bytes() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(proofMagic)
+	b.WriteByte(proofVersion)
+	for _, field := range []string{p.IDKey, p.AttributeName, p.AttributeValue, p.IdentityAttributeSecret, p.AttestorIDKey} {
+		writeVarBytes(&b, []byte(field))
+	}
+	for _, txs := range [][]string{{p.AttestationTx}, p.AttestorHistory, p.IdentityHistory} {
+		b.Write(transaction.VarInt(len(txs)).Bytes())
+		for _, tx := range txs {
+			raw, err := hex.DecodeString(tx)
+			if err != nil {
+				return nil, fmt.Errorf("%w: transaction is not hex: %w", ErrInvalidProof, err)
+			}
+			writeVarBytes(&b, raw)
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// NewProofBundleFromBytes reads a proof bundle from its binary encoding
+func NewProofBundleFromBytes(data []byte) (*ProofBundle, error) {
+	if len(data) < len(proofMagic)+1 || string(data[:len(proofMagic)]) != proofMagic {
+		return nil, fmt.Errorf("%w: not a proof bundle", ErrInvalidProof)
+	} else if data[len(proofMagic)] != proofVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidProof, data[len(proofMagic)])
+	}
+
+	r := bytes.NewReader(data[len(proofMagic)+1:])
+	p := new(ProofBundle)
+	for _, field := range []*string{&p.IDKey, &p.AttributeName, &p.AttributeValue, &p.IdentityAttributeSecret, &p.AttestorIDKey} {
+		value, err := readVarBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		*field = string(value)
+	}
+	var attestation []string
+	for _, txs := range []*[]string{&attestation, &p.AttestorHistory, &p.IdentityHistory} {
+		var count transaction.VarInt
+		if _, err := count.ReadFrom(r); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+		} else if count > transaction.VarInt(r.Len()) {
+			return nil, fmt.Errorf("%w: invalid transaction count", ErrInvalidProof)
+		}
+		for i := transaction.VarInt(0); i < count; i++ {
+			raw, err := readVarBytes(r)
+			if err != nil {
+				return nil, err
+			}
+			*txs = append(*txs, hex.EncodeToString(raw))
+		}
+	}
+	if len(attestation) != 1 {
+		return nil, fmt.Errorf("%w: expected one attestation tx", ErrInvalidProof)
+	} else if r.Len() > 0 {
+		return nil, fmt.Errorf("%w: trailing data", ErrInvalidProof)
+	}
+	p.AttestationTx = attestation[0]
+	return p, nil
+}
+
+// identityChain checks the ID history of an identity and returns its signing addresses in order,
+// each ID record must be signed by an address allowed to publish it (see ValidateIDSigner)
+func identityChain(idKey, rootAddress string, history []string, network *chaincfg.Params) ([]string, error) {
+	if len(history) == 0 {
+		return nil, &MissingFieldError{Field: "history"}
+	}
+	var addresses []string
+	for index, txHex := range history {
+		records, err := signedRecordsFromHex(txHex)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", index, err)
+		}
+		found := false
+		for _, record := range records {
+			if record.Type != ID || record.IDKey != idKey {
+				continue
+			} else if record.Signer == nil || !record.Signer.Valid {
+				return nil, &SignatureError{Err: fmt.Errorf("ID record %d is not signed", index)}
+			} else if err = record.Validate(network); err != nil {
+				return nil, err
+			}
+			var current string
+			if len(addresses) > 0 {
+				current = addresses[len(addresses)-1]
+			}
+			if err = ValidateIDSigner(idKey, record.Signer.Address, rootAddress, current); err != nil {
+				return nil, fmt.Errorf("%w: ID record %d: %w", ErrInvalidProof, index, err)
+			}
+			addresses = append(addresses, record.Address)
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: transaction %d has no ID record for %s", ErrInvalidProof, index, idKey)
+		}
+	}
+	return addresses, nil
+}
+
+// signedRecordsFromHex returns the signed BAP records of a raw transaction (hex)
+func signedRecordsFromHex(txHex string) ([]*SignedBap, error) {
+	tx, err := transaction.NewTransactionFromHex(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	var bobTx *bob.Tx
+	if bobTx, err = bob.NewFromTx(tx); err != nil {
+		return nil, err
+	}
+	return NewSignedFromOutputs(bobTx.Out)
+}
+
+// hexTxs returns the raw hex of the transactions
+func hexTxs(txs []*transaction.Transaction) []string {
+	if len(txs) == 0 {
+		return nil
+	}
+	raw := make([]string, len(txs))
+	for index, tx := range txs {
+		raw[index] = tx.Hex()
+	}
+	return raw
+}
+
+// writeVarBytes will write a VarInt length prefixed byte slice
+func writeVarBytes(b *bytes.Buffer, data []byte) {
+	b.Write(transaction.VarInt(len(data)).Bytes())
+	b.Write(data)
+}
+
+// readVarBytes will read a VarInt length prefixed byte slice
+func readVarBytes(r *bytes.Reader) ([]byte, error) {
+	var length transaction.VarInt
+	if _, err := length.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	} else if length > transaction.VarInt(r.Len()) {
+		return nil, fmt.Errorf("%w: field length exceeds data", ErrInvalidProof)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+	return data, nil
+}
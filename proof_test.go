@@ -0,0 +1,297 @@
+package bap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+// Attestor identity of the proof tests
+const (
+	testProofAttestorKey = "xprv9s21ZrQH143K3PZSwbEeXEYq74EbnfMngzAiMCZcfjzyRpUvt2vQJnaHRTZjeuEmLXeN6BzYRoFsEckfobxE9XaRzeLGfQoxzPzTRyRb6oE"
+	testProofAttestorID  = "0d5d1e0b1bd2c0f9b8c7e6a5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5"
+)
+
+// testProofAttribute is the attested attribute of the proof tests
+var testProofAttribute = &AttestationRequest{
+	IDKey:                   idKey,
+	AttributeName:           "name",
+	AttributeValue:          "John",
+	IdentityAttributeSecret: "secret",
+}
+
+// testIDTx returns an ID record for the address at 0/counter, signed by the key at 0/signer
+func testIDTx(t testing.TB, xPrivateKey, idKey string, counter, signer uint32) *transaction.Transaction {
+	signingKey, _ := testutil.SigningKey(t, xPrivateKey, signer)
+	_, address := testutil.SigningKey(t, xPrivateKey, counter)
+	record, err := newRecord(signingKey, [][]byte{[]byte(Prefix), []byte(ID), []byte(idKey), []byte(address), []byte(pipe)})
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var tx *transaction.Transaction
+	if tx, err = returnTx(record); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return tx
+}
+
+// testProofRoots returns the root addresses of the attestor and identity of the proof tests
+func testProofRoots(t testing.TB) map[string]string {
+	return map[string]string{
+		idKey:               testutil.RootAddress(t, privateKey),
+		testProofAttestorID: testutil.RootAddress(t, testProofAttestorKey),
+	}
+}
+
+// testProofBundle returns a proof of an attestation signed by the attestor's rotated key (0/1)
+func testProofBundle(t testing.TB) *ProofBundle {
+	attestorKey, _ := testutil.SigningKey(t, testProofAttestorKey, 1)
+	attestationTx, err := CreateAttestation(idKey, attestorKey, "name", "John", "secret")
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var bundle *ProofBundle
+	if bundle, err = NewProofBundle(testProofAttribute, attestationTx, testProofAttestorID,
+		[]*transaction.Transaction{
+			testIDTx(t, testProofAttestorKey, testProofAttestorID, 0, 0),
+			testIDTx(t, testProofAttestorKey, testProofAttestorID, 1, 0),
+		},
+		[]*transaction.Transaction{
+			testIDTx(t, privateKey, idKey, 0, 0),
+			testIDTx(t, privateKey, idKey, 1, 0),
+			testIDTx(t, privateKey, idKey, 2, 1),
+		},
+	); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return bundle
+}
+
+// TestProofBundle_Verify will test the method Verify()
+func TestProofBundle_Verify(t *testing.T) {
+	t.Parallel()
+
+	_, attestorAddress := testutil.SigningKey(t, testProofAttestorKey, 1)
+	_, identityAddress := testutil.SigningKey(t, privateKey, 2)
+	otherKey, otherAddress := testutil.SigningKey(t, testProofAttestorKey, 5)
+	replacedKey, _ := testutil.SigningKey(t, testProofAttestorKey, 0)
+	attestorKey, _ := testutil.SigningKey(t, testProofAttestorKey, 1)
+	roots := testProofRoots(t)
+	otherAttestation, err := CreateAttestation(idKey, otherKey, "name", "John", "secret")
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var replacedAttestation, thresholdAttestation *transaction.Transaction
+	if replacedAttestation, err = CreateAttestation(idKey, replacedKey, "name", "John", "secret"); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if thresholdAttestation, err = CreateThresholdAttestation([]*ec.PrivateKey{otherKey, attestorKey}, *testProofAttribute); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			modify        func(p *ProofBundle)
+			roots         map[string]string
+			expectedError error
+		}{
+			{"valid", func(_ *ProofBundle) {}, roots, nil},
+			{"without identity history", func(p *ProofBundle) { p.IdentityHistory = nil }, roots, nil},
+			{"wrong value", func(p *ProofBundle) { p.AttributeValue = "Jane" }, roots, ErrInvalidProof},
+			{"wrong secret", func(p *ProofBundle) { p.IdentityAttributeSecret = "guess" }, roots, ErrInvalidProof},
+			{"wrong identity", func(p *ProofBundle) { p.IDKey = testProofAttestorID }, roots, ErrInvalidProof},
+			{"signed by another key", func(p *ProofBundle) { p.AttestationTx = otherAttestation.Hex() }, roots, ErrInvalidProof},
+			{"signed by a replaced address", func(p *ProofBundle) { p.AttestationTx = replacedAttestation.Hex() }, roots, ErrInvalidProof},
+			{"second attestor of a threshold attestation", func(p *ProofBundle) { p.AttestationTx = thresholdAttestation.Hex() }, roots, nil},
+			{"missing rotation", func(p *ProofBundle) { p.AttestorHistory = p.AttestorHistory[:1] }, roots, ErrInvalidProof},
+			{"unauthorized rotation", func(p *ProofBundle) {
+				p.AttestorHistory[1] = testIDTx(t, testProofAttestorKey, testProofAttestorID, 1, 5).Hex()
+			}, roots, ErrInvalidProof},
+			{"unrelated history", func(p *ProofBundle) { p.AttestorHistory = p.IdentityHistory }, roots, ErrInvalidProof},
+			{"bad identity history", func(p *ProofBundle) { p.IdentityHistory = []string{"zz"} }, roots, ErrInvalidProof},
+			{"bad attestation tx", func(p *ProofBundle) { p.AttestationTx = "00" }, roots, ErrInvalidProof},
+			{"no attestor history", func(p *ProofBundle) { p.AttestorHistory = nil }, roots, ErrMissingField},
+			{"no attestor", func(p *ProofBundle) { p.AttestorIDKey = "" }, roots, ErrMissingField},
+			{"no id key", func(p *ProofBundle) { p.IDKey = "" }, roots, ErrMissingField},
+			{"no root addresses", func(_ *ProofBundle) {}, nil, ErrInvalidProof},
+			{"forged history", func(p *ProofBundle) {
+				p.AttestorHistory = []string{testIDTx(t, testProofAttestorKey, testProofAttestorID, 5, 5).Hex()}
+				p.AttestationTx = otherAttestation.Hex()
+			}, roots, ErrInvalidProof},
+			{"history of the trusted root", func(p *ProofBundle) {
+				p.AttestorHistory = []string{testIDTx(t, testProofAttestorKey, testProofAttestorID, 5, 5).Hex()}
+				p.AttestationTx = otherAttestation.Hex()
+			}, map[string]string{idKey: roots[idKey], testProofAttestorID: otherAddress}, nil},
+			{"rotation signed by a replaced root", func(p *ProofBundle) {
+				p.IdentityHistory[2] = testIDTx(t, privateKey, idKey, 2, 0).Hex()
+			}, roots, ErrInvalidProof},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		bundle := testProofBundle(t)
+		test.modify(bundle)
+		if result, verifyErr := bundle.VerifyWithRoots(nil, test.roots); test.expectedError == nil && verifyErr != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, verifyErr.Error())
+		} else if test.expectedError != nil && !errors.Is(verifyErr, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, verifyErr)
+		} else if test.expectedError == nil && test.roots[testProofAttestorID] == roots[testProofAttestorID] &&
+			(result.AttestorAddress != attestorAddress || result.AttestorIDKey != testProofAttestorID) {
+			t.Errorf("%s Failed: [%s] inputted and unexpected result: %+v", t.Name(), test.name, result)
+		} else if test.expectedError == nil && len(bundle.IdentityHistory) > 0 && result.IdentityAddress != identityAddress {
+			t.Errorf("%s Failed: [%s] inputted and expected identity address [%s] but got [%s]", t.Name(), test.name, identityAddress, result.IdentityAddress)
+		}
+	}
+}
+
+// TestProofBundle_VerifySpecIDKey will test verifying an attestor whose id key is derived
+// from its root address, which needs no root address from the verifier
+func TestProofBundle_VerifySpecIDKey(t *testing.T) {
+	t.Parallel()
+
+	attestorID := IdentityKeyFromAddress(testutil.RootAddress(t, testProofAttestorKey))
+	attestorKey, attestorAddress := testutil.SigningKey(t, testProofAttestorKey, 1)
+	attestationTx, err := CreateAttestation(idKey, attestorKey, "name", "John", "secret")
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var bundle *ProofBundle
+	if bundle, err = NewProofBundle(testProofAttribute, attestationTx, attestorID, []*transaction.Transaction{
+		testIDTx(t, testProofAttestorKey, attestorID, 0, 0),
+		testIDTx(t, testProofAttestorKey, attestorID, 1, 0),
+	}, nil); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	var result *VerifiedProof
+	if result, err = bundle.Verify(nil); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if result.AttestorAddress != attestorAddress {
+		t.Fatalf("%s Failed: expected the attestor address %s but got %s", t.Name(), attestorAddress, result.AttestorAddress)
+	}
+
+	// A history created by another key
+	otherID := IdentityKeyFromAddress(testutil.RootAddress(t, privateKey))
+	bundle.AttestorIDKey = otherID
+	bundle.AttestorHistory = []string{testIDTx(t, testProofAttestorKey, otherID, 1, 1).Hex()}
+	if _, err = bundle.Verify(nil); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("%s Failed: expected ErrInvalidProof but got: %v", t.Name(), err)
+	}
+}
+
+// TestProofBundle_Encoding will test the JSON and binary encodings
+func TestProofBundle_Encoding(t *testing.T) {
+	t.Parallel()
+
+	bundle := testProofBundle(t)
+	data, err := bundle.Bytes()
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var decoded *ProofBundle
+	if decoded, err = NewProofBundleFromBytes(data); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if _, err = decoded.VerifyWithRoots(&chaincfg.MainNet, testProofRoots(t)); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if fmt.Sprintf("%+v", decoded) != fmt.Sprintf("%+v", bundle) {
+		t.Fatalf("expected %+v but got %+v", bundle, decoded)
+	}
+
+	var jsonData []byte
+	if jsonData, err = json.Marshal(bundle); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(data) >= len(jsonData) {
+		t.Fatalf("binary encoding (%d bytes) should be smaller than JSON (%d bytes)", len(data), len(jsonData))
+	}
+	fromJSON := new(ProofBundle)
+	if err = json.Unmarshal(jsonData, fromJSON); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if _, err = fromJSON.VerifyWithRoots(nil, testProofRoots(t)); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name  string
+			input []byte
+		}{
+			{"empty", nil},
+			{"magic", []byte("XXXX\x01")},
+			{"version", []byte("BAPP\x02")},
+			{"truncated", data[:len(data)-10]},
+			{"trailing", append(append([]byte{}, data...), 0)},
+			{"length", []byte("BAPP\x01\xfd\xff\xff")},
+			{"no attestation", []byte("BAPP\x01\x00\x00\x00\x00\x00\x00\x00\x00")},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err = NewProofBundleFromBytes(test.input); !errors.Is(err, ErrInvalidProof) {
+			t.Errorf("%s Failed: [%s] inputted and expected ErrInvalidProof but got: %v", t.Name(), test.name, err)
+		}
+	}
+
+	bundle.AttestationTx = "zz"
+	if _, err = bundle.Bytes(); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("expected ErrInvalidProof but got: %v", err)
+	}
+}
+
+// TestNewProofBundle will test the method NewProofBundle()
+func TestNewProofBundle(t *testing.T) {
+	t.Parallel()
+
+	tx := transaction.NewTransaction()
+	history := []*transaction.Transaction{tx}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			attribute     *AttestationRequest
+			tx            *transaction.Transaction
+			attestorIDKey string
+			history       []*transaction.Transaction
+		}{
+			{"no attribute", nil, tx, testProofAttestorID, history},
+			{"no id key", &AttestationRequest{}, tx, testProofAttestorID, history},
+			{"no tx", testProofAttribute, nil, testProofAttestorID, history},
+			{"no attestor", testProofAttribute, tx, "", history},
+			{"no history", testProofAttribute, tx, testProofAttestorID, nil},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err := NewProofBundle(test.attribute, test.tx, test.attestorIDKey, test.history, nil); !errors.Is(err, ErrMissingField) {
+			t.Errorf("%s Failed: [%s] inputted and expected ErrMissingField but got: %v", t.Name(), test.name, err)
+		}
+	}
+}
+
+// ExampleProofBundle_Verify example using Verify()
+func ExampleProofBundle_Verify() {
+	bundle := &ProofBundle{IDKey: idKey, AttestorIDKey: testProofAttestorID}
+	_, err := bundle.Verify(nil)
+	fmt.Printf("error: %s", err.Error())
+	// Output:error: attestor history: missing required field: history
+}
+
+// BenchmarkProofBundle_VerifyWithRoots benchmarks the method VerifyWithRoots()
+func BenchmarkProofBundle_VerifyWithRoots(b *testing.B) {
+	bundle := testProofBundle(b)
+	roots := testProofRoots(b)
+	for i := 0; i < b.N; i++ {
+		_, _ = bundle.VerifyWithRoots(nil, roots)
+	}
+}
@@ -0,0 +1,46 @@
+package bap
+
+import (
+	"github.com/bitcoinschema/go-aip"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Record is a signed BAP record (BAP data + AIP signature) before it is placed in a transaction
+type Record struct {
+	Aip           *aip.Aip       `json:"aip"`            // AIP signature metadata
+	Address       string         `json:"address"`        // Address of the signing key
+	LockingScript *script.Script `json:"locking_script"` // OP_FALSE OP_RETURN script holding the parts
+	Parts         [][]byte       `json:"parts"`          // BAP data parts followed by the AIP parts
+}
+
+// newRecord will sign the BAP data with AIP and build the record
+func newRecord(signingKey *ec.PrivateKey, data [][]byte) (*Record, error) {
+
+	// Sign the data
+	parts, a, err := aip.SignOpReturnData(signingKey, aip.BitcoinECDSA, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the locking script
+	var output *transaction.TransactionOutput
+	if output, err = transaction.CreateOpReturnOutput(parts); err != nil {
+		return nil, err
+	}
+
+	return &Record{
+		Aip:           a,
+		Address:       a.AlgorithmSigningComponent,
+		LockingScript: output.LockingScript,
+		Parts:         parts,
+	}, nil
+}
+
+// Output returns a new zero-satoshi transaction output holding the record
+func (r *Record) Output() *transaction.TransactionOutput {
+	lockingScript := make(script.Script, len(*r.LockingScript))
+	copy(lockingScript, *r.LockingScript)
+	return &transaction.TransactionOutput{LockingScript: &lockingScript}
+}
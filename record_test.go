@@ -0,0 +1,89 @@
+package bap
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// TestCreateIdentityRecord will test the method CreateIdentityRecord()
+func TestCreateIdentityRecord(t *testing.T) {
+	t.Parallel()
+
+	record, err := CreateIdentityRecord(privateKey, idKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// BAP parts + pipe + AIP parts
+	if len(record.Parts) != 9 {
+		t.Fatalf("expected 9 parts but got %d", len(record.Parts))
+	} else if string(record.Parts[0]) != Prefix || string(record.Parts[1]) != string(ID) || string(record.Parts[2]) != idKey {
+		t.Fatalf("unexpected BAP parts: %s", record.Parts[:3])
+	} else if string(record.Parts[3]) != record.Address {
+		t.Fatalf("expected signing address [%s] but got [%s]", record.Address, record.Parts[3])
+	}
+
+	// AIP metadata is kept
+	if record.Aip == nil {
+		t.Fatalf("aip should not be nil")
+	} else if record.Aip.Signature != string(record.Parts[8]) {
+		t.Fatalf("expected signature [%s] but got [%s]", record.Parts[8], record.Aip.Signature)
+	} else if valid, err := record.Aip.Validate(); err != nil || !valid {
+		t.Fatalf("aip signature should be valid: %v", err)
+	}
+
+	// Locking script matches the transaction output
+	tx, err := CreateIdentity(privateKey, idKey, 0)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if !bytes.Equal(*record.LockingScript, *tx.Outputs[0].LockingScript) {
+		t.Fatalf("locking script does not match CreateIdentity()")
+	}
+
+	// Invalid identity
+	if _, err = CreateIdentityRecord(privateKey, "", 0); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+}
+
+// TestCreateAttestationRecord will test the method CreateAttestationRecord()
+func TestCreateAttestationRecord(t *testing.T) {
+	t.Parallel()
+
+	record, err := CreateAttestationRecord(idKey, testAttestorKey(), "person", "john", "some-secret-hash")
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	hash := AttestationHash(idKey, "person", "john", "some-secret-hash")
+	if !bytes.Equal(record.Parts[2], hash[:]) {
+		t.Fatalf("expected attestation hash [%x] but got [%x]", hash, record.Parts[2])
+	} else if record.Address != "1AFc9feffQmxT61iEftzkaYvWTgLCyU6j" {
+		t.Fatalf("expected signing address [%s] but got [%s]", "1AFc9feffQmxT61iEftzkaYvWTgLCyU6j", record.Address)
+	}
+
+	// Output is a copy of the locking script
+	output := record.Output()
+	(*output.LockingScript)[0] = 0xff
+	if (*record.LockingScript)[0] == 0xff {
+		t.Fatalf("output should not share the record locking script")
+	}
+
+	// Invalid attestation
+	if _, err = CreateAttestationRecord(idKey, testAttestorKey(), "", "john", "some-secret-hash"); err == nil {
+		t.Fatalf("error should have occurred")
+	}
+}
+
+// ExampleCreateIdentityRecord example using CreateIdentityRecord()
+func ExampleCreateIdentityRecord() {
+	record, err := CreateIdentityRecord(privateKey, idKey, 0)
+	if err != nil {
+		fmt.Printf("failed to create identity record: %s", err.Error())
+		return
+	}
+
+	fmt.Printf("signed by: %s", record.Address)
+	// Output:signed by: 1A9VQqdNJrvVF73nf879n2fES6cd5nWNid
+}
@@ -0,0 +1,361 @@
+// Package server serves a BAP API compatible REST API backed by an indexer Store,
+// so front-ends and clients can run against a local, self-contained BAP API
+//
+// Every endpoint accepts a POST with a JSON body (like the hosted BAP API) or a GET with
+// query parameters, and responds with {"status": "OK", "result": ...} or
+// {"status": "ERROR", "code": ..., "message": ...}:
+//
+//   - /v1/identity/get {"idKey"}: the identity, its address history and profile
+//   - /v1/identity/validByAddress {"address", "block", "timestamp"}: the identity of an
+//     address, and whether the address was its signing address at the block (or time)
+//   - /v1/identity/getAttestations {"idKey", "limit", "offset"}: attestations of the
+//     identity's claimed attributes
+//   - /v1/attestation/get {"hash"}: attestations and revocations of a URN hash
+//   - /v1/attestation/getByAttestor {"idKey", "limit", "offset"}: identities attested by
+//     the attestor
+//
+// Request bodies larger than MaxBodySize are rejected with 413 Request Entity Too Large.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+)
+
+// Prefix is the path prefix of all API endpoints
+const Prefix = "/v1"
+
+// MaxBodySize is the maximum size of a request body in bytes
+const MaxBodySize = 64 * 1024
+
+// Response statuses
+const (
+	StatusError = "ERROR"
+	StatusOK    = "OK"
+)
+
+// Response is the envelope of every API response
+type Response struct {
+	Code    int         `json:"code,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+	Status  string      `json:"status"`
+}
+
+// Identity is an identity as returned by the API
+type Identity struct {
+	Addresses      []*Address      `json:"addresses"`
+	Block          uint32          `json:"block"`
+	CurrentAddress string          `json:"currentAddress"`
+	IDKey          string          `json:"idKey"`
+	Identity       json.RawMessage `json:"identity,omitempty"` // Latest profile (ALIAS)
+	IdentityTxID   string          `json:"identityTxId,omitempty"`
+	RootAddress    string          `json:"rootAddress"`
+	Timestamp      uint32          `json:"timestamp"`
+	Valid          bool            `json:"valid"`
+}
+
+// Address is a signing address of an identity as returned by the API
+type Address struct {
+	Address   string `json:"address"`
+	Block     uint32 `json:"block"`
+	Timestamp uint32 `json:"timestamp"`
+	TxID      string `json:"txId"`
+}
+
+// Attestation is an ATTEST or REVOKE record as returned by the API
+type Attestation struct {
+	Address   string `json:"address"`          // Address that signed the record
+	Block     uint32 `json:"block"`            // Block height (0 is unconfirmed)
+	Expiry    uint64 `json:"expiry,omitempty"` // Block height or unix time the attestation expires
+	Hash      string `json:"hash"`             // URN hash
+	IDKey     string `json:"idKey,omitempty"`  // Attestor identity, if known
+	Sequence  uint64 `json:"sequence"`
+	Subject   string `json:"subject,omitempty"` // Identity the attribute belongs to, if known
+	Timestamp uint32 `json:"timestamp"`
+	TxID      string `json:"txId"`
+	Type      string `json:"type"`
+}
+
+// AttestationResult is the result of /attestation/get
+type AttestationResult struct {
+	Attestations []*Attestation `json:"attestations"`
+	Hash         string         `json:"hash"`
+}
+
+// Request is the body (or query) of an API request, each endpoint uses a subset of the fields
+type Request struct {
+	Address   string `json:"address,omitempty"`
+	Block     uint32 `json:"block,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	IDKey     string `json:"idKey,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+	Offset    int    `json:"offset,omitempty"`
+	Timestamp uint32 `json:"timestamp,omitempty"`
+}
+
+// Server is an http.Handler serving the BAP API
+type Server struct {
+	mux   *http.ServeMux
+	query *indexer.Query
+}
+
+// New returns a server for the records of the store
+func New(store indexer.Store) *Server {
+	s := &Server{mux: http.NewServeMux(), query: indexer.NewQuery(store)}
+	s.handle("/identity/get", s.identity)
+	s.handle("/identity/validByAddress", s.validByAddress)
+	s.handle("/identity/getAttestations", s.identityAttestations)
+	s.handle("/attestation/get", s.attestation)
+	s.handle("/attestation/getByAttestor", s.attestedBy)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle will register an endpoint handler
+func (s *Server) handle(path string, handler func(*Request) (interface{}, error)) {
+	s.mux.HandleFunc(Prefix+path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+		}
+		req, err := decodeRequest(r)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+				return
+			}
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		var result interface{}
+		if result, err = handler(req); err != nil {
+			writeError(w, errorCode(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, &Response{Result: result, Status: StatusOK})
+	})
+}
+
+// identity handles /identity/get
+func (s *Server) identity(req *Request) (interface{}, error) {
+	if len(req.IDKey) == 0 {
+		return nil, &bap.MissingFieldError{Field: "idKey"}
+	}
+	record, err := s.query.Identity(req.IDKey)
+	if err != nil {
+		return nil, err
+	}
+	identity := newIdentity(record)
+	identity.Valid = true
+	return identity, nil
+}
+
+// validByAddress handles /identity/validByAddress
+func (s *Server) validByAddress(req *Request) (interface{}, error) {
+	if len(req.Address) == 0 {
+		return nil, &bap.MissingFieldError{Field: "address"}
+	}
+	record, err := s.query.IdentityByAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+	identity := newIdentity(record)
+	switch {
+	case req.Block > 0:
+		identity.Valid = record.AddressAt(req.Block) == req.Address
+	case req.Timestamp > 0:
+		identity.Valid = addressAtTime(record.Identity, req.Timestamp) == req.Address
+	default:
+		identity.Valid = record.CurrentAddress == req.Address
+	}
+	return identity, nil
+}
+
+// identityAttestations handles /identity/getAttestations
+func (s *Server) identityAttestations(req *Request) (interface{}, error) {
+	records, _, err := s.query.AttestationsForIdentity(req.IDKey, indexer.Page{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		return nil, err
+	}
+	return newAttestations(records), nil
+}
+
+// attestation handles /attestation/get
+func (s *Server) attestation(req *Request) (interface{}, error) {
+	if len(req.Hash) == 0 {
+		return nil, &bap.MissingFieldError{Field: "hash"}
+	}
+	records, _, err := s.query.Attestations(indexer.AttestationFilter{URNHashes: []string{req.Hash}}, indexer.Page{})
+	if err != nil {
+		return nil, err
+	}
+	return &AttestationResult{Attestations: newAttestations(records), Hash: req.Hash}, nil
+}
+
+// attestedBy handles /attestation/getByAttestor
+func (s *Server) attestedBy(req *Request) (interface{}, error) {
+	records, _, err := s.query.IdentitiesAttestedBy(req.IDKey, indexer.Page{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		return nil, err
+	}
+	identities := make([]*Identity, len(records))
+	for index, record := range records {
+		identities[index] = newIdentity(record)
+		identities[index].Valid = true
+	}
+	return identities, nil
+}
+
+// newIdentity converts an indexed identity to the API format
+func newIdentity(record *indexer.IdentityRecord) *Identity {
+	identity := &Identity{
+		Addresses:      make([]*Address, len(record.Addresses)),
+		Block:          record.FirstSeen.Height,
+		CurrentAddress: record.CurrentAddress,
+		IDKey:          record.IDKey,
+		RootAddress:    record.RootAddress,
+		Timestamp:      record.FirstSeen.Time,
+	}
+	for index, a := range record.Addresses {
+		identity.Addresses[index] = &Address{
+			Address:   a.Address,
+			Block:     a.Block.Height,
+			Timestamp: a.Block.Time,
+			TxID:      a.TxID,
+		}
+	}
+	if len(record.Addresses) > 0 {
+		identity.IdentityTxID = record.Addresses[0].TxID
+	}
+	if record.Profile != nil && json.Valid([]byte(record.Profile.Profile)) {
+		identity.Identity = json.RawMessage(record.Profile.Profile)
+	}
+	return identity
+}
+
+// newAttestations converts indexed attestations to the API format
+func newAttestations(records []*indexer.AttestationRecord) []*Attestation {
+	attestations := make([]*Attestation, len(records))
+	for index, record := range records {
+		attestations[index] = &Attestation{
+			Address:   record.Address,
+			Block:     record.Block.Height,
+			Expiry:    uint64(record.Expiry),
+			Hash:      record.URNHash,
+			IDKey:     record.AttestorIDKey,
+			Sequence:  record.Sequence,
+			Subject:   record.Subject,
+			Timestamp: record.Block.Time,
+			TxID:      record.TxID,
+			Type:      string(record.Type),
+		}
+	}
+	return attestations
+}
+
+// addressAtTime returns the signing address that was valid at the given unix time
+// (unconfirmed addresses, without a block time, are ignored)
+func addressAtTime(identity *indexer.Identity, timestamp uint32) string {
+	var address string
+	for _, a := range identity.Addresses {
+		if a.Block.Time == 0 || a.Block.Time > timestamp {
+			break
+		}
+		address = a.Address
+	}
+	return address
+}
+
+// decodeRequest will read the request from the JSON body (POST) or query parameters (GET)
+func decodeRequest(r *http.Request) (*Request, error) {
+	req := new(Request)
+	if r.Method == http.MethodPost {
+		if r.Body == nil || r.ContentLength == 0 {
+			return req, nil
+		}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			return nil, fmt.Errorf("invalid JSON body: %w", err)
+		}
+		return req, nil
+	}
+
+	values := r.URL.Query()
+	req.Address = values.Get("address")
+	req.Hash = values.Get("hash")
+	req.IDKey = values.Get("idKey")
+	var (
+		err           error
+		limit, offset uint32
+	)
+	if req.Block, err = queryUint(values, "block"); err != nil {
+		return nil, err
+	} else if req.Timestamp, err = queryUint(values, "timestamp"); err != nil {
+		return nil, err
+	} else if limit, err = queryUint(values, "limit"); err != nil {
+		return nil, err
+	} else if offset, err = queryUint(values, "offset"); err != nil {
+		return nil, err
+	}
+	req.Limit, req.Offset = int(limit), int(offset)
+	return req, nil
+}
+
+// queryUint will read an optional unsigned number from the query parameters
+func queryUint(values url.Values, name string) (uint32, error) {
+	value := values.Get(name)
+	if len(value) == 0 {
+		return 0, nil
+	}
+	number, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid " + name + ": " + value)
+	}
+	return uint32(number), nil
+}
+
+// errorCode returns the HTTP status code of an error
+func errorCode(err error) int {
+	switch {
+	case errors.Is(err, indexer.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, bap.ErrMissingField):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeError will write an error response
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, &Response{Code: code, Message: message, Status: StatusError})
+}
+
+// writeJSON will write a JSON response
+func writeJSON(w http.ResponseWriter, code int, response *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,197 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	"github.com/bitcoinschema/go-bap/internal/testutil/testindex"
+)
+
+// testServer returns a server over an identity (rotated at 103), its profile and an attestation
+func testServer(t testing.TB) *httptest.Server {
+	idx := testindex.New(t, testutil.IDKey, testutil.AttestorID)
+	attestorKey, _ := testutil.SigningKey(t, testutil.AttestorKey, 0)
+	rootKey, _ := testutil.SigningKey(t, testutil.IdentityKey, 0)
+	testindex.Add(t, idx, 102,
+		testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.IDKey, "name", "John", "0"),
+		testutil.RotationTx(t, testutil.IdentityKey, testutil.IDKey, 1),                                     // 103
+		testutil.SignedTx(t, rootKey, []byte(bap.ALIAS), []byte(testutil.IDKey), []byte(`{"name":"John"}`)), // 104, invalid (old address)
+	)
+	if _, err := idx.AddClaim(testutil.IDKey, "name", "John", testindex.Secret); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	server := httptest.NewServer(New(idx.Store()))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testRequest will call an endpoint with a POST (JSON body) or GET (query) request
+func testRequest(t testing.TB, server *httptest.Server, method, path, body string, result interface{}) *Response {
+	var (
+		err      error
+		response *http.Response
+	)
+	if method == http.MethodGet {
+		response, err = http.Get(server.URL + Prefix + path + "?" + body)
+	} else {
+		response, err = http.Post(server.URL+Prefix+path, "application/json", strings.NewReader(body))
+	}
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	decoded := &Response{Result: result}
+	if err = json.NewDecoder(response.Body).Decode(decoded); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if decoded.Status == StatusError && decoded.Code != response.StatusCode {
+		t.Fatalf("expected code [%d] but got [%d]", response.StatusCode, decoded.Code)
+	}
+	return decoded
+}
+
+// TestServer_Identity will test the identity endpoints
+func TestServer_Identity(t *testing.T) {
+	t.Parallel()
+
+	server := testServer(t)
+	_, rootAddress := testutil.SigningKey(t, testutil.IdentityKey, 0)
+	_, rotatedAddress := testutil.SigningKey(t, testutil.IdentityKey, 1)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			method          string
+			path            string
+			body            string
+			expectedStatus  string
+			expectedCode    int
+			expectedAddress string
+			expectedValid   bool
+		}{
+			{http.MethodPost, "/identity/get", `{"idKey":"` + testutil.IDKey + `"}`, StatusOK, 0, rotatedAddress, true},
+			{http.MethodGet, "/identity/get", "idKey=" + testutil.IDKey, StatusOK, 0, rotatedAddress, true},
+			{http.MethodPost, "/identity/get", `{"idKey":"unknown"}`, StatusError, http.StatusNotFound, "", false},
+			{http.MethodPost, "/identity/get", `{}`, StatusError, http.StatusBadRequest, "", false},
+			{http.MethodPost, "/identity/get", `{"idKey":`, StatusError, http.StatusBadRequest, "", false},
+			{http.MethodPost, "/identity/get", `{"idKey":"` + strings.Repeat("a", MaxBodySize) + `"}`, StatusError, http.StatusRequestEntityTooLarge, "", false},
+			{http.MethodPost, "/identity/validByAddress", `{"address":"` + rootAddress + `"}`, StatusOK, 0, rotatedAddress, false},
+			{http.MethodPost, "/identity/validByAddress", `{"address":"` + rotatedAddress + `"}`, StatusOK, 0, rotatedAddress, true},
+			{http.MethodPost, "/identity/validByAddress", `{"address":"` + rootAddress + `","block":102}`, StatusOK, 0, rotatedAddress, true},
+			{http.MethodPost, "/identity/validByAddress", `{"address":"` + rotatedAddress + `","block":102}`, StatusOK, 0, rotatedAddress, false},
+			{http.MethodGet, "/identity/validByAddress", "address=" + rootAddress + "&timestamp=1600000102", StatusOK, 0, rotatedAddress, true},
+			{http.MethodGet, "/identity/validByAddress", "address=" + rootAddress + "&timestamp=1600000103", StatusOK, 0, rotatedAddress, false},
+			{http.MethodGet, "/identity/validByAddress", "address=" + rootAddress + "&block=abc", StatusError, http.StatusBadRequest, "", false},
+			{http.MethodPost, "/identity/validByAddress", `{"address":"unknown"}`, StatusError, http.StatusNotFound, "", false},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		identity := new(Identity)
+		response := testRequest(t, server, test.method, test.path, test.body, identity)
+		if response.Status != test.expectedStatus {
+			t.Errorf("%s Failed: [%s %s] inputted and expected status [%s] but got [%s] (%s)", t.Name(), test.path, test.body, test.expectedStatus, response.Status, response.Message)
+		} else if response.Code != test.expectedCode {
+			t.Errorf("%s Failed: [%s %s] inputted and expected code [%d] but got [%d]", t.Name(), test.path, test.body, test.expectedCode, response.Code)
+		} else if identity.CurrentAddress != test.expectedAddress {
+			t.Errorf("%s Failed: [%s %s] inputted and expected address [%s] but got [%s]", t.Name(), test.path, test.body, test.expectedAddress, identity.CurrentAddress)
+		} else if identity.Valid != test.expectedValid {
+			t.Errorf("%s Failed: [%s %s] inputted and expected valid [%t] but got [%t]", t.Name(), test.path, test.body, test.expectedValid, identity.Valid)
+		} else if response.Status == StatusOK && (identity.IDKey != testutil.IDKey || identity.RootAddress != rootAddress || len(identity.Addresses) != 2 || identity.Block != 100) {
+			t.Errorf("%s Failed: [%s %s] inputted and unexpected identity: %+v", t.Name(), test.path, test.body, identity)
+		}
+	}
+}
+
+// TestServer_Attestations will test the attestation endpoints
+func TestServer_Attestations(t *testing.T) {
+	t.Parallel()
+
+	server := testServer(t)
+	hash := bap.AttestationHash(testutil.IDKey, "name", "John", testindex.Secret)
+	urnHash := fmt.Sprintf("%x", hash)
+
+	// Attestations of a URN hash
+	result := new(AttestationResult)
+	if response := testRequest(t, server, http.MethodPost, "/attestation/get", `{"hash":"`+urnHash+`"}`, result); response.Status != StatusOK {
+		t.Fatalf("expected status OK but got: %s", response.Message)
+	} else if result.Hash != urnHash || len(result.Attestations) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	} else if a := result.Attestations[0]; a.IDKey != testutil.AttestorID || a.Subject != testutil.IDKey || a.Type != string(bap.ATTEST) || a.Block != 102 {
+		t.Fatalf("unexpected attestation: %+v", a)
+	}
+	if response := testRequest(t, server, http.MethodPost, "/attestation/get", `{}`, nil); response.Code != http.StatusBadRequest {
+		t.Fatalf("expected bad request but got: %+v", response)
+	}
+
+	// Attestations of an identity
+	var attestations []*Attestation
+	if response := testRequest(t, server, http.MethodGet, "/identity/getAttestations", "idKey="+testutil.IDKey, &attestations); response.Status != StatusOK {
+		t.Fatalf("expected status OK but got: %s", response.Message)
+	} else if len(attestations) != 1 || attestations[0].Hash != urnHash {
+		t.Fatalf("unexpected attestations: %+v", attestations)
+	}
+
+	// Identities attested by the attestor
+	var identities []*Identity
+	if response := testRequest(t, server, http.MethodPost, "/attestation/getByAttestor", `{"idKey":"`+testutil.AttestorID+`","limit":10}`, &identities); response.Status != StatusOK {
+		t.Fatalf("expected status OK but got: %s", response.Message)
+	} else if len(identities) != 1 || identities[0].IDKey != testutil.IDKey {
+		t.Fatalf("unexpected identities: %+v", identities)
+	}
+	if response := testRequest(t, server, http.MethodPost, "/attestation/getByAttestor", `{}`, nil); response.Code != http.StatusBadRequest {
+		t.Fatalf("expected bad request but got: %+v", response)
+	}
+}
+
+// TestServer_ServeHTTP will test the CORS and method handling
+func TestServer_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	handler := New(indexer.NewMemoryStore())
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			method       string
+			path         string
+			expectedCode int
+		}{
+			{http.MethodOptions, Prefix + "/identity/get", http.StatusNoContent},
+			{http.MethodDelete, Prefix + "/identity/get", http.StatusMethodNotAllowed},
+			{http.MethodGet, Prefix + "/unknown", http.StatusNotFound},
+			{http.MethodGet, Prefix + "/identity/get?idKey=" + testutil.IDKey, http.StatusNotFound},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(test.method, test.path, nil))
+		if recorder.Code != test.expectedCode {
+			t.Errorf("%s Failed: [%s %s] inputted and expected code [%d] but got [%d]", t.Name(), test.method, test.path, test.expectedCode, recorder.Code)
+		} else if recorder.Header().Get("Access-Control-Allow-Origin") != "*" {
+			t.Errorf("%s Failed: [%s %s] inputted and expected CORS headers", t.Name(), test.method, test.path)
+		}
+	}
+}
+
+// ExampleNew example using New()
+func ExampleNew() {
+	handler := New(indexer.NewMemoryStore())
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, Prefix+"/identity/get", strings.NewReader(`{"idKey":"unknown"}`)))
+	fmt.Print(recorder.Body.String())
+	// Output:{"code":404,"message":"not found","status":"ERROR"}
+}
@@ -0,0 +1,165 @@
+package bap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+
+	"github.com/bitcoinschema/go-aip"
+	"github.com/bitcoinschema/go-bpu"
+	base58 "github.com/bsv-blockchain/go-sdk/compat/base58"
+	hash "github.com/bsv-blockchain/go-sdk/primitives/hash"
+)
+
+const (
+	// compactSignatureLength is the length of a raw Bitcoin Signed Message signature
+	compactSignatureLength = 65
+
+	// opReturn is the OP_RETURN byte prepended to all AIP signed data
+	opReturn = "j"
+)
+
+// Signer is the AIP signature that follows a BAP record
+type Signer struct {
+	Address   string        `json:"address"`         // Signing address (or pubkey for paymail)
+	Algorithm aip.Algorithm `json:"algorithm"`       // AIP algorithm
+	Signature string        `json:"signature"`       // Base64 signature
+	Valid     bool          `json:"valid"`           // True if the signature validated
+	Error     string        `json:"error,omitempty"` // Validation error, if any
+}
+
+// SignedBap is a BAP record together with the AIP signature that signed it
+type SignedBap struct {
+	*Bap
+	Signer *Signer `json:"signer,omitempty"` // Nil if the record is not signed
+}
+
+// NewSignedFromTapes will create a SignedBap for every BAP record in the tapes of
+// a single output, in order
+//
+// The AIP signature of a record is the first AIP tape after it, and it signs every
+// push before it in the output (the raw base64/hex payloads are used, so binary
+// fields validate correctly)
+func NewSignedFromTapes(tapes []bpu.Tape) (records []*SignedBap, err error) {
+	for index := range tapes {
+		if !tapeHasPrefix(&tapes[index], Prefix) {
+			continue
+		}
+		var b *Bap
+		if b, err = NewFromTape(&tapes[index]); err != nil {
+			return nil, err
+		}
+		record := &SignedBap{Bap: b}
+		for aipIndex := index + 1; aipIndex < len(tapes); aipIndex++ {
+			if tapeHasPrefix(&tapes[aipIndex], aip.Prefix) {
+				record.Signer = verifySigner(tapes[:aipIndex], &tapes[aipIndex])
+				break
+			} else if tapeHasPrefix(&tapes[aipIndex], Prefix) {
+				break
+			}
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, ErrNoRecord
+	}
+	return
+}
+
+// NewSignedFromOutputs will create a SignedBap for every BAP record in a []bob.Output,
+// in output order
+func NewSignedFromOutputs(outputs []bpu.Output) (records []*SignedBap, err error) {
+	for _, output := range outputs {
+		found, tapeErr := NewSignedFromTapes(output.Tape)
+		if errors.Is(tapeErr, ErrNoRecord) {
+			continue
+		} else if tapeErr != nil {
+			return nil, tapeErr
+		}
+		records = append(records, found...)
+	}
+	if len(records) == 0 {
+		return nil, ErrNoRecord
+	}
+	return
+}
+
+// IdentityKeyFromAddress returns the BAP identity key of a root address:
+// base58(ripemd160(sha256(rootAddress)))
+func IdentityKeyFromAddress(rootAddress string) string {
+	return base58.Encode(hash.Ripemd160(hash.Sha256([]byte(rootAddress))))
+}
+
+// verifySigner will read the AIP tape and validate it against the preceding tapes
+func verifySigner(signedTapes []bpu.Tape, aipTape *bpu.Tape) *Signer {
+	signer := new(Signer)
+
+	// Locate the AIP fields
+	cells := aipTape.Cell
+	for index := range cells {
+		if cellHasPrefix(&cells[index], aip.Prefix) {
+			cells = cells[index:]
+			break
+		}
+	}
+	if len(cells) < 4 {
+		signer.Error = "missing AIP fields"
+		return signer
+	}
+	algorithm, _ := cellString(&cells[1])
+	signer.Algorithm = aip.Algorithm(algorithm)
+	signer.Address, _ = cellString(&cells[2])
+
+	// Signatures are pushed either as base64 text or as the raw 65 byte compact signature
+	if raw, ok := cellBytes(&cells[3]); ok && len(raw) == compactSignatureLength {
+		signer.Signature = base64.StdEncoding.EncodeToString(raw)
+	} else {
+		signer.Signature = string(raw)
+	}
+
+	// Rebuild the signed data: OP_RETURN, then every push with a pipe between protocols
+	var data bytes.Buffer
+	for _, tape := range signedTapes {
+		wrote := false
+		for index := range tape.Cell {
+			cell := &tape.Cell[index]
+			if cell.Op != nil && (*cell.Op == 0 || *cell.Op > 0x4e) {
+				continue
+			}
+			if raw, ok := cellBytes(cell); ok {
+				data.Write(raw)
+				wrote = true
+			}
+		}
+		if wrote {
+			data.WriteString(pipe)
+		}
+	}
+
+	// Validate with AIP
+	a := &aip.Aip{
+		Algorithm:                 signer.Algorithm,
+		AlgorithmSigningComponent: signer.Address,
+		Data:                      []string{opReturn, data.String()},
+		Signature:                 signer.Signature,
+	}
+	valid, err := a.Validate()
+	signer.Valid = valid && err == nil
+	if err != nil {
+		signer.Error = err.Error()
+	}
+	if signer.Algorithm == aip.Paymail {
+		signer.Address = a.AlgorithmSigningComponent
+	}
+	return signer
+}
+
+// tapeHasPrefix returns true if any cell of the tape holds the given prefix
+func tapeHasPrefix(tape *bpu.Tape, prefix string) bool {
+	for index := range tape.Cell {
+		if cellHasPrefix(&tape.Cell[index], prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bob"
+)
+
+// TestNewSignedFromOutputs will test the method NewSignedFromOutputs()
+func TestNewSignedFromOutputs(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			fixture         string
+			expectedCount   int
+			expectedAddress string
+			expectedValid   bool
+		}{
+			{"id", 1, testAddress, true},
+			{"attest_binary_hash", 1, "1AFc9feffQmxT61iEftzkaYvWTgLCyU6j", true},
+			{"attest_no_string_cells", 1, "1AFc9feffQmxT61iEftzkaYvWTgLCyU6j", true},
+			{"attest_batch", 3, "1AFc9feffQmxT61iEftzkaYvWTgLCyU6j", true},
+			{"attest_hex_hash", 1, "134a6TXxzgQ9Az3w8BcvgdZyA5UqRL89da", true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		records, err := NewSignedFromOutputs(loadBobFixture(t, test.fixture).Out)
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.fixture, err.Error())
+			continue
+		} else if len(records) != test.expectedCount {
+			t.Errorf("%s Failed: [%s] inputted and expected [%d] records but got [%d]", t.Name(), test.fixture, test.expectedCount, len(records))
+			continue
+		}
+		for _, record := range records {
+			if record.Signer == nil {
+				t.Errorf("%s Failed: [%s] inputted and signer was nil", t.Name(), test.fixture)
+			} else if record.Signer.Address != test.expectedAddress {
+				t.Errorf("%s Failed: [%s] inputted and expected [%s] but got [%s]", t.Name(), test.fixture, test.expectedAddress, record.Signer.Address)
+			} else if record.Signer.Valid != test.expectedValid {
+				t.Errorf("%s Failed: [%s] inputted and expected valid [%t] but got [%t] %s", t.Name(), test.fixture, test.expectedValid, record.Signer.Valid, record.Signer.Error)
+			}
+		}
+	}
+}
+
+// TestNewSignedFromTapes will test the method NewSignedFromTapes()
+func TestNewSignedFromTapes(t *testing.T) {
+	t.Parallel()
+
+	// Unsigned record
+	records, err := NewSignedFromTapes(loadBobFixture(t, "revoke").Out[0].Tape)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if records[0].Signer != nil {
+		t.Fatalf("signer should be nil")
+	} else if records[0].Type != REVOKE || records[0].Sequence != 1 {
+		t.Fatalf("unexpected record: %+v", records[0].Bap)
+	}
+
+	// Tampered binary hash
+	bobTx := loadBobFixture(t, "attest_no_string_cells")
+	tampered := "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+	bobTx.Out[0].Tape[1].Cell[2].B = &tampered
+	if records, err = NewSignedFromTapes(bobTx.Out[0].Tape); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if records[0].Signer.Valid {
+		t.Fatalf("signature should not be valid")
+	}
+
+	// No records
+	if _, err = NewSignedFromTapes(bobTx.Out[0].Tape[:1]); !errors.Is(err, ErrNoRecord) {
+		t.Fatalf("expected ErrNoRecord but got: %v", err)
+	}
+}
+
+// TestIdentityKeyFromAddress will test the method IdentityKeyFromAddress()
+func TestIdentityKeyFromAddress(t *testing.T) {
+	t.Parallel()
+
+	key := IdentityKeyFromAddress(testAddress)
+	if err := ValidateIDKey(key); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if key == IdentityKeyFromAddress(testTestnetAddress) {
+		t.Fatalf("identity keys should differ")
+	}
+}
+
+// ExampleNewSignedFromOutputs example using NewSignedFromOutputs()
+func ExampleNewSignedFromOutputs() {
+	tx, err := CreateIdentity(privateKey, idKey, 0)
+	if err != nil {
+		fmt.Printf("failed to create identity: %s", err.Error())
+		return
+	}
+	var bobTx *bob.Tx
+	if bobTx, err = bob.NewFromTx(tx); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	var records []*SignedBap
+	if records, err = NewSignedFromOutputs(bobTx.Out); err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("%s signed by %s: %t", records[0].Type, records[0].Signer.Address, records[0].Signer.Valid)
+	// Output:ID signed by 1A9VQqdNJrvVF73nf879n2fES6cd5nWNid: true
+}
@@ -0,0 +1,135 @@
+// Package spv verifies BAP transactions offline with SPV: transactions wrapped in BEEF
+// (BRC-62/95/96) are checked against their merkle proofs and a ChainTracker, like Headers
+// which is backed by a local block headers file, and their BAP records are returned with
+// the confirmed block height and time
+package spv
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bob"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+	"github.com/bsv-blockchain/go-sdk/transaction/chaintracker"
+)
+
+// SPV errors
+var (
+	ErrInvalidBEEF  = errors.New("invalid BEEF")
+	ErrInvalidProof = errors.New("merkle proof does not match the chain")
+	ErrNotMined     = errors.New("transaction has no merkle proof")
+)
+
+// Confirmation is the block a transaction was mined in
+type Confirmation struct {
+	BlockHash string `json:"block_hash,omitempty"` // Set if the ChainTracker is a HeaderReader
+	Height    uint32 `json:"height"`
+	Time      uint32 `json:"time,omitempty"` // Set if the ChainTracker is a HeaderReader
+}
+
+// Record is a signed BAP record of a verified transaction
+type Record struct {
+	*bap.SignedBap
+	Confirmation
+	Output int    `json:"output"`
+	TxID   string `json:"txid"`
+}
+
+// VerifiedTx is a transaction proven to be mined, with its BAP records
+type VerifiedTx struct {
+	Confirmation
+	Records []*Record                `json:"records"`
+	Tx      *transaction.Transaction `json:"-"`
+	TxID    string                   `json:"txid"`
+}
+
+// VerifyBEEF reads the subject transaction of a BEEF, verifies its merkle proof with the chain
+// tracker and parses its BAP records
+func VerifyBEEF(beef []byte, tracker chaintracker.ChainTracker) (*VerifiedTx, error) {
+	tx, err := parseBEEF(beef)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyTx(tx, tracker)
+}
+
+// VerifyBEEFHex reads a hex encoded BEEF, see VerifyBEEF
+func VerifyBEEFHex(beefHex string, tracker chaintracker.ChainTracker) (*VerifiedTx, error) {
+	beef, err := hex.DecodeString(beefHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBEEF, err)
+	}
+	return VerifyBEEF(beef, tracker)
+}
+
+// VerifyTx verifies the merkle proof of a transaction with the chain tracker and parses its BAP records
+func VerifyTx(tx *transaction.Transaction, tracker chaintracker.ChainTracker) (*VerifiedTx, error) {
+	if tx == nil {
+		return nil, &bap.MissingFieldError{Field: "transaction"}
+	} else if tracker == nil {
+		return nil, &bap.MissingFieldError{Field: "tracker"}
+	} else if tx.MerklePath == nil {
+		return nil, ErrNotMined
+	}
+
+	txid := tx.TxID()
+	valid, err := tx.MerklePath.Verify(txid, tracker)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	} else if !valid {
+		return nil, ErrInvalidProof
+	}
+
+	verified := &VerifiedTx{
+		Confirmation: Confirmation{Height: tx.MerklePath.BlockHeight},
+		Tx:           tx,
+		TxID:         txid.String(),
+	}
+	if reader, ok := tracker.(HeaderReader); ok {
+		var header *BlockHeader
+		if header, err = reader.Header(verified.Height); err != nil {
+			return nil, err
+		}
+		verified.BlockHash = header.Hash.String()
+		verified.Time = header.Time
+	}
+
+	var bobTx *bob.Tx
+	if bobTx, err = bob.NewFromTx(tx); err != nil {
+		return nil, err
+	}
+	for output := range bobTx.Out {
+		var records []*bap.SignedBap
+		if records, err = bap.NewSignedFromTapes(bobTx.Out[output].Tape); errors.Is(err, bap.ErrNoRecord) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			verified.Records = append(verified.Records, &Record{
+				SignedBap:    record,
+				Confirmation: verified.Confirmation,
+				Output:       output,
+				TxID:         verified.TxID,
+			})
+		}
+	}
+	return verified, nil
+}
+
+// parseBEEF reads the subject transaction of a BEEF, malformed input is reported as ErrInvalidBEEF
+func parseBEEF(beef []byte) (tx *transaction.Transaction, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tx, err = nil, fmt.Errorf("%w: %v", ErrInvalidBEEF, r)
+		}
+	}()
+	if tx, err = transaction.NewTransactionFromBEEF(beef); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidBEEF, err)
+	} else if tx == nil {
+		return nil, fmt.Errorf("%w: no subject transaction", ErrInvalidBEEF)
+	}
+	return tx, nil
+}
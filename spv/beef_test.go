@@ -0,0 +1,157 @@
+package spv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// Example keys
+const (
+	testAttestorKey = "xprv9s21ZrQH143K3PZSwbEeXEYq74EbnfMngzAiMCZcfjzyRpUvt2vQJnaHRTZjeuEmLXeN6BzYRoFsEckfobxE9XaRzeLGfQoxzPzTRyRb6oE"
+	testIDKey       = "8bafa4ca97d770276253585cb2a49da1775ec7aeed3178e346c8c1b55eaf5ca2"
+)
+
+// testMinedTx returns an attestation tx mined at height 101 (second of two txs in the block)
+// and the headers of blocks 100 and 101
+func testMinedTx(t testing.TB) (*transaction.Transaction, *Headers) {
+	hdKey, err := hd.NewKeyFromString(testAttestorKey)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var child *hd.ExtendedKey
+	if child, err = hdKey.DeriveChildFromPath("0/0"); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	key, _ := child.ECPrivKey()
+	var tx *transaction.Transaction
+	if tx, err = bap.CreateAttestation(testIDKey, key, "name", "John", "secret"); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	isTxid := true
+	coinbase := chainhash.HashH([]byte("coinbase"))
+	tx.MerklePath = transaction.NewMerklePath(101, [][]*transaction.PathElement{{
+		{Offset: 0, Hash: &coinbase},
+		{Offset: 1, Hash: tx.TxID(), Txid: &isTxid},
+	}})
+	var root *chainhash.Hash
+	if root, err = tx.MerklePath.ComputeRoot(tx.TxID()); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	var headers *Headers
+	if headers, err = NewHeaders(bytes.NewReader(testChain(chainhash.HashH([]byte("a")), *root)), 100); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return tx, headers
+}
+
+// testRootTracker is a ChainTracker without headers
+type testRootTracker struct {
+	headers *Headers
+}
+
+// IsValidRootForHeight implements chaintracker.ChainTracker
+func (r *testRootTracker) IsValidRootForHeight(root *chainhash.Hash, height uint32) (bool, error) {
+	return r.headers.IsValidRootForHeight(root, height)
+}
+
+// TestVerifyBEEF will test the method VerifyBEEF()
+func TestVerifyBEEF(t *testing.T) {
+	t.Parallel()
+
+	tx, headers := testMinedTx(t)
+	beef, err := tx.BEEF()
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	var verified *VerifiedTx
+	if verified, err = VerifyBEEF(beef, headers); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if verified.TxID != tx.TxID().String() || verified.Height != 101 || verified.Time != 1600000600 || len(verified.BlockHash) != 64 {
+		t.Fatalf("unexpected confirmation: %+v", verified.Confirmation)
+	} else if len(verified.Records) != 1 {
+		t.Fatalf("expected 1 record but got %d", len(verified.Records))
+	}
+	record := verified.Records[0]
+	if record.Type != bap.ATTEST || !record.Signer.Valid || record.Height != 101 || record.Time != 1600000600 || record.TxID != verified.TxID {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+
+	// Roots only (no block time)
+	var hexBEEF string
+	if hexBEEF, err = tx.BEEFHex(); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if verified, err = VerifyBEEFHex(hexBEEF, &testRootTracker{headers: headers}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if verified.Height != 101 || verified.Time != 0 || len(verified.BlockHash) != 0 {
+		t.Fatalf("unexpected confirmation: %+v", verified.Confirmation)
+	}
+}
+
+// TestVerifyBEEF_Errors will test the errors of VerifyBEEF() and VerifyTx()
+func TestVerifyBEEF_Errors(t *testing.T) {
+	t.Parallel()
+
+	tx, headers := testMinedTx(t)
+	beef, err := tx.BEEF()
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	otherHeaders, _ := NewHeaders(bytes.NewReader(testChain(chainhash.HashH([]byte("a")), chainhash.HashH([]byte("b")))), 100)
+	shortHeaders, _ := NewHeaders(bytes.NewReader(testChain(chainhash.HashH([]byte("a")))), 100)
+	unmined := transaction.NewTransaction()
+	unminedBEEF, _ := unmined.BEEF()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			beef          []byte
+			tracker       *Headers
+			expectedError error
+		}{
+			{"wrong root", beef, otherHeaders, ErrInvalidProof},
+			{"unknown height", beef, shortHeaders, ErrInvalidProof},
+			{"not mined", unminedBEEF, headers, ErrNotMined},
+			{"empty", nil, headers, ErrInvalidBEEF},
+			{"truncated", beef[:len(beef)/2], headers, ErrInvalidBEEF},
+			{"not beef", []byte("not a beef at all"), headers, ErrInvalidBEEF},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err = VerifyBEEF(test.beef, test.tracker); !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		}
+	}
+
+	if _, err = VerifyBEEFHex("zz", headers); !errors.Is(err, ErrInvalidBEEF) {
+		t.Fatalf("expected ErrInvalidBEEF but got: %v", err)
+	} else if _, err = VerifyTx(nil, headers); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	} else if _, err = VerifyTx(tx, nil); !errors.Is(err, bap.ErrMissingField) {
+		t.Fatalf("expected ErrMissingField but got: %v", err)
+	}
+}
+
+// ExampleVerifyBEEF example using VerifyBEEF()
+func ExampleVerifyBEEF() {
+	headers, err := NewHeaders(bytes.NewReader(nil), 0)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	_, err = VerifyBEEF([]byte("not a beef"), headers)
+	fmt.Printf("invalid: %t", errors.Is(err, ErrInvalidBEEF))
+	// Output:invalid: true
+}
@@ -0,0 +1,136 @@
+package spv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+)
+
+// HeaderSize is the size of a serialized block header
+const HeaderSize = 80
+
+// ErrInvalidHeaders is returned when a headers file is truncated or its headers do not link up
+var ErrInvalidHeaders = errors.New("invalid block headers")
+
+// BlockHeader is a block header at a height of the chain
+type BlockHeader struct {
+	Bits       uint32         `json:"bits"`
+	Hash       chainhash.Hash `json:"hash"`
+	Height     uint32         `json:"height"`
+	MerkleRoot chainhash.Hash `json:"merkle_root"`
+	Nonce      uint32         `json:"nonce"`
+	PrevHash   chainhash.Hash `json:"prev_hash"`
+	Time       uint32         `json:"time"`
+	Version    uint32         `json:"version"`
+}
+
+// HeaderReader returns the block header at a height, a ChainTracker implementing it lets
+// verification attach the block hash and time to records
+type HeaderReader interface {
+	Header(height uint32) (*BlockHeader, error)
+}
+
+// Headers is a ChainTracker over a chain of block headers kept in memory
+type Headers struct {
+	headers     []*BlockHeader
+	mu          sync.RWMutex
+	startHeight uint32
+}
+
+// NewHeaders reads serialized 80 byte headers, the first one being at startHeight
+func NewHeaders(r io.Reader, startHeight uint32) (*Headers, error) {
+	h := &Headers{startHeight: startHeight}
+	reader := bufio.NewReader(r)
+	raw := make([]byte, HeaderSize)
+	for {
+		if _, err := io.ReadFull(reader, raw); errors.Is(err, io.EOF) {
+			return h, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: truncated header at height %d", ErrInvalidHeaders, h.next())
+		}
+		if err := h.Add(raw); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// LoadHeaders reads a headers file of serialized 80 byte headers, the first one being at startHeight
+func LoadHeaders(path string, startHeight uint32) (*Headers, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	return NewHeaders(file, startHeight)
+}
+
+// Add appends a serialized header, it must link to the current tip
+func (h *Headers) Add(raw []byte) error {
+	if len(raw) != HeaderSize {
+		return fmt.Errorf("%w: header is %d bytes", ErrInvalidHeaders, len(raw))
+	}
+	header := &BlockHeader{
+		Bits:    binary.LittleEndian.Uint32(raw[72:76]),
+		Hash:    chainhash.DoubleHashH(raw),
+		Nonce:   binary.LittleEndian.Uint32(raw[76:80]),
+		Time:    binary.LittleEndian.Uint32(raw[68:72]),
+		Version: binary.LittleEndian.Uint32(raw[0:4]),
+	}
+	copy(header.PrevHash[:], raw[4:36])
+	copy(header.MerkleRoot[:], raw[36:68])
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.headers) > 0 && !header.PrevHash.IsEqual(&h.headers[len(h.headers)-1].Hash) {
+		return fmt.Errorf("%w: header %s does not link to the tip", ErrInvalidHeaders, header.Hash)
+	}
+	header.Height = h.next()
+	h.headers = append(h.headers, header)
+	return nil
+}
+
+// Header returns the block header at a height
+func (h *Headers) Header(height uint32) (*BlockHeader, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if height < h.startHeight || height-h.startHeight >= uint32(len(h.headers)) {
+		return nil, fmt.Errorf("no header at height %d", height)
+	}
+	header := *h.headers[height-h.startHeight]
+	return &header, nil
+}
+
+// Height returns the height of the tip, or false if there are no headers
+func (h *Headers) Height() (uint32, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.headers) == 0 {
+		return 0, false
+	}
+	return h.next() - 1, true
+}
+
+// IsValidRootForHeight implements chaintracker.ChainTracker
+func (h *Headers) IsValidRootForHeight(root *chainhash.Hash, height uint32) (bool, error) {
+	if root == nil {
+		return false, nil
+	}
+	header, err := h.Header(height)
+	if err != nil {
+		return false, err
+	}
+	return header.MerkleRoot.IsEqual(root), nil
+}
+
+// next returns the height of the next header
+func (h *Headers) next() uint32 {
+	return h.startHeight + uint32(len(h.headers))
+}
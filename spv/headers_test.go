@@ -0,0 +1,119 @@
+package spv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsv-blockchain/go-sdk/chainhash"
+)
+
+// testHeader returns a serialized header linking to prevHash
+func testHeader(prevHash, merkleRoot chainhash.Hash, time uint32) []byte {
+	raw := make([]byte, HeaderSize)
+	binary.LittleEndian.PutUint32(raw[0:4], 1)
+	copy(raw[4:36], prevHash[:])
+	copy(raw[36:68], merkleRoot[:])
+	binary.LittleEndian.PutUint32(raw[68:72], time)
+	binary.LittleEndian.PutUint32(raw[72:76], 0x1d00ffff)
+	return raw
+}
+
+// testChain returns serialized headers, the merkle root of each is given
+func testChain(roots ...chainhash.Hash) []byte {
+	var (
+		chain bytes.Buffer
+		prev  chainhash.Hash
+	)
+	for index, root := range roots {
+		raw := testHeader(prev, root, 1600000000+uint32(index)*600)
+		prev = chainhash.DoubleHashH(raw)
+		chain.Write(raw)
+	}
+	return chain.Bytes()
+}
+
+// TestNewHeaders will test the method NewHeaders()
+func TestNewHeaders(t *testing.T) {
+	t.Parallel()
+
+	roots := []chainhash.Hash{chainhash.HashH([]byte("a")), chainhash.HashH([]byte("b")), chainhash.HashH([]byte("c"))}
+	chain := testChain(roots...)
+	broken := append(append([]byte{}, chain[:HeaderSize]...), chain[2*HeaderSize:]...)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name           string
+			input          []byte
+			startHeight    uint32
+			expectedHeight uint32
+			expectedTip    bool
+			expectedError  bool
+		}{
+			{"chain", chain, 100, 102, true, false},
+			{"genesis", chain, 0, 2, true, false},
+			{"empty", nil, 100, 0, false, false},
+			{"empty from genesis", nil, 0, 0, false, false},
+			{"truncated", chain[:HeaderSize+10], 100, 0, false, true},
+			{"not linked", broken, 100, 0, false, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		headers, err := NewHeaders(bytes.NewReader(test.input), test.startHeight)
+		if err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		} else if err != nil && !errors.Is(err, ErrInvalidHeaders) {
+			t.Errorf("%s Failed: [%s] inputted and expected ErrInvalidHeaders but got: %s", t.Name(), test.name, err.Error())
+		} else if err != nil {
+			continue
+		}
+		if height, ok := headers.Height(); height != test.expectedHeight || ok != test.expectedTip {
+			t.Errorf("%s Failed: [%s] inputted and expected height [%d %t] but got [%d %t]", t.Name(), test.name, test.expectedHeight, test.expectedTip, height, ok)
+		}
+	}
+}
+
+// TestHeaders_IsValidRootForHeight will test the method IsValidRootForHeight()
+func TestHeaders_IsValidRootForHeight(t *testing.T) {
+	t.Parallel()
+
+	roots := []chainhash.Hash{chainhash.HashH([]byte("a")), chainhash.HashH([]byte("b"))}
+	path := filepath.Join(t.TempDir(), "headers.bin")
+	if err := os.WriteFile(path, testChain(roots...), 0o600); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	headers, err := LoadHeaders(path, 100)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	var valid bool
+	if valid, err = headers.IsValidRootForHeight(&roots[1], 101); err != nil || !valid {
+		t.Fatalf("expected valid root but got: %t %v", valid, err)
+	} else if valid, err = headers.IsValidRootForHeight(&roots[0], 101); err != nil || valid {
+		t.Fatalf("expected invalid root but got: %t %v", valid, err)
+	} else if _, err = headers.IsValidRootForHeight(&roots[0], 99); err == nil {
+		t.Fatalf("error expected for unknown height")
+	} else if _, err = headers.IsValidRootForHeight(&roots[0], 102); err == nil {
+		t.Fatalf("error expected for unknown height")
+	}
+
+	var header *BlockHeader
+	if header, err = headers.Header(101); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if header.Height != 101 || header.Time != 1600000600 || header.PrevHash.String() == header.Hash.String() {
+		t.Fatalf("unexpected header: %+v", header)
+	}
+
+	if _, err = LoadHeaders(filepath.Join(t.TempDir(), "missing.bin"), 0); err == nil {
+		t.Fatalf("error expected for missing file")
+	}
+}
@@ -0,0 +1,144 @@
+package bap
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// AttestationStatus is the state of an attestation
+type AttestationStatus string
+
+// Attestation statuses
+const (
+	StatusActive     AttestationStatus = "active"     // The attestation is the current record
+	StatusExpired    AttestationStatus = "expired"    // The attestation is the current record, but its expiry has passed
+	StatusRevoked    AttestationStatus = "revoked"    // A REVOKE took effect after the attestation
+	StatusSuperseded AttestationStatus = "superseded" // An ATTEST with a higher sequence replaced it
+)
+
+// StatusRecord is an ATTEST or REVOKE record of one URN hash by one attestor, with the block
+// height it was mined at (0 is unconfirmed)
+type StatusRecord struct {
+	Expiry   Expiry          `json:"expiry,omitempty"` // Expiry of an ATTEST
+	Height   uint32          `json:"height"`
+	Sequence uint64          `json:"sequence"`
+	TxID     string          `json:"txid"`
+	Type     AttestationType `json:"type"`
+}
+
+// StatusChange is a record that changed the state of the URN hash
+type StatusChange struct {
+	*StatusRecord
+	Status AttestationStatus `json:"status"` // Status of the URN hash after the change (active or revoked)
+}
+
+// Status is the resolved status of an attestation
+type Status struct {
+	Attestation *StatusRecord     `json:"attestation"` // The attestation the status is about
+	Changes     []*StatusChange   `json:"changes"`     // Every change of the URN hash, in chain order
+	ChangedBy   *StatusRecord     `json:"changed_by"`  // The record that set the status
+	Expiry      Expiry            `json:"expiry,omitempty"`
+	Height      uint32            `json:"height"` // Height at which the status took effect
+	Status      AttestationStatus `json:"status"`
+}
+
+// ResolveStatus applies the sequence rules to the ATTEST and REVOKE records of a URN hash by one
+// attestor, and returns the status of the attestation in txid (or of the latest attestation if
+// txid is empty). ErrNoRecord is returned if there is no such attestation.
+//
+// Records take effect in chain order. A record only takes effect if its sequence is higher than
+// the sequence of the current record, or equal to it when a REVOKE follows an ATTEST, so replayed
+// or stale records are ignored. An attestation stays active until the next record that takes
+// effect: a REVOKE revokes it, an ATTEST supersedes it. An attestation that never took effect is
+// superseded at its own height. Expiry is not checked, see CheckExpiry.
+func ResolveStatus(records []*StatusRecord, txid string) (*Status, error) {
+	ordered := make([]*StatusRecord, 0, len(records))
+	for _, record := range records {
+		if record != nil && (record.Type == ATTEST || record.Type == REVOKE) {
+			ordered = append(ordered, record)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return chainHeight(ordered[i].Height) < chainHeight(ordered[j].Height)
+	})
+
+	var (
+		changes []*StatusChange
+		current *StatusRecord
+	)
+	for _, record := range ordered {
+		if current != nil && (record.Sequence < current.Sequence ||
+			(record.Sequence == current.Sequence && (record.Type == current.Type || record.Type == ATTEST))) {
+			continue
+		}
+		current = record
+		status := StatusActive
+		if record.Type == REVOKE {
+			status = StatusRevoked
+		}
+		changes = append(changes, &StatusChange{StatusRecord: record, Status: status})
+	}
+
+	// The attestation the status is about (the latest one that took effect if no txid is given)
+	var attestation *StatusRecord
+	if len(txid) == 0 {
+		for _, change := range changes {
+			if change.Type == ATTEST {
+				attestation = change.StatusRecord
+			}
+		}
+	}
+	for _, record := range ordered {
+		if attestation == nil && record.Type == ATTEST && (len(txid) == 0 || record.TxID == txid) {
+			attestation = record
+		}
+	}
+	if attestation == nil {
+		return nil, ErrNoRecord
+	}
+
+	result := &Status{
+		Attestation: attestation,
+		Changes:     changes,
+		ChangedBy:   attestation,
+		Expiry:      attestation.Expiry,
+		Height:      attestation.Height,
+		Status:      StatusSuperseded,
+	}
+	for index, change := range changes {
+		if change.StatusRecord != attestation {
+			continue
+		}
+		result.Status = StatusActive
+		if index+1 < len(changes) {
+			next := changes[index+1]
+			result.ChangedBy, result.Height, result.Status = next.StatusRecord, next.Height, StatusSuperseded
+			if next.Type == REVOKE {
+				result.Status = StatusRevoked
+			}
+		}
+		break
+	}
+	return result, nil
+}
+
+// CheckExpiry marks an active attestation as expired if its expiry has passed at the chain
+// height and time. Height is set to the expiry height, or to the given height for a timestamp expiry.
+func (s *Status) CheckExpiry(height uint32, now time.Time) {
+	if s.Status != StatusActive || !s.Expiry.Expired(height, now) {
+		return
+	}
+	s.Status, s.Height = StatusExpired, height
+	if s.Expiry.IsHeight() {
+		s.Height = uint32(s.Expiry)
+	}
+}
+
+// chainHeight orders unconfirmed records (height 0) after every mined record
+func chainHeight(height uint32) uint32 {
+	if height == 0 {
+		return math.MaxUint32
+	}
+	return height
+}
@@ -0,0 +1,203 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testStatusRecord returns a status record
+func testStatusRecord(txid string, recordType AttestationType, sequence uint64, height uint32) *StatusRecord {
+	return &StatusRecord{Height: height, Sequence: sequence, TxID: txid, Type: recordType}
+}
+
+// TestResolveStatus will test the method ResolveStatus()
+func TestResolveStatus(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name            string
+			records         []*StatusRecord
+			txid            string
+			expectedStatus  AttestationStatus
+			expectedHeight  uint32
+			expectedChanged string
+			expectedError   error
+		}{
+			{"attest only", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 0, 100),
+			}, "a", StatusActive, 100, "a", nil},
+			{"attest then revoke", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 0, 100),
+				testStatusRecord("r", REVOKE, 1, 110),
+			}, "a", StatusRevoked, 110, "r", nil},
+			{"revoke with the same sequence", []*StatusRecord{
+				testStatusRecord("r", REVOKE, 0, 110),
+				testStatusRecord("a", ATTEST, 0, 100),
+			}, "a", StatusRevoked, 110, "r", nil},
+			{"stale revoke is ignored", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 2, 100),
+				testStatusRecord("r", REVOKE, 1, 110),
+			}, "a", StatusActive, 100, "a", nil},
+			{"replayed attest after revoke is ignored", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 1, 100),
+				testStatusRecord("r", REVOKE, 1, 110),
+				testStatusRecord("b", ATTEST, 1, 120),
+			}, "", StatusRevoked, 110, "r", nil},
+			{"higher sequence attest supersedes", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 0, 100),
+				testStatusRecord("b", ATTEST, 1, 120),
+			}, "a", StatusSuperseded, 120, "b", nil},
+			{"superseding attest is active", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 0, 100),
+				testStatusRecord("b", ATTEST, 1, 120),
+			}, "b", StatusActive, 120, "b", nil},
+			{"latest attestation", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 0, 100),
+				testStatusRecord("r", REVOKE, 1, 110),
+				testStatusRecord("b", ATTEST, 2, 120),
+			}, "", StatusActive, 120, "b", nil},
+			{"re-attested after revoke", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 0, 100),
+				testStatusRecord("r", REVOKE, 1, 110),
+				testStatusRecord("b", ATTEST, 2, 120),
+			}, "a", StatusRevoked, 110, "r", nil},
+			{"attest that never took effect", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 3, 100),
+				testStatusRecord("b", ATTEST, 1, 120),
+			}, "b", StatusSuperseded, 120, "b", nil},
+			{"unconfirmed revoke is last", []*StatusRecord{
+				testStatusRecord("r", REVOKE, 1, 0),
+				testStatusRecord("a", ATTEST, 0, 100),
+			}, "a", StatusRevoked, 0, "r", nil},
+			{"other types are ignored", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 0, 100),
+				testStatusRecord("x", ALIAS, 5, 110),
+				nil,
+			}, "a", StatusActive, 100, "a", nil},
+			{"unknown txid", []*StatusRecord{
+				testStatusRecord("a", ATTEST, 0, 100),
+			}, "b", "", 0, "", ErrNoRecord},
+			{"only a revoke", []*StatusRecord{
+				testStatusRecord("r", REVOKE, 0, 100),
+			}, "", "", 0, "", ErrNoRecord},
+			{"no records", nil, "", "", 0, "", ErrNoRecord},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if status, err := ResolveStatus(test.records, test.txid); test.expectedError != nil && !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		} else if test.expectedError == nil && err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if test.expectedError == nil && (status.Status != test.expectedStatus || status.Height != test.expectedHeight || status.ChangedBy.TxID != test.expectedChanged) {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s %d %s] but got [%s %d %s]", t.Name(), test.name,
+				test.expectedStatus, test.expectedHeight, test.expectedChanged, status.Status, status.Height, status.ChangedBy.TxID)
+		}
+	}
+}
+
+// TestResolveStatus_Changes will test the changes returned by ResolveStatus()
+func TestResolveStatus_Changes(t *testing.T) {
+	t.Parallel()
+
+	status, err := ResolveStatus([]*StatusRecord{
+		testStatusRecord("b", ATTEST, 2, 120),
+		testStatusRecord("r", REVOKE, 1, 110),
+		testStatusRecord("s", REVOKE, 0, 115),
+		testStatusRecord("a", ATTEST, 0, 100),
+	}, "")
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	expected := []string{"a:active", "r:revoked", "b:active"}
+	if len(status.Changes) != len(expected) {
+		t.Fatalf("%s Failed: expected %d changes but got %d", t.Name(), len(expected), len(status.Changes))
+	}
+	for index, change := range status.Changes {
+		if got := change.TxID + ":" + string(change.Status); got != expected[index] {
+			t.Errorf("%s Failed: expected change [%s] but got [%s]", t.Name(), expected[index], got)
+		}
+	}
+}
+
+// ExampleResolveStatus example using ResolveStatus()
+func ExampleResolveStatus() {
+	status, err := ResolveStatus([]*StatusRecord{
+		{Height: 100, Sequence: 0, TxID: "attest", Type: ATTEST},
+		{Height: 110, Sequence: 1, TxID: "revoke", Type: REVOKE},
+	}, "attest")
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("%s at height %d by %s", status.Status, status.Height, status.ChangedBy.TxID)
+	// Output:revoked at height 110 by revoke
+}
+
+// BenchmarkResolveStatus benchmarks the method ResolveStatus()
+func BenchmarkResolveStatus(b *testing.B) {
+	records := []*StatusRecord{
+		testStatusRecord("a", ATTEST, 0, 100),
+		testStatusRecord("r", REVOKE, 1, 110),
+		testStatusRecord("b", ATTEST, 2, 120),
+	}
+	for i := 0; i < b.N; i++ {
+		_, _ = ResolveStatus(records, "a")
+	}
+}
+
+// TestStatus_CheckExpiry will test the method CheckExpiry()
+func TestStatus_CheckExpiry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name           string
+			records        []*StatusRecord
+			height         uint32
+			expectedStatus AttestationStatus
+			expectedHeight uint32
+		}{
+			{"not expired", []*StatusRecord{
+				{Expiry: 200, Height: 100, TxID: "a", Type: ATTEST},
+			}, 150, StatusActive, 100},
+			{"expired at height", []*StatusRecord{
+				{Expiry: 200, Height: 100, TxID: "a", Type: ATTEST},
+			}, 250, StatusExpired, 200},
+			{"expired at time", []*StatusRecord{
+				{Expiry: Expiry(now.Unix()), Height: 100, TxID: "a", Type: ATTEST},
+			}, 250, StatusExpired, 250},
+			{"renewed by a later attestation", []*StatusRecord{
+				{Expiry: 200, Height: 100, TxID: "a", Type: ATTEST},
+				{Expiry: 300, Height: 190, Sequence: 1, TxID: "b", Type: ATTEST},
+			}, 250, StatusActive, 190},
+			{"revoked before expiry", []*StatusRecord{
+				{Expiry: 200, Height: 100, TxID: "a", Type: ATTEST},
+				{Height: 150, Sequence: 1, TxID: "r", Type: REVOKE},
+			}, 250, StatusRevoked, 150},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		status, err := ResolveStatus(test.records, "")
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		}
+		status.CheckExpiry(test.height, now)
+		if status.Status != test.expectedStatus || status.Height != test.expectedHeight {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s at %d] but got [%s at %d]", t.Name(), test.name,
+				test.expectedStatus, test.expectedHeight, status.Status, status.Height)
+		}
+	}
+}
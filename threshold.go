@@ -0,0 +1,126 @@
+package bap
+
+import (
+	"fmt"
+
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// ThresholdPolicy requires active attestations of a URN hash from at least Threshold of the
+// Attestors (id keys), an M-of-N policy
+type ThresholdPolicy struct {
+	Attestors []string `json:"attestors"`
+	Threshold int      `json:"threshold"`
+}
+
+// ThresholdResult is the outcome of checking a ThresholdPolicy
+type ThresholdResult struct {
+	Approved  []string           `json:"approved"` // Attestors with an active attestation, in policy order
+	Met       bool               `json:"met"`
+	Statuses  map[string]*Status `json:"statuses"` // Status of each attestor that attested the hash
+	Threshold int                `json:"threshold"`
+}
+
+// NewThresholdPolicy returns a validated M-of-N policy over the attestor id keys
+func NewThresholdPolicy(threshold int, attestors ...string) (*ThresholdPolicy, error) {
+	p := &ThresholdPolicy{Attestors: attestors, Threshold: threshold}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Validate checks that the attestors are unique, valid id keys and that 1 <= Threshold <= len(Attestors)
+func (p *ThresholdPolicy) Validate() error {
+	if len(p.Attestors) == 0 {
+		return &MissingFieldError{Field: "attestors"}
+	} else if p.Threshold < 1 || p.Threshold > len(p.Attestors) {
+		return fmt.Errorf("threshold %d is not between 1 and %d attestors", p.Threshold, len(p.Attestors))
+	}
+	seen := make(map[string]bool, len(p.Attestors))
+	for _, attestor := range p.Attestors {
+		if err := ValidateIDKey(attestor); err != nil {
+			return err
+		} else if seen[attestor] {
+			return fmt.Errorf("duplicate attestor %s", attestor)
+		}
+		seen[attestor] = true
+	}
+	return nil
+}
+
+// Check counts the attestors of the policy whose attestation is active. statuses holds the
+// resolved status (see ResolveStatus and Status.CheckExpiry) of each attestor's attestation
+// by id key, attestors outside the policy are ignored. ErrThresholdNotMet is returned with
+// the result if fewer than Threshold attestations are active.
+func (p *ThresholdPolicy) Check(statuses map[string]*Status) (*ThresholdResult, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	result := &ThresholdResult{Statuses: make(map[string]*Status), Threshold: p.Threshold}
+	for _, attestor := range p.Attestors {
+		status, ok := statuses[attestor]
+		if !ok || status == nil {
+			continue
+		}
+		result.Statuses[attestor] = status
+		if status.Status == StatusActive {
+			result.Approved = append(result.Approved, attestor)
+		}
+	}
+	if result.Met = len(result.Approved) >= p.Threshold; !result.Met {
+		return result, fmt.Errorf("%w: %d of %d attestations", ErrThresholdNotMet, len(result.Approved), p.Threshold)
+	}
+	return result, nil
+}
+
+// CreateThresholdAttestation creates a transaction with one attestation output of the request per
+// attestor signing key, so several attestors co-sign the same URN hash
+func CreateThresholdAttestation(attestorSigningKeys []*ec.PrivateKey,
+	request AttestationRequest) (*transaction.Transaction, error) {
+
+	t := transaction.NewTransaction()
+	if err := AddThresholdAttestationOutputs(t, attestorSigningKeys, request); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// AddThresholdAttestationOutputs adds one attestation output of the request per attestor signing
+// key to an existing transaction. Signatures only cover their own output, so attestors can add
+// their outputs to the same transaction one after another before it is funded and broadcast.
+func AddThresholdAttestationOutputs(t *transaction.Transaction, attestorSigningKeys []*ec.PrivateKey,
+	request AttestationRequest) error {
+
+	// At least one key is required, and each key only once
+	if len(attestorSigningKeys) == 0 {
+		return &MissingFieldError{Field: "attestorSigningKeys"}
+	}
+	seen := make(map[string]bool, len(attestorSigningKeys))
+	records := make([]*Record, len(attestorSigningKeys))
+	for index, key := range attestorSigningKeys {
+		if key == nil {
+			return &MissingFieldError{Field: fmt.Sprintf("attestorSigningKeys[%d]", index)}
+		}
+		pubKey := string(key.PubKey().Compressed())
+		if seen[pubKey] {
+			return fmt.Errorf("attestor signing key %d is a duplicate", index)
+		}
+		seen[pubKey] = true
+
+		// Sign every record before adding any output
+		var err error
+		if records[index], err = CreateAttestationRecordWithExpiry(request.IDKey, key, request.AttributeName,
+			request.AttributeValue, request.IdentityAttributeSecret, request.Expiry); err != nil {
+			return fmt.Errorf("attestor %d: %w", index, err)
+		}
+	}
+
+	for _, record := range records {
+		if err := addOutput(t, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
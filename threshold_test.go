@@ -0,0 +1,168 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+)
+
+// Attestors of the threshold tests
+const (
+	testThresholdAttestorA = "0d5d1e0b1bd2c0f9b8c7e6a5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5"
+	testThresholdAttestorB = "1f1e1d1c1b1a191817161514131211100f0e0d0c0b0a09080706050403020100"
+	testThresholdAttestorC = "oqWsnpcTgXuEGSHRUGJUfY2518b"
+)
+
+// TestThresholdPolicy_Validate will test the method Validate()
+func TestThresholdPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			threshold     int
+			attestors     []string
+			expectedError bool
+		}{
+			{"2 of 3", 2, []string{testThresholdAttestorA, testThresholdAttestorB, testThresholdAttestorC}, false},
+			{"1 of 1", 1, []string{testThresholdAttestorA}, false},
+			{"threshold too high", 3, []string{testThresholdAttestorA, testThresholdAttestorB}, true},
+			{"zero threshold", 0, []string{testThresholdAttestorA}, true},
+			{"duplicate attestor", 2, []string{testThresholdAttestorA, testThresholdAttestorA}, true},
+			{"invalid attestor", 1, []string{"not-an-id-key"}, true},
+			{"no attestors", 1, nil, true},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err := NewThresholdPolicy(test.threshold, test.attestors...); err != nil && !test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if err == nil && test.expectedError {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		}
+	}
+}
+
+// TestThresholdPolicy_Check will test the method Check()
+func TestThresholdPolicy_Check(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewThresholdPolicy(2, testThresholdAttestorA, testThresholdAttestorB, testThresholdAttestorC)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	active := &Status{Status: StatusActive}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name             string
+			statuses         map[string]*Status
+			expectedApproved []string
+			expectedError    error
+		}{
+			{"all active", map[string]*Status{
+				testThresholdAttestorC: active, testThresholdAttestorA: active, testThresholdAttestorB: active,
+			}, []string{testThresholdAttestorA, testThresholdAttestorB, testThresholdAttestorC}, nil},
+			{"two active", map[string]*Status{
+				testThresholdAttestorA: active, testThresholdAttestorB: {Status: StatusRevoked}, testThresholdAttestorC: active,
+			}, []string{testThresholdAttestorA, testThresholdAttestorC}, nil},
+			{"one active", map[string]*Status{
+				testThresholdAttestorA: active, testThresholdAttestorB: {Status: StatusExpired},
+			}, []string{testThresholdAttestorA}, ErrThresholdNotMet},
+			{"outside the policy", map[string]*Status{
+				testThresholdAttestorA: active, idKey: active,
+			}, []string{testThresholdAttestorA}, ErrThresholdNotMet},
+			{"none", nil, nil, ErrThresholdNotMet},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if result, checkErr := policy.Check(test.statuses); test.expectedError == nil && checkErr != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, checkErr.Error())
+		} else if test.expectedError != nil && !errors.Is(checkErr, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, checkErr)
+		} else if fmt.Sprint(result.Approved) != fmt.Sprint(test.expectedApproved) || result.Met != (test.expectedError == nil) {
+			t.Errorf("%s Failed: [%s] inputted and expected approved %v but got %+v", t.Name(), test.name, test.expectedApproved, result)
+		}
+	}
+}
+
+// TestCreateThresholdAttestation will test the method CreateThresholdAttestation()
+func TestCreateThresholdAttestation(t *testing.T) {
+	t.Parallel()
+
+	keyA, addressA := testutil.SigningKey(t, testProofAttestorKey, 0)
+	keyB, addressB := testutil.SigningKey(t, privateKey, 0)
+	request := AttestationRequest{IDKey: idKey, AttributeName: "kyc", AttributeValue: "passed", IdentityAttributeSecret: "secret", Expiry: 850000}
+	hash := AttestationHash(request.IDKey, request.AttributeName, request.AttributeValue, request.IdentityAttributeSecret)
+
+	tx, err := CreateThresholdAttestation([]*ec.PrivateKey{keyA, keyB}, request)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var records []*SignedBap
+	if records, err = signedRecordsFromHex(tx.Hex()); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if len(records) != 2 {
+		t.Fatalf("%s Failed: expected 2 records but got %d", t.Name(), len(records))
+	}
+	for index, address := range []string{addressA, addressB} {
+		if record := records[index]; record.URNHash != fmt.Sprintf("%x", hash) || record.Expiry != 850000 ||
+			!record.Signer.Valid || record.Signer.Address != address {
+			t.Errorf("%s Failed: unexpected record %d: %+v %+v", t.Name(), index, record.Bap, record.Signer)
+		}
+	}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name    string
+			keys    []*ec.PrivateKey
+			request AttestationRequest
+		}{
+			{"no keys", nil, request},
+			{"nil key", []*ec.PrivateKey{keyA, nil}, request},
+			{"duplicate key", []*ec.PrivateKey{keyA, keyA}, request},
+			{"invalid request", []*ec.PrivateKey{keyA, keyB}, AttestationRequest{IDKey: idKey}},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if tx, err = CreateThresholdAttestation(test.keys, test.request); err == nil || tx != nil {
+			t.Errorf("%s Failed: [%s] inputted and error was expected", t.Name(), test.name)
+		}
+	}
+}
+
+// ExampleThresholdPolicy_Check example using Check()
+func ExampleThresholdPolicy_Check() {
+	policy, err := NewThresholdPolicy(2, testThresholdAttestorA, testThresholdAttestorB, testThresholdAttestorC)
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	_, err = policy.Check(map[string]*Status{
+		testThresholdAttestorA: {Status: StatusActive},
+		testThresholdAttestorB: {Status: StatusRevoked},
+	})
+	fmt.Printf("error: %s", err.Error())
+	// Output:error: attestation threshold not met: 1 of 2 attestations
+}
+
+// BenchmarkCreateThresholdAttestation benchmarks the method CreateThresholdAttestation()
+func BenchmarkCreateThresholdAttestation(b *testing.B) {
+	keyA, _ := testutil.SigningKey(b, testProofAttestorKey, 0)
+	keyB, _ := testutil.SigningKey(b, privateKey, 0)
+	request := AttestationRequest{IDKey: idKey, AttributeName: "kyc", AttributeValue: "passed", IdentityAttributeSecret: "secret"}
+	for i := 0; i < b.N; i++ {
+		_, _ = CreateThresholdAttestation([]*ec.PrivateKey{keyA, keyB}, request)
+	}
+}
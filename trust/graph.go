@@ -0,0 +1,159 @@
+// Package trust builds a web-of-trust graph from indexed attestations and scores identities
+// without a central authority: nodes are BAP identities, an edge from an attestor to an
+// identity stands for the attestor's active ATTEST records of the identity's attributes, and
+// trust flows from seed identities along the edges, decaying with every hop
+package trust
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+)
+
+// Edge is the set of active attestations of an identity's attributes by an attestor
+type Edge struct {
+	From      string   `json:"from"`       // Attestor id key
+	To        string   `json:"to"`         // Attested identity id key
+	TxIDs     []string `json:"txids"`      // Attestation txids, in chain order
+	URNHashes []string `json:"urn_hashes"` // Attested URN hashes, in chain order
+}
+
+// Graph is a directed graph of identities and attestations
+type Graph struct {
+	edges map[string]map[string]*Edge // From -> To -> Edge
+	in    map[string][]string         // To -> From
+}
+
+// NewGraph returns an empty graph
+func NewGraph() *Graph {
+	return &Graph{edges: make(map[string]map[string]*Edge), in: make(map[string][]string)}
+}
+
+// Build returns the graph of the active attestations of a store: ATTEST records whose attestor
+// and subject identity are known (see indexer.Indexer.AddClaim), that are not revoked,
+// superseded or expired (at the highest indexed block and the current time). Attestations of
+// an identity by itself are ignored.
+func Build(store indexer.Store) (*Graph, error) {
+	return build(store, nil, time.Now())
+}
+
+// BuildAt returns the graph of the active attestations (see Build) with expiry checked at the given chain height and time
+func BuildAt(store indexer.Store, height uint32, now time.Time) (*Graph, error) {
+	return build(store, &height, now)
+}
+
+// build groups the attestations by URN hash and attestor and adds the active ones
+func build(store indexer.Store, height *uint32, now time.Time) (*Graph, error) {
+	records, _, err := indexer.NewQuery(store).Attestations(indexer.AttestationFilter{}, indexer.Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	type group struct {
+		attestor string
+		records  []*bap.StatusRecord
+		subject  string
+		urnHash  string
+	}
+	var (
+		groups []*group
+		byKey  = make(map[string]*group)
+		tip    uint32
+	)
+	for _, record := range records {
+		tip = max(tip, record.Block.Height)
+		if len(record.AttestorIDKey) == 0 || len(record.Subject) == 0 || record.AttestorIDKey == record.Subject {
+			continue
+		}
+		key := record.URNHash + ":" + record.AttestorIDKey
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{attestor: record.AttestorIDKey, subject: record.Subject, urnHash: record.URNHash}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.records = append(g.records, &bap.StatusRecord{
+			Expiry:   record.Expiry,
+			Height:   record.Block.Height,
+			Sequence: record.Sequence,
+			TxID:     record.TxID,
+			Type:     record.Type,
+		})
+	}
+	if height != nil {
+		tip = *height
+	}
+
+	graph := NewGraph()
+	for _, g := range groups {
+		status, statusErr := bap.ResolveStatus(g.records, "")
+		if statusErr != nil {
+			continue // Only revocations
+		}
+		status.CheckExpiry(tip, now)
+		if status.Status == bap.StatusActive {
+			graph.AddEdge(g.attestor, g.subject, status.Attestation.TxID, g.urnHash)
+		}
+	}
+	return graph, nil
+}
+
+// AddEdge adds an attestation of an identity (to) by an attestor (from)
+func (g *Graph) AddEdge(from, to, txid, urnHash string) {
+	if len(from) == 0 || len(to) == 0 || from == to {
+		return
+	}
+	if g.edges[from] == nil {
+		g.edges[from] = make(map[string]*Edge)
+	}
+	edge, ok := g.edges[from][to]
+	if !ok {
+		edge = &Edge{From: from, To: to}
+		g.edges[from][to] = edge
+		g.in[to] = append(g.in[to], from)
+	}
+	edge.TxIDs = append(edge.TxIDs, txid)
+	edge.URNHashes = append(edge.URNHashes, urnHash)
+}
+
+// Edge returns the edge from an attestor to an identity
+func (g *Graph) Edge(from, to string) (*Edge, bool) {
+	edge, ok := g.edges[from][to]
+	return edge, ok
+}
+
+// Attested returns the identities attested by an attestor, sorted
+func (g *Graph) Attested(from string) []string {
+	attested := make([]string, 0, len(g.edges[from]))
+	for to := range g.edges[from] {
+		attested = append(attested, to)
+	}
+	sort.Strings(attested)
+	return attested
+}
+
+// Attestors returns the attestors of an identity, sorted
+func (g *Graph) Attestors(to string) []string {
+	attestors := append([]string(nil), g.in[to]...)
+	sort.Strings(attestors)
+	return attestors
+}
+
+// Nodes returns every identity of the graph, sorted
+func (g *Graph) Nodes() []string {
+	seen := make(map[string]bool)
+	for from, edges := range g.edges {
+		seen[from] = true
+		for to := range edges {
+			seen[to] = true
+		}
+	}
+	nodes := make([]string, 0, len(seen))
+	for node := range seen {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
@@ -0,0 +1,142 @@
+package trust
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	"github.com/bitcoinschema/go-bap/internal/testutil/testindex"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// testStore indexes three identities and attestations between them:
+// attestor -> identity (name), identity -> other (name), a revoked attestor -> other (email),
+// a self-attestation, an attestation of an unknown subject and an other -> attestor attestation
+// expiring at height 150
+func testStore(t testing.TB) indexer.Store {
+	idx := testindex.New(t, testutil.AttestorID, testutil.IDKey, testutil.OtherID)
+	attestorKey, _ := testutil.SigningKey(t, testutil.AttestorKey, 0)
+	identityKey, _ := testutil.SigningKey(t, testutil.IdentityKey, 0)
+	otherKey, _ := testutil.SigningKey(t, testutil.OtherKey, 0)
+
+	for _, claim := range [][3]string{
+		{testutil.IDKey, "name", "John"},
+		{testutil.OtherID, "name", "Jane"},
+		{testutil.OtherID, "email", "jane@example.com"},
+		{testutil.IDKey, "email", "john@example.com"},
+		{testutil.AttestorID, "name", "Acme"},
+	} {
+		if _, err := idx.AddClaim(claim[0], claim[1], claim[2], testindex.Secret); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+	}
+	txs := map[uint32]*transaction.Transaction{
+		110: testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.IDKey, "name", "John", "0"),
+		111: testindex.Attestation(t, identityKey, bap.ATTEST, testutil.OtherID, "name", "Jane", "0"),
+		112: testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.OtherID, "email", "jane@example.com", "0"),
+		113: testindex.Attestation(t, attestorKey, bap.REVOKE, testutil.OtherID, "email", "jane@example.com", "1"),
+		114: testindex.Attestation(t, identityKey, bap.ATTEST, testutil.IDKey, "email", "john@example.com", "0"),
+		115: testindex.Attestation(t, attestorKey, bap.ATTEST, testutil.OtherID, "name", "unclaimed", "0"),
+	}
+	var err error
+	if txs[116], err = bap.CreateAttestationWithExpiry(testutil.AttestorID, otherKey, "name", "Acme", testindex.Secret, 150); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	testindex.AddHeights(t, idx, txs)
+	return idx.Store()
+}
+
+// TestBuildAt will test the method BuildAt()
+func TestBuildAt(t *testing.T) {
+	t.Parallel()
+
+	store := testStore(t)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			height        uint32
+			expectedEdges string
+		}{
+			{"before the expiry", 120, fmt.Sprintf("[%s>%s %s>%s %s>%s]", testutil.AttestorID[:4], testutil.IDKey[:4], testutil.OtherID[:4], testutil.AttestorID[:4], testutil.IDKey[:4], testutil.OtherID[:4])},
+			{"after the expiry", 150, fmt.Sprintf("[%s>%s %s>%s]", testutil.AttestorID[:4], testutil.IDKey[:4], testutil.IDKey[:4], testutil.OtherID[:4])},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		g, err := BuildAt(store, test.height, time.Now())
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+			continue
+		}
+		var edges []string
+		for _, from := range g.Nodes() {
+			for _, to := range g.Attested(from) {
+				edges = append(edges, from[:4]+">"+to[:4])
+			}
+		}
+		if fmt.Sprint(edges) != test.expectedEdges {
+			t.Errorf("%s Failed: [%s] inputted and expected %s but got %v", t.Name(), test.name, test.expectedEdges, edges)
+		}
+	}
+}
+
+// TestBuild will test the method Build()
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	g, err := Build(testStore(t))
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// The highest indexed block (116) is before the expiry
+	if attestors := g.Attestors(testutil.AttestorID); fmt.Sprint(attestors) != fmt.Sprint([]string{testutil.OtherID}) {
+		t.Fatalf("%s Failed: unexpected attestors %v", t.Name(), attestors)
+	}
+	edge, ok := g.Edge(testutil.AttestorID, testutil.IDKey)
+	if !ok || len(edge.TxIDs) != 1 || len(edge.URNHashes) != 1 {
+		t.Fatalf("%s Failed: unexpected edge %+v", t.Name(), edge)
+	}
+	if _, ok = g.Edge(testutil.IDKey, testutil.IDKey); ok {
+		t.Fatalf("%s Failed: expected self-attestations to be ignored", t.Name())
+	}
+
+	var score *Score
+	if score, err = g.Score(testutil.OtherID, Config{Seeds: []Seed{{IDKey: testutil.AttestorID, Trust: 1}}}); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if score.Score != 0.25 || len(score.Edges) != 2 || score.Edges[0].TxIDs[0] != edge.TxIDs[0] {
+		t.Fatalf("%s Failed: unexpected score %+v", t.Name(), score)
+	}
+}
+
+// TestGraph_AddEdge will test the method AddEdge()
+func TestGraph_AddEdge(t *testing.T) {
+	t.Parallel()
+
+	g := NewGraph()
+	g.AddEdge("a", "b", "tx1", "urn1")
+	g.AddEdge("a", "b", "tx2", "urn2")
+	g.AddEdge("c", "b", "tx3", "urn3")
+	g.AddEdge("a", "a", "tx4", "urn4")
+	g.AddEdge("", "b", "tx5", "urn5")
+
+	if edge, ok := g.Edge("a", "b"); !ok || fmt.Sprint(edge.TxIDs) != "[tx1 tx2]" || fmt.Sprint(edge.URNHashes) != "[urn1 urn2]" {
+		t.Fatalf("%s Failed: unexpected edge %+v", t.Name(), edge)
+	} else if fmt.Sprint(g.Attestors("b")) != "[a c]" || fmt.Sprint(g.Attested("a")) != "[b]" || fmt.Sprint(g.Nodes()) != "[a b c]" {
+		t.Fatalf("%s Failed: unexpected graph %v %v %v", t.Name(), g.Attestors("b"), g.Attested("a"), g.Nodes())
+	}
+}
+
+// BenchmarkBuild benchmarks the method Build()
+func BenchmarkBuild(b *testing.B) {
+	store := testStore(b)
+	for i := 0; i < b.N; i++ {
+		_, _ = Build(store)
+	}
+}
@@ -0,0 +1,181 @@
+package trust
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/bitcoinschema/go-bap"
+)
+
+// Propagation defaults, used for zero Config fields
+const (
+	DefaultDecay    = 0.5
+	DefaultMaxDepth = 3
+)
+
+// ErrInvalidConfig is returned for a trust configuration without seeds or with out of range values
+var ErrInvalidConfig = errors.New("invalid trust config")
+
+// Seed is an identity trusted up front, with its trust in (0, 1]
+type Seed struct {
+	IDKey string  `json:"id_key"`
+	Trust float64 `json:"trust"`
+}
+
+// Config configures trust propagation
+type Config struct {
+	Decay    float64 `json:"decay,omitempty"`     // Trust kept per hop, in (0, 1]
+	MaxDepth int     `json:"max_depth,omitempty"` // Maximum hops from a seed
+	Seeds    []Seed  `json:"seeds"`
+}
+
+// Score is the trust in an identity and its explanation: the best path of attestations from a seed
+type Score struct {
+	Depth     int      `json:"depth"`     // Hops from the seed
+	Endorsers []string `json:"endorsers"` // Trusted attestors of the identity, sorted
+	IDKey     string   `json:"id_key"`
+	Path      []string `json:"path"`  // Id keys from the seed to the identity
+	Edges     []*Edge  `json:"edges"` // Attestations along the path
+	Score     float64  `json:"score"` // 0 if the identity is not reachable from a seed
+	Seed      string   `json:"seed,omitempty"`
+}
+
+// node is the best known trust of an identity during propagation, nodes are never changed
+// once created so the prev chain keeps the path the trust came along
+type node struct {
+	depth int
+	idKey string
+	prev  *node // Node the trust came from, nil for a seed
+	score float64
+	seed  string
+}
+
+// Score returns the trust in an identity: the highest seed trust times Decay for every hop of
+// a path of attestations from the seed to the identity, within MaxDepth hops
+func (g *Graph) Score(idKey string, config Config) (*Score, error) {
+	if len(idKey) == 0 {
+		return nil, &bap.MissingFieldError{Field: "idKey"}
+	}
+	nodes, err := g.propagate(config)
+	if err != nil {
+		return nil, err
+	}
+	return g.score(idKey, nodes), nil
+}
+
+// Scores returns the trust in every reachable identity, highest first (ties sorted by id key)
+func (g *Graph) Scores(config Config) ([]*Score, error) {
+	nodes, err := g.propagate(config)
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]*Score, 0, len(nodes))
+	for idKey := range nodes {
+		scores = append(scores, g.score(idKey, nodes))
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].IDKey < scores[j].IDKey
+	})
+	return scores, nil
+}
+
+// propagate spreads trust from the seeds, one hop per round, keeping the best score of each identity
+func (g *Graph) propagate(config Config) (map[string]*node, error) {
+	config, err := config.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*node)
+	var frontier []string
+	for _, seed := range config.Seeds {
+		if current, ok := nodes[seed.IDKey]; !ok || seed.Trust > current.score {
+			nodes[seed.IDKey] = &node{idKey: seed.IDKey, score: seed.Trust, seed: seed.IDKey}
+		}
+		frontier = append(frontier, seed.IDKey)
+	}
+
+	for depth := 1; depth <= config.MaxDepth && len(frontier) > 0; depth++ {
+		sort.Strings(frontier)
+
+		// Trust spreads from the nodes as they were at the start of the round, a node replaced
+		// during the round spreads its new trust in the next one
+		snapshot := make(map[string]*node, len(frontier))
+		for _, from := range frontier {
+			snapshot[from] = nodes[from]
+		}
+		var next []string
+		for _, from := range frontier {
+			source := snapshot[from]
+			for _, to := range g.Attested(from) {
+				candidate := source.score * config.Decay
+				if current, ok := nodes[to]; ok && candidate <= current.score {
+					continue
+				}
+				if _, ok := nodes[to]; !ok || nodes[to].depth != depth {
+					next = append(next, to)
+				}
+				nodes[to] = &node{depth: source.depth + 1, idKey: to, prev: source, score: candidate, seed: source.seed}
+			}
+		}
+		frontier = next
+	}
+	return nodes, nil
+}
+
+// score builds the score of an identity from the propagation result
+func (g *Graph) score(idKey string, nodes map[string]*node) *Score {
+	result := &Score{IDKey: idKey, Endorsers: []string{}, Path: []string{}, Edges: []*Edge{}}
+	current, ok := nodes[idKey]
+	if !ok {
+		return result
+	}
+	result.Depth, result.Score, result.Seed = current.depth, current.score, current.seed
+
+	// Walk back to the seed
+	for n := current; n != nil; n = n.prev {
+		result.Path = append([]string{n.idKey}, result.Path...)
+		if n.prev == nil {
+			break
+		}
+		if edge, found := g.Edge(n.prev.idKey, n.idKey); found {
+			result.Edges = append([]*Edge{edge}, result.Edges...)
+		}
+	}
+
+	for _, attestor := range g.Attestors(idKey) {
+		if n, trusted := nodes[attestor]; trusted && n.score > 0 {
+			result.Endorsers = append(result.Endorsers, attestor)
+		}
+	}
+	return result
+}
+
+// withDefaults validates the config and fills in the default decay and depth
+func (c Config) withDefaults() (Config, error) {
+	if len(c.Seeds) == 0 {
+		return c, fmt.Errorf("%w: no seeds", ErrInvalidConfig)
+	} else if c.Decay < 0 || c.Decay > 1 {
+		return c, fmt.Errorf("%w: decay %v is not in (0, 1]", ErrInvalidConfig, c.Decay)
+	} else if c.MaxDepth < 0 {
+		return c, fmt.Errorf("%w: negative max depth", ErrInvalidConfig)
+	}
+	for _, seed := range c.Seeds {
+		if len(seed.IDKey) == 0 {
+			return c, &bap.MissingFieldError{Field: "seed"}
+		} else if seed.Trust <= 0 || seed.Trust > 1 {
+			return c, fmt.Errorf("%w: seed trust %v is not in (0, 1]", ErrInvalidConfig, seed.Trust)
+		}
+	}
+	if c.Decay == 0 {
+		c.Decay = DefaultDecay
+	}
+	if c.MaxDepth == 0 {
+		c.MaxDepth = DefaultMaxDepth
+	}
+	return c, nil
+}
@@ -0,0 +1,173 @@
+package trust
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bap"
+)
+
+// testGraph returns a graph: seed -> a -> b -> c -> d, seed -> x, other -> b
+func testGraph() *Graph {
+	g := NewGraph()
+	for _, edge := range [][2]string{{"seed", "a"}, {"a", "b"}, {"b", "c"}, {"c", "d"}, {"seed", "x"}, {"other", "b"}} {
+		g.AddEdge(edge[0], edge[1], "tx-"+edge[0]+"-"+edge[1], "urn-"+edge[1])
+	}
+	return g
+}
+
+// TestGraph_Score will test the method Score()
+func TestGraph_Score(t *testing.T) {
+	t.Parallel()
+
+	g := testGraph()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name              string
+			idKey             string
+			config            Config
+			expectedScore     float64
+			expectedPath      string
+			expectedEndorsers string
+		}{
+			{"seed", "seed", Config{Seeds: []Seed{{"seed", 1}}}, 1, "[seed]", "[]"},
+			{"one hop", "a", Config{Seeds: []Seed{{"seed", 1}}}, 0.5, "[seed a]", "[seed]"},
+			{"three hops", "c", Config{Seeds: []Seed{{"seed", 1}}}, 0.125, "[seed a b c]", "[b]"},
+			{"beyond the depth", "d", Config{Seeds: []Seed{{"seed", 1}}}, 0, "[]", "[]"},
+			{"deeper", "d", Config{MaxDepth: 4, Seeds: []Seed{{"seed", 1}}}, 0.0625, "[seed a b c d]", "[c]"},
+			{"decay", "b", Config{Decay: 0.9, Seeds: []Seed{{"seed", 1}}}, 0.81, "[seed a b]", "[a]"},
+			{"closer seed", "b", Config{Seeds: []Seed{{"seed", 1}, {"other", 0.8}}}, 0.4, "[other b]", "[a other]"},
+			{"stronger seed", "b", Config{Seeds: []Seed{{"seed", 1}, {"other", 0.2}}}, 0.25, "[seed a b]", "[a other]"},
+			{"unknown identity", "nobody", Config{Seeds: []Seed{{"seed", 1}}}, 0, "[]", "[]"},
+			{"edges do not flow backwards", "seed", Config{Seeds: []Seed{{"a", 1}}}, 0, "[]", "[]"},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		score, err := g.Score(test.idKey, test.config)
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if fmt.Sprintf("%.4f", score.Score) != fmt.Sprintf("%.4f", test.expectedScore) ||
+			fmt.Sprint(score.Path) != test.expectedPath || fmt.Sprint(score.Endorsers) != test.expectedEndorsers {
+			t.Errorf("%s Failed: [%s] inputted and expected [%v %s %s] but got [%v %v %v]", t.Name(), test.name,
+				test.expectedScore, test.expectedPath, test.expectedEndorsers, score.Score, score.Path, score.Endorsers)
+		} else if len(score.Edges) != max(len(score.Path)-1, 0) || score.Depth != len(score.Edges) {
+			t.Errorf("%s Failed: [%s] inputted and expected an edge per hop but got %d edges at depth %d", t.Name(), test.name, len(score.Edges), score.Depth)
+		}
+	}
+}
+
+// TestGraph_ScoreConvergingSeeds will test that the explanation matches the score when a seed
+// is reached with more trust from another seed: seed -> weak -> x
+func TestGraph_ScoreConvergingSeeds(t *testing.T) {
+	t.Parallel()
+
+	g := NewGraph()
+	g.AddEdge("seed", "weak", "tx-seed-weak", "urn-weak")
+	g.AddEdge("weak", "x", "tx-weak-x", "urn-x")
+	seeds := []Seed{{"seed", 1}, {"weak", 0.2}}
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name         string
+			idKey        string
+			maxDepth     int
+			expected     string
+			expectedSeed string
+		}{
+			{"replaced seed", "weak", 1, "0.5000 [seed weak]", "seed"},
+			{"from the seed as it was", "x", 1, "0.1000 [weak x]", "weak"},
+			{"from the replaced seed", "x", 2, "0.2500 [seed weak x]", "seed"},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		score, err := g.Score(test.idKey, Config{MaxDepth: test.maxDepth, Seeds: seeds})
+		if err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if result := fmt.Sprintf("%.4f %v", score.Score, score.Path); result != test.expected || score.Seed != test.expectedSeed {
+			t.Errorf("%s Failed: [%s] inputted and expected [%s %s] but got [%s %s]", t.Name(), test.name, test.expected, test.expectedSeed, result, score.Seed)
+		} else if score.Depth != len(score.Path)-1 || score.Depth > test.maxDepth || score.Path[0] != score.Seed {
+			t.Errorf("%s Failed: [%s] inputted and the path %v does not match depth %d and seed %s", t.Name(), test.name, score.Path, score.Depth, score.Seed)
+		}
+	}
+}
+
+// TestGraph_Scores will test the method Scores()
+func TestGraph_Scores(t *testing.T) {
+	t.Parallel()
+
+	scores, err := testGraph().Scores(Config{Seeds: []Seed{{"seed", 1}}})
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	ranking := make([]string, len(scores))
+	for index, score := range scores {
+		ranking[index] = fmt.Sprintf("%s:%.3f", score.IDKey, score.Score)
+	}
+	if fmt.Sprint(ranking) != "[seed:1.000 a:0.500 x:0.500 b:0.250 c:0.125]" {
+		t.Fatalf("%s Failed: unexpected ranking %v", t.Name(), ranking)
+	}
+}
+
+// TestConfig_Invalid will test invalid configs
+func TestConfig_Invalid(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			idKey         string
+			config        Config
+			expectedError error
+		}{
+			{"no seeds", "a", Config{}, ErrInvalidConfig},
+			{"decay too high", "a", Config{Decay: 1.5, Seeds: []Seed{{"seed", 1}}}, ErrInvalidConfig},
+			{"negative decay", "a", Config{Decay: -1, Seeds: []Seed{{"seed", 1}}}, ErrInvalidConfig},
+			{"negative depth", "a", Config{MaxDepth: -1, Seeds: []Seed{{"seed", 1}}}, ErrInvalidConfig},
+			{"zero trust", "a", Config{Seeds: []Seed{{"seed", 0}}}, ErrInvalidConfig},
+			{"empty seed", "a", Config{Seeds: []Seed{{"", 1}}}, bap.ErrMissingField},
+			{"no id key", "", Config{Seeds: []Seed{{"seed", 1}}}, bap.ErrMissingField},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err := testGraph().Score(test.idKey, test.config); !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		}
+	}
+}
+
+// ExampleGraph_Score example using Score()
+func ExampleGraph_Score() {
+	g := NewGraph()
+	g.AddEdge("marketplace", "alice", "txid-1", "urn-1")
+	g.AddEdge("alice", "bob", "txid-2", "urn-2")
+	score, err := g.Score("bob", Config{Decay: 0.8, Seeds: []Seed{{IDKey: "marketplace", Trust: 1}}})
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("score: %.2f path: %v", score.Score, score.Path)
+	// Output:score: 0.64 path: [marketplace alice bob]
+}
+
+// BenchmarkGraph_Score benchmarks the method Score()
+func BenchmarkGraph_Score(b *testing.B) {
+	g := NewGraph()
+	for i := 0; i < 1000; i++ {
+		g.AddEdge(fmt.Sprintf("id-%d", i/10), fmt.Sprintf("id-%d", i+1), "txid", "urn")
+	}
+	config := Config{MaxDepth: 5, Seeds: []Seed{{IDKey: "id-0", Trust: 1}}}
+	for i := 0; i < b.N; i++ {
+		_, _ = g.Score("id-999", config)
+	}
+}
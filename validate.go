@@ -0,0 +1,163 @@
+package bap
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bitcoinschema/go-bpu"
+	base58 "github.com/bsv-blockchain/go-sdk/compat/base58"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+// identityKeyLength is the length of a decoded base58 identity key (ripemd160 hash)
+const identityKeyLength = 20
+
+// Validate strictly checks the fields of a parsed BAP record: the record type must be
+// known, addresses must have a valid checksum for the given network (mainnet if nil),
+// URN hashes must be 32 byte hex, id keys must be well-formed and profiles must be JSON objects
+func (b *Bap) Validate(network *chaincfg.Params) error {
+	switch b.Type {
+	case ID:
+		if err := ValidateIDKey(b.IDKey); err != nil {
+			return &TapeError{Type: b.Type, Field: "id_key", Err: err}
+		}
+		if err := ValidateAddress(b.Address, network); err != nil {
+			return &TapeError{Type: b.Type, Field: "address", Err: err}
+		}
+	case ATTEST, REVOKE, DATA:
+		if err := validateURNHash(b.URNHash); err != nil {
+			return &TapeError{Type: b.Type, Field: "urn_hash", Err: err}
+		}
+	case ALIAS:
+		if err := ValidateIDKey(b.IDKey); err != nil {
+			return &TapeError{Type: b.Type, Field: "id_key", Err: err}
+		}
+		if err := validateProfile(b.Profile); err != nil {
+			return &TapeError{Type: b.Type, Field: "profile", Err: err}
+		}
+	default:
+		return &RecordTypeError{Type: b.Type}
+	}
+	return nil
+}
+
+// NewFromTapeStrict takes a bob.Tape and returns a BAP data structure that has
+// passed Validate() for the given network (mainnet if nil)
+func NewFromTapeStrict(tape *bpu.Tape, network *chaincfg.Params) (*Bap, error) {
+	b, err := NewFromTape(tape)
+	if err != nil {
+		return nil, err
+	}
+	if err = b.Validate(network); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// NewFromTapesStrict will create a new BAP object from a []bob.Tape that has
+// passed Validate() for the given network (mainnet if nil)
+func NewFromTapesStrict(tapes []bpu.Tape, network *chaincfg.Params) (*Bap, error) {
+	b, err := NewFromTapes(tapes)
+	if err != nil {
+		return nil, err
+	}
+	if err = b.Validate(network); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ValidateAddress checks that a P2PKH address has a valid checksum and belongs
+// to the given network (mainnet if nil)
+func ValidateAddress(address string, network *chaincfg.Params) error {
+	if network == nil {
+		network = &chaincfg.MainNet
+	}
+	if len(address) == 0 {
+		return &MissingFieldError{Field: "address"}
+	}
+
+	decoded, err := base58.Decode(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", address, err)
+	} else if len(decoded) != 25 {
+		return fmt.Errorf("invalid address %s: wrong length %d", address, len(decoded))
+	}
+
+	// Checksum is the first 4 bytes of sha256d(version + hash)
+	first := sha256.Sum256(decoded[:21])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], decoded[21:]) {
+		return fmt.Errorf("invalid address %s: bad checksum", address)
+	}
+
+	if decoded[0] != network.LegacyPubKeyHashAddrID {
+		return &NetworkError{Address: address, Network: network.Name}
+	}
+	return nil
+}
+
+// ValidateIDKey checks that an identity key is either a base58 encoded
+// ripemd160 hash (BAP spec) or a 32 byte hex string
+func ValidateIDKey(idKey string) error {
+	if len(idKey) == 0 {
+		return &MissingFieldError{Field: "idKey"}
+	}
+	if len(idKey) == 64 {
+		if _, err := hex.DecodeString(idKey); err == nil {
+			return nil
+		}
+	}
+	if decoded, err := base58.Decode(idKey); err == nil && len(decoded) == identityKeyLength {
+		return nil
+	}
+	return fmt.Errorf("invalid id key: %s", idKey)
+}
+
+// ValidateIDSigner checks that an ID record of an id key is signed by an address allowed to publish it
+//
+// The first ID record of an identity (empty currentAddress) creates it and must be signed by its
+// root address: rootAddress if it is known, otherwise the address the id key is derived from (64
+// character hex id keys are not derived from an address and require rootAddress). Later ID
+// records rotate the signing address and must be signed by the current signing address, as
+// CreateIdentityRotation does: a replaced key, the root key included, cannot rotate it again.
+func ValidateIDSigner(idKey, signer, rootAddress, currentAddress string) error {
+	switch {
+	case len(currentAddress) > 0:
+		if signer != currentAddress {
+			return fmt.Errorf("%w: rotation of %s signed by %s, not its current address %s", ErrUnauthorized, idKey, signer, currentAddress)
+		}
+	case len(rootAddress) > 0:
+		if signer != rootAddress {
+			return fmt.Errorf("%w: id key %s has root address %s, not %s", ErrUnauthorized, idKey, rootAddress, signer)
+		}
+	case len(idKey) == 64:
+		return fmt.Errorf("%w: id key %s is not derived from an address, its root address is required", ErrUnauthorized, idKey)
+	case IdentityKeyFromAddress(signer) != idKey:
+		return fmt.Errorf("%w: id key %s is not derived from %s", ErrUnauthorized, idKey, signer)
+	}
+	return nil
+}
+
+// validateURNHash checks that a URN hash is a 32 byte hex string
+func validateURNHash(urnHash string) error {
+	decoded, err := hex.DecodeString(urnHash)
+	if err != nil {
+		return fmt.Errorf("urn hash is not hex: %w", err)
+	} else if len(decoded) != sha256.Size {
+		return fmt.Errorf("urn hash has wrong length %d", len(decoded))
+	}
+	return nil
+}
+
+// validateProfile checks that a profile is a well-formed JSON object
+func validateProfile(profile string) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(profile), &fields); err != nil {
+		return fmt.Errorf("profile is not a JSON object: %w", err)
+	}
+	return nil
+}
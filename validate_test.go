@@ -0,0 +1,141 @@
+package bap
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bitcoinschema/go-bob"
+	chaincfg "github.com/bsv-blockchain/go-sdk/transaction/chaincfg"
+)
+
+const (
+	testAddress        = "1A9VQqdNJrvVF73nf879n2fES6cd5nWNid"
+	testTestnetAddress = "mpfShtiM7tMk2DXQNh5XbwsZJ6DKzUZSop"
+	testBase58IDKey    = "3SyWUZXvhidNcEHbAC3HkBnKoHCx"
+	testURNHash        = "cf39fc55da24dc23eff1809e6e6cf32a0fe6aecc81296543e9ac84b8c501bac5"
+)
+
+// TestBap_Validate will test the method Validate()
+func TestBap_Validate(t *testing.T) {
+	t.Parallel()
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			input         Bap
+			network       *chaincfg.Params
+			expectedError error
+		}{
+			{Bap{Type: ID, IDKey: idKey, Address: testAddress}, nil, nil},
+			{Bap{Type: ID, IDKey: testBase58IDKey, Address: testAddress}, &chaincfg.MainNet, nil},
+			{Bap{Type: ID, IDKey: idKey, Address: testTestnetAddress}, &chaincfg.TestNet, nil},
+			{Bap{Type: ID, IDKey: idKey, Address: testTestnetAddress}, nil, ErrWrongNetwork},
+			{Bap{Type: ID, IDKey: idKey, Address: testAddress}, &chaincfg.TestNet, ErrWrongNetwork},
+			{Bap{Type: ID, IDKey: idKey, Address: "1A9VQqdNJrvVF73nf879n2fES6cd5nWNie"}, nil, ErrMalformedTape},
+			{Bap{Type: ID, IDKey: idKey, Address: "Address"}, nil, ErrMalformedTape},
+			{Bap{Type: ID, IDKey: "idKey", Address: testAddress}, nil, ErrMalformedTape},
+			{Bap{Type: ID, Address: testAddress}, nil, ErrMissingField},
+			{Bap{Type: ATTEST, URNHash: testURNHash}, nil, nil},
+			{Bap{Type: REVOKE, URNHash: testURNHash, Sequence: 1}, nil, nil},
+			{Bap{Type: ATTEST, URNHash: "not-hex"}, nil, ErrMalformedTape},
+			{Bap{Type: ATTEST, URNHash: "cf39fc55"}, nil, ErrMalformedTape},
+			{Bap{Type: ALIAS, IDKey: idKey, Profile: `{"name":"John"}`}, nil, nil},
+			{Bap{Type: ALIAS, IDKey: idKey, Profile: `{"name":`}, nil, ErrMalformedTape},
+			{Bap{Type: ALIAS, IDKey: idKey, Profile: `["name"]`}, nil, ErrMalformedTape},
+			{Bap{Type: "UNKNOWN"}, nil, ErrInvalidRecordType},
+			{Bap{}, nil, ErrInvalidRecordType},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if err := test.input.Validate(test.network); err != nil && test.expectedError == nil {
+			t.Errorf("%s Failed: [%+v] inputted and error not expected but got: %s", t.Name(), test.input, err.Error())
+		} else if err == nil && test.expectedError != nil {
+			t.Errorf("%s Failed: [%+v] inputted and error was expected", t.Name(), test.input)
+		} else if err != nil && !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%+v] inputted and expected [%s] but got [%s]", t.Name(), test.input, test.expectedError, err)
+		}
+	}
+}
+
+// TestNewFromTapeStrict will test the methods NewFromTapeStrict() and NewFromTapesStrict()
+func TestNewFromTapeStrict(t *testing.T) {
+	t.Parallel()
+
+	bobData, err := bob.NewFromString(sampleValidBobTx)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, err = NewFromTapeStrict(&bobData.Out[0].Tape[1], nil); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, err = NewFromTapesStrict(bobData.Out[0].Tape, nil); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+
+	// Unknown type is accepted by the lenient parser, but not the strict one
+	unknown := "UNKNOWN"
+	bobData.Out[0].Tape[1].Cell[1].S = &unknown
+	if _, err = NewFromTape(&bobData.Out[0].Tape[1]); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	if _, err = NewFromTapeStrict(&bobData.Out[0].Tape[1], nil); !errors.Is(err, ErrInvalidRecordType) {
+		t.Fatalf("expected ErrInvalidRecordType but got: %v", err)
+	}
+	if _, err = NewFromTapesStrict(bobData.Out[0].Tape, nil); !errors.Is(err, ErrInvalidRecordType) {
+		t.Fatalf("expected ErrInvalidRecordType but got: %v", err)
+	}
+
+	// Invalid ID record
+	id := string(ID)
+	badAddress := "Address"
+	bobData.Out[0].Tape[1].Cell[1].S = &id
+	bobData.Out[0].Tape[1].Cell[3].S = &badAddress
+	if _, err = NewFromTapeStrict(&bobData.Out[0].Tape[1], nil); !errors.Is(err, ErrMalformedTape) {
+		t.Fatalf("expected ErrMalformedTape but got: %v", err)
+	}
+}
+
+// TestValidateIDSigner will test the method ValidateIDSigner()
+func TestValidateIDSigner(t *testing.T) {
+	t.Parallel()
+
+	const otherAddress = "1AFc9feffQmxT61iEftzkaYvWTgLCyU6j"
+	specIDKey := IdentityKeyFromAddress(testAddress)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name           string
+			idKey          string
+			signer         string
+			rootAddress    string
+			currentAddress string
+			expectedError  error
+		}{
+			{"spec id key", specIDKey, testAddress, "", "", nil},
+			{"spec id key of another address", specIDKey, otherAddress, "", "", ErrUnauthorized},
+			{"configured root", idKey, testAddress, testAddress, "", nil},
+			{"not the configured root", idKey, otherAddress, testAddress, "", ErrUnauthorized},
+			{"hex id key without root", idKey, testAddress, "", "", ErrUnauthorized},
+			{"rotation by the current address", idKey, otherAddress, testAddress, otherAddress, nil},
+			{"rotation by the replaced root", idKey, testAddress, testAddress, otherAddress, ErrUnauthorized},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if err := ValidateIDSigner(test.idKey, test.signer, test.rootAddress, test.currentAddress); !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		}
+	}
+}
+
+// ExampleValidateAddress example using ValidateAddress()
+func ExampleValidateAddress() {
+	err := ValidateAddress(testTestnetAddress, nil)
+	fmt.Printf("wrong network: %t", errors.Is(err, ErrWrongNetwork))
+	// Output:wrong network: true
+}
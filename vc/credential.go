@@ -0,0 +1,132 @@
+// Package vc wraps BAP attestations into W3C Verifiable Credentials
+//
+// The attestor that published an ATTEST record issues a credential disclosing the attested
+// attribute (name, value and secret) of an identity. The credential proof is a Bitcoin Signed
+// Message by the address that signed the ATTEST record, and references the attestor's did:bap
+// identity and the attestation txid. A Verifier checks the proof, recomputes the URN hash and
+// confirms the attestation is indexed, unrevoked and unexpired in a local indexer Store.
+package vc
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/did"
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/script"
+)
+
+// Credential contexts and types
+const (
+	ContextV1      = "https://www.w3.org/2018/credentials/v1"
+	CredentialType = "BAPAttestationCredential"
+	ProofPurpose   = "assertionMethod"
+	ProofType      = "BAPAttestationSignature"
+)
+
+// ErrInvalidCredential is returned when a credential is missing or malformed
+var ErrInvalidCredential = errors.New("invalid credential")
+
+// Credential is a W3C Verifiable Credential for an attested BAP attribute
+type Credential struct {
+	Context           []string `json:"@context"`
+	Type              []string `json:"type"`
+	Issuer            string   `json:"issuer"` // did:bap DID of the attestor
+	IssuanceDate      string   `json:"issuanceDate"`
+	ExpirationDate    string   `json:"expirationDate,omitempty"` // Set for attestations that expire at a time
+	CredentialSubject *Subject `json:"credentialSubject"`
+	Proof             *Proof   `json:"proof,omitempty"`
+}
+
+// Subject is the disclosed attribute of the identity
+type Subject struct {
+	ID                      string `json:"id"` // did:bap DID of the identity
+	AttributeName           string `json:"attributeName"`
+	AttributeValue          string `json:"attributeValue"`
+	IdentityAttributeSecret string `json:"identityAttributeSecret"`
+	URNHash                 string `json:"urnHash"`
+}
+
+// Proof is the attestor signature over the credential, anchored by the attestation tx
+type Proof struct {
+	Type               string `json:"type"`
+	Created            string `json:"created"`
+	ProofPurpose       string `json:"proofPurpose"`
+	VerificationMethod string `json:"verificationMethod"` // did:bap DID of the attestor
+	Address            string `json:"address"`            // Address that signed the ATTEST record
+	TxID               string `json:"txid"`               // Txid of the ATTEST record
+	ProofValue         string `json:"proofValue"`         // Base64 Bitcoin Signed Message
+}
+
+// Issue returns a credential for an attested attribute, signed by the key that signed the
+// ATTEST record (attestorIDKey is the attestor's identity, txid the attestation tx)
+func Issue(request *bap.AttestationRequest, attestorIDKey, txid string,
+	attestorSigningKey *ec.PrivateKey, issued time.Time) (*Credential, error) {
+	if request == nil {
+		return nil, &bap.MissingFieldError{Field: "request"}
+	} else if len(request.IDKey) == 0 {
+		return nil, &bap.MissingFieldError{Field: "idKey"}
+	} else if len(attestorIDKey) == 0 {
+		return nil, &bap.MissingFieldError{Field: "attestorIDKey"}
+	} else if len(txid) == 0 {
+		return nil, &bap.MissingFieldError{Field: "txid"}
+	} else if attestorSigningKey == nil {
+		return nil, &bap.MissingFieldError{Field: "attestorSigningKey"}
+	}
+
+	address, err := script.NewAddressFromPublicKey(attestorSigningKey.PubKey(), true)
+	if err != nil {
+		return nil, err
+	}
+	hash := bap.AttestationHash(request.IDKey, request.AttributeName, request.AttributeValue, request.IdentityAttributeSecret)
+	date := issued.UTC().Format(time.RFC3339)
+
+	credential := &Credential{
+		Context:      []string{ContextV1},
+		Type:         []string{"VerifiableCredential", CredentialType},
+		Issuer:       did.New(attestorIDKey),
+		IssuanceDate: date,
+		CredentialSubject: &Subject{
+			ID:                      did.New(request.IDKey),
+			AttributeName:           request.AttributeName,
+			AttributeValue:          request.AttributeValue,
+			IdentityAttributeSecret: request.IdentityAttributeSecret,
+			URNHash:                 hex.EncodeToString(hash[:]),
+		},
+		Proof: &Proof{
+			Type:               ProofType,
+			Created:            date,
+			ProofPurpose:       ProofPurpose,
+			VerificationMethod: did.New(attestorIDKey),
+			Address:            address.AddressString,
+			TxID:               txid,
+		},
+	}
+
+	if request.Expiry.IsTime() {
+		credential.ExpirationDate = request.Expiry.Time().Format(time.RFC3339)
+	}
+
+	var payload, signature []byte
+	if payload, err = credential.signingPayload(); err != nil {
+		return nil, err
+	} else if signature, err = bsm.SignMessage(attestorSigningKey, payload); err != nil {
+		return nil, err
+	}
+	credential.Proof.ProofValue = base64.StdEncoding.EncodeToString(signature)
+	return credential, nil
+}
+
+// signingPayload returns the signed data: the credential JSON with an empty proof value
+func (c *Credential) signingPayload() ([]byte, error) {
+	unsigned := *c
+	proof := *c.Proof
+	proof.ProofValue = ""
+	unsigned.Proof = &proof
+	return json.Marshal(&unsigned)
+}
@@ -0,0 +1,250 @@
+package vc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bitcoinschema/go-aip"
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/indexer"
+	"github.com/bitcoinschema/go-bap/internal/testutil"
+	"github.com/bitcoinschema/go-bap/internal/testutil/testindex"
+	hd "github.com/bsv-blockchain/go-sdk/compat/bip32"
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+	ec "github.com/bsv-blockchain/go-sdk/primitives/ec"
+	"github.com/bsv-blockchain/go-sdk/transaction"
+)
+
+// testRequest is the attested attribute
+var testRequest = &bap.AttestationRequest{
+	IDKey:                   testutil.IDKey,
+	AttributeName:           "name",
+	AttributeValue:          "John",
+	IdentityAttributeSecret: testindex.Secret,
+}
+
+// testIndexer returns an indexer with the identity, the attestor and the attestation (at 102)
+func testIndexer(t testing.TB) (*indexer.Indexer, string) {
+	idx := testindex.New(t, testutil.IDKey, testutil.AttestorID)
+	attestorKey, _ := testutil.SigningKey(t, testutil.AttestorKey, 0)
+	attestTx, err := bap.CreateAttestation(testutil.IDKey, attestorKey, "name", "John", testindex.Secret)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	testindex.Add(t, idx, 102, attestTx)
+	return idx, attestTx.TxID().String()
+}
+
+// testIssue returns a credential for the test attestation
+func testIssue(t testing.TB, txid string) *Credential {
+	attestorKey, _ := testutil.SigningKey(t, testutil.AttestorKey, 0)
+	credential, err := Issue(testRequest, testutil.AttestorID, txid, attestorKey, time.Unix(1600000000, 0))
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	return credential
+}
+
+// testResign will sign the credential again after it was modified
+func testResign(t testing.TB, credential *Credential, key *ec.PrivateKey) {
+	payload, err := credential.signingPayload()
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	var signature []byte
+	if signature, err = bsm.SignMessage(key, payload); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	credential.Proof.ProofValue = base64.StdEncoding.EncodeToString(signature)
+}
+
+// TestIssue will test the method Issue()
+func TestIssue(t *testing.T) {
+	t.Parallel()
+
+	credential := testIssue(t, "txid")
+	hash := bap.AttestationHash(testutil.IDKey, "name", "John", testindex.Secret)
+	if credential.Issuer != "did:bap:"+testutil.AttestorID || credential.CredentialSubject.ID != "did:bap:"+testutil.IDKey {
+		t.Fatalf("unexpected credential: %+v", credential)
+	} else if credential.CredentialSubject.URNHash != fmt.Sprintf("%x", hash) || credential.IssuanceDate != "2020-09-13T12:26:40Z" {
+		t.Fatalf("unexpected subject: %+v", credential.CredentialSubject)
+	} else if credential.Proof.Type != ProofType || credential.Proof.TxID != "txid" || credential.Proof.Address != "1AFc9feffQmxT61iEftzkaYvWTgLCyU6j" {
+		t.Fatalf("unexpected proof: %+v", credential.Proof)
+	}
+
+	key, _ := testutil.SigningKey(t, testutil.AttestorKey, 0)
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			request       *bap.AttestationRequest
+			attestorIDKey string
+			txid          string
+			key           *ec.PrivateKey
+		}{
+			{"nil request", nil, testutil.AttestorID, "txid", key},
+			{"missing id key", &bap.AttestationRequest{}, testutil.AttestorID, "txid", key},
+			{"missing attestor", testRequest, "", "txid", key},
+			{"missing txid", testRequest, testutil.AttestorID, "", key},
+			{"missing key", testRequest, testutil.AttestorID, "txid", nil},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		if _, err := Issue(test.request, test.attestorIDKey, test.txid, test.key, time.Now()); !errors.Is(err, bap.ErrMissingField) {
+			t.Errorf("%s Failed: [%s] inputted and expected ErrMissingField but got: %v", t.Name(), test.name, err)
+		}
+	}
+}
+
+// TestVerifier_Verify will test the method Verify()
+func TestVerifier_Verify(t *testing.T) {
+	t.Parallel()
+
+	idx, txid := testIndexer(t)
+	verifier := NewVerifier(idx.Store())
+	attestorKey, _ := testutil.SigningKey(t, testutil.AttestorKey, 0)
+	otherKey, _ := testutil.SigningKey(t, testutil.IdentityKey, 0)
+
+	var (
+		// Testing private methods
+		tests = []struct {
+			name          string
+			modify        func(c *Credential)
+			expectedError error
+		}{
+			{"valid", func(_ *Credential) {}, nil},
+			{"json round trip", func(c *Credential) {
+				data, _ := json.Marshal(c)
+				*c = Credential{}
+				_ = json.Unmarshal(data, c)
+			}, nil},
+			{"tampered value", func(c *Credential) { c.CredentialSubject.AttributeValue = "Jane" }, bap.ErrInvalidSignature},
+			{"bad signature", func(c *Credential) { c.Proof.ProofValue = "not-base64!" }, bap.ErrInvalidSignature},
+			{"hash mismatch", func(c *Credential) {
+				c.CredentialSubject.AttributeValue = "Jane"
+				testResign(t, c, attestorKey)
+			}, ErrHashMismatch},
+			{"wrong txid", func(c *Credential) {
+				c.Proof.TxID = "0000"
+				testResign(t, c, attestorKey)
+			}, ErrNotAttested},
+			{"signed by another key", func(c *Credential) { testResign(t, c, otherKey) }, bap.ErrInvalidSignature},
+			{"signed by another address", func(c *Credential) {
+				c.Proof.Address = "1A9VQqdNJrvVF73nf879n2fES6cd5nWNid"
+				testResign(t, c, otherKey)
+			}, ErrNotAttested},
+			{"wrong issuer", func(c *Credential) {
+				c.Issuer = "did:bap:" + testutil.IDKey
+				c.Proof.VerificationMethod = c.Issuer
+				testResign(t, c, attestorKey)
+			}, ErrNotAttested},
+			{"proof not by issuer", func(c *Credential) { c.Proof.VerificationMethod = "did:bap:" + testutil.IDKey }, ErrInvalidCredential},
+			{"bad issuer", func(c *Credential) { c.Issuer, c.Proof.VerificationMethod = "did:web:x", "did:web:x" }, ErrInvalidCredential},
+			{"bad subject", func(c *Credential) { c.CredentialSubject.ID = "did:web:x" }, ErrInvalidCredential},
+			{"proof type", func(c *Credential) { c.Proof.Type = "Ed25519Signature2020" }, ErrInvalidCredential},
+			{"no proof", func(c *Credential) { c.Proof = nil }, ErrInvalidCredential},
+		}
+	)
+
+	// Run tests
+	for _, test := range tests {
+		credential := testIssue(t, txid)
+		test.modify(credential)
+		if attestation, err := verifier.Verify(credential); test.expectedError == nil && err != nil {
+			t.Errorf("%s Failed: [%s] inputted and error not expected but got: %s", t.Name(), test.name, err.Error())
+		} else if test.expectedError == nil && (attestation.TxID != txid || attestation.Subject != "") {
+			t.Errorf("%s Failed: [%s] inputted and unexpected attestation: %+v", t.Name(), test.name, attestation)
+		} else if test.expectedError != nil && !errors.Is(err, test.expectedError) {
+			t.Errorf("%s Failed: [%s] inputted and expected error [%v] but got [%v]", t.Name(), test.name, test.expectedError, err)
+		}
+	}
+
+	// Revoked, then attested again with a higher sequence
+	hash := bap.AttestationHash(testutil.IDKey, "name", "John", testindex.Secret)
+	sequenceTx := func(recordType bap.AttestationType, sequence string) *transaction.Transaction {
+		parts, _, err := aip.SignOpReturnData(attestorKey, aip.BitcoinECDSA, [][]byte{
+			[]byte(bap.Prefix), []byte(recordType), hash[:], []byte(sequence), []byte("|"),
+		})
+		if err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+		tx := transaction.NewTransaction()
+		if err = tx.AddOpReturnPartsOutput(parts); err != nil {
+			t.Fatalf("error occurred: %s", err.Error())
+		}
+		return tx
+	}
+	testindex.Add(t, idx, 103, sequenceTx(bap.REVOKE, "1"))
+	if _, err := verifier.Verify(testIssue(t, txid)); !errors.Is(err, ErrRevoked) {
+		t.Fatalf("expected ErrRevoked but got: %v", err)
+	}
+	testindex.Add(t, idx, 104, sequenceTx(bap.ATTEST, "1"))
+	if _, err := verifier.Verify(testIssue(t, txid)); !errors.Is(err, ErrRevoked) {
+		t.Fatalf("expected a replayed sequence to be ignored but got: %v", err)
+	}
+	testindex.Add(t, idx, 105, sequenceTx(bap.ATTEST, "2"))
+	if _, err := verifier.Verify(testIssue(t, txid)); err != nil {
+		t.Fatalf("expected the attribute to be attested again but got: %v", err)
+	}
+}
+
+// TestVerifier_VerifyAt will test the expiry checks of VerifyAt()
+func TestVerifier_VerifyAt(t *testing.T) {
+	t.Parallel()
+
+	idx, _ := testIndexer(t)
+	key, _ := testutil.SigningKey(t, testutil.AttestorKey, 0)
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiry, err := bap.ExpiresAt(expires)
+	if err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	request := &bap.AttestationRequest{
+		IDKey:                   testutil.IDKey,
+		AttributeName:           "age",
+		AttributeValue:          "21+",
+		IdentityAttributeSecret: "age-secret",
+		Expiry:                  expiry,
+	}
+	var attestTx *transaction.Transaction
+	if attestTx, err = bap.CreateAttestationWithExpiry(request.IDKey, key, request.AttributeName,
+		request.AttributeValue, request.IdentityAttributeSecret, request.Expiry); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	}
+	testindex.Add(t, idx, 110, attestTx)
+
+	var credential *Credential
+	if credential, err = Issue(request, testutil.AttestorID, attestTx.TxID().String(), key, time.Unix(1600000000, 0)); err != nil {
+		t.Fatalf("error occurred: %s", err.Error())
+	} else if credential.ExpirationDate != "2030-01-01T00:00:00Z" {
+		t.Fatalf("%s Failed: expected the expiration date but got [%s]", t.Name(), credential.ExpirationDate)
+	}
+
+	verifier := NewVerifier(idx.Store())
+	if _, err = verifier.VerifyAt(credential, 120, expires.Add(-time.Second)); err != nil {
+		t.Fatalf("%s Failed: expected a valid credential but got: %v", t.Name(), err)
+	} else if _, err = verifier.VerifyAt(credential, 120, expires); !errors.Is(err, ErrExpired) {
+		t.Fatalf("%s Failed: expected ErrExpired but got: %v", t.Name(), err)
+	}
+}
+
+// ExampleIssue example using Issue()
+func ExampleIssue() {
+	hdKey, _ := hd.NewKeyFromString(testutil.AttestorKey)
+	child, _ := hdKey.DeriveChildFromPath("0/0")
+	key, _ := child.ECPrivKey()
+
+	credential, err := Issue(testRequest, testutil.AttestorID, "txid", key, time.Unix(1600000000, 0))
+	if err != nil {
+		fmt.Printf("error occurred: %s", err.Error())
+		return
+	}
+	fmt.Printf("issuer: %s signed by: %s", credential.Issuer, credential.Proof.Address)
+	// Output:issuer: did:bap:0d5d1e0b1bd2c0f9b8c7e6a5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5 signed by: 1AFc9feffQmxT61iEftzkaYvWTgLCyU6j
+}
@@ -0,0 +1,117 @@
+package vc
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bitcoinschema/go-bap"
+	"github.com/bitcoinschema/go-bap/did"
+	"github.com/bitcoinschema/go-bap/indexer"
+	bsm "github.com/bsv-blockchain/go-sdk/compat/bsm"
+)
+
+// Verification errors
+var (
+	ErrExpired      = errors.New("attestation has expired")
+	ErrHashMismatch = errors.New("urn hash does not match the disclosed attribute")
+	ErrNotAttested  = errors.New("attestation not found in the index")
+	ErrRevoked      = errors.New("attestation has been revoked")
+)
+
+// Verifier verifies credentials against the attestations of an indexer Store
+type Verifier struct {
+	query *indexer.Query
+}
+
+// NewVerifier returns a verifier over the store
+func NewVerifier(store indexer.Store) *Verifier {
+	return &Verifier{query: indexer.NewQuery(store)}
+}
+
+// Verify checks the credential proof signature, recomputes the URN hash of the disclosed
+// attribute and confirms the attestation is indexed for the issuer, not revoked and not expired
+// (at the highest indexed block and the current time).
+// It returns the indexed attestation of a valid credential.
+func (v *Verifier) Verify(c *Credential) (*indexer.AttestationRecord, error) {
+	return v.verify(c, func(urnHash, attestorIDKey string) (*bap.Status, error) {
+		return v.query.AttestationStatus(urnHash, attestorIDKey, "")
+	})
+}
+
+// VerifyAt verifies the credential (see Verify) with the expiry checked at the given chain height and time
+func (v *Verifier) VerifyAt(c *Credential, height uint32, now time.Time) (*indexer.AttestationRecord, error) {
+	return v.verify(c, func(urnHash, attestorIDKey string) (*bap.Status, error) {
+		return v.query.AttestationStatusAt(urnHash, attestorIDKey, "", height, now)
+	})
+}
+
+// verify checks the credential, status returns the current status of the attested URN hash
+func (v *Verifier) verify(c *Credential, status func(urnHash, attestorIDKey string) (*bap.Status, error)) (*indexer.AttestationRecord, error) {
+	if c == nil || c.CredentialSubject == nil || c.Proof == nil {
+		return nil, fmt.Errorf("%w: missing subject or proof", ErrInvalidCredential)
+	} else if c.Proof.Type != ProofType {
+		return nil, fmt.Errorf("%w: unsupported proof type %s", ErrInvalidCredential, c.Proof.Type)
+	} else if c.Proof.VerificationMethod != c.Issuer {
+		return nil, fmt.Errorf("%w: proof is not by the issuer", ErrInvalidCredential)
+	}
+	attestorIDKey, err := did.Parse(c.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: issuer: %w", ErrInvalidCredential, err)
+	}
+	var idKey string
+	if idKey, err = did.Parse(c.CredentialSubject.ID); err != nil {
+		return nil, fmt.Errorf("%w: subject: %w", ErrInvalidCredential, err)
+	}
+
+	// Proof signature
+	var signature, payload []byte
+	if signature, err = base64.StdEncoding.DecodeString(c.Proof.ProofValue); err != nil {
+		return nil, &bap.SignatureError{Address: c.Proof.Address, Err: err}
+	} else if payload, err = c.signingPayload(); err != nil {
+		return nil, err
+	} else if err = bsm.VerifyMessage(c.Proof.Address, signature, payload); err != nil {
+		return nil, &bap.SignatureError{Address: c.Proof.Address, Err: err}
+	}
+
+	// URN hash of the disclosed attribute
+	subject := c.CredentialSubject
+	hash := bap.AttestationHash(idKey, subject.AttributeName, subject.AttributeValue, subject.IdentityAttributeSecret)
+	urnHash := hex.EncodeToString(hash[:])
+	if urnHash != subject.URNHash {
+		return nil, ErrHashMismatch
+	}
+
+	// Indexed attestation by the issuer
+	records, _, err := v.query.Attestations(indexer.AttestationFilter{
+		AttestorIDKey: attestorIDKey,
+		Type:          bap.ATTEST,
+		URNHashes:     []string{urnHash},
+	}, indexer.Page{})
+	if err != nil {
+		return nil, err
+	}
+	var attestation *indexer.AttestationRecord
+	for _, record := range records {
+		if record.TxID == c.Proof.TxID && record.Address == c.Proof.Address {
+			attestation = record
+			break
+		}
+	}
+	if attestation == nil {
+		return nil, ErrNotAttested
+	}
+
+	// Not revoked or expired, by the sequence rules (a superseding attestation of the same hash keeps it valid)
+	var current *bap.Status
+	if current, err = status(urnHash, attestorIDKey); err != nil {
+		return nil, err
+	} else if current.Status == bap.StatusRevoked {
+		return nil, ErrRevoked
+	} else if current.Status == bap.StatusExpired {
+		return nil, ErrExpired
+	}
+	return attestation, nil
+}